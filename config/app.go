@@ -8,6 +8,7 @@ type App struct {
 	Environment string `required:"true"`
 	CertDir     string `split_words:"true"`
 	KeyDir      string `split_words:"true"`
+	BaseDomain  string `split_words:"true"`
 }
 
 func APP() App {
@@ -0,0 +1,19 @@
+package config
+
+import (
+	"time"
+
+	"github.com/kelseyhightower/envconfig"
+)
+
+type Auth struct {
+	SessionTTL     time.Duration `split_words:"true" default:"720h"`
+	AccessTokenTTL time.Duration `split_words:"true" default:"15m"`
+}
+
+func NewAuth() Auth {
+	var auth Auth
+	envconfig.MustProcess("AUTH", &auth)
+
+	return auth
+}
@@ -1,11 +1,48 @@
 package config
 
-import "github.com/kelseyhightower/envconfig"
+import (
+	"time"
 
+	"github.com/kelseyhightower/envconfig"
+)
+
+// Cache selects the cache backend. Driver "redis" is shared between replicas;
+// "memory" keeps an LRU bounded by MaxEntries and MaxBytes in the process.
+//
+// Redis runs in Mode "standalone", "sentinel" or "cluster". Address lists
+// the server, the sentinels or the cluster nodes, separated by commas.
 type Cache struct {
-	Address  string `required:"true"`
-	Password string `required:"true"`
-	DB       int    `required:"true"`
+	Driver           string `default:"redis"`
+	Mode             string `default:"standalone"`
+	Address          []string
+	MasterName       string `split_words:"true"`
+	Username         string
+	Password         string
+	SentinelPassword string `split_words:"true"`
+	DB               int
+
+	TLS           bool   `envconfig:"TLS"`
+	TLSCACert     string `envconfig:"TLS_CA_CERT"`
+	TLSCert       string `envconfig:"TLS_CERT"`
+	TLSKey        string `envconfig:"TLS_KEY"`
+	TLSServerName string `envconfig:"TLS_SERVER_NAME"`
+
+	DialTimeout  time.Duration `split_words:"true" default:"5s"`
+	ReadTimeout  time.Duration `split_words:"true" default:"3s"`
+	WriteTimeout time.Duration `split_words:"true" default:"3s"`
+	PoolSize     int           `split_words:"true"`
+	MinIdleConns int           `split_words:"true"`
+	PoolTimeout  time.Duration `split_words:"true"`
+
+	MaxEntries int           `split_words:"true" default:"100000"`
+	MaxBytes   int64         `split_words:"true" default:"67108864"`
+	TaskTTL    time.Duration `split_words:"true" default:"5m"`
+	// TaskStaleTTL is how long expired tasks are still served while they are
+	// refreshed.
+	TaskStaleTTL time.Duration `split_words:"true" default:"1m"`
+	// IdempotencyTTL is how long responses are kept for Idempotency-Key
+	// retries.
+	IdempotencyTTL time.Duration `split_words:"true" default:"24h"`
 }
 
 func NewCache() Cache {
@@ -10,8 +10,11 @@ import (
 type Config struct {
 	Api
 	App
+	Auth
 	Client
 	Cors
+	OIDC
+	RateLimit
 
 	Cache
 	Database
@@ -32,20 +35,26 @@ func New(isTesting, isCallingFromDomain bool) *Config {
 
 	if isTesting {
 		return &Config{
-			Api:      API(),
-			App:      APP(),
-			Cache:    NewCache(),
-			Database: DataStore(),
+			Api:       API(),
+			App:       APP(),
+			Auth:      NewAuth(),
+			OIDC:      NewOIDC(),
+			RateLimit: NewRateLimit(),
+			Cache:     NewCache(),
+			Database:  DataStore(),
 		}
 	}
 
 	return &Config{
-		Api:      API(),
-		App:      APP(),
-		Client:   NewClient(),
-		Cors:     NewCors(),
-		Cache:    NewCache(),
-		Database: DataStore(),
+		Api:       API(),
+		App:       APP(),
+		Auth:      NewAuth(),
+		Client:    NewClient(),
+		Cors:      NewCors(),
+		OIDC:      NewOIDC(),
+		RateLimit: NewRateLimit(),
+		Cache:     NewCache(),
+		Database:  DataStore(),
 	}
 }
 
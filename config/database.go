@@ -17,6 +17,32 @@ type Database struct {
 	MaxConnectionPool      int           `split_words:"true" required:"true"`
 	MaxIdleConnections     int           `split_words:"true" required:"true"`
 	ConnectionsMaxLifeTime time.Duration `split_words:"true" required:"true"`
+	// TxRetries is how many more times a transaction aborted by a
+	// serialization failure or a deadlock is run.
+	TxRetries int `split_words:"true" default:"3"`
+	// Replicas are the data source names of read replicas of the primary,
+	// opened through the same driver. FindOne and FindMany read from them.
+	Replicas []string
+	// ReplicaCheckInterval is how often replicas are pinged, so the ones
+	// down stop being read from until they answer again.
+	ReplicaCheckInterval time.Duration `split_words:"true" default:"5s"`
+	// ReadYourWritesWindow is how long a client reads from the primary after
+	// it writes, so it sees its writes before replicas catch up.
+	ReadYourWritesWindow time.Duration `split_words:"true" default:"5s"`
+	// Statements slower than SlowQueryThreshold are logged, and those run
+	// without a deadline get QueryTimeout. Zero turns either off.
+	SlowQueryThreshold time.Duration `split_words:"true" default:"200ms"`
+	QueryTimeout       time.Duration `split_words:"true" default:"10s"`
+	// ExplainSlowQueries logs the plan of slow reads in development.
+	ExplainSlowQueries bool `split_words:"true" default:"false"`
+	// ErrorStatsInterval is how often failed statements are logged, counted
+	// by type, zero to never log them.
+	ErrorStatsInterval time.Duration `split_words:"true" default:"5m"`
+	// StatementCacheSize is how many prepared statements repositories keep,
+	// zero to send every query unprepared. Their hit rate is logged every
+	// StatementStatsInterval, zero to never log it.
+	StatementCacheSize     int           `split_words:"true" default:"256"`
+	StatementStatsInterval time.Duration `split_words:"true" default:"5m"`
 }
 
 func DataStore() Database {
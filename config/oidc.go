@@ -0,0 +1,32 @@
+package config
+
+import (
+	"time"
+
+	"github.com/kelseyhightower/envconfig"
+)
+
+// OIDC configures single sign-on. It is disabled while Issuer is empty.
+// Roles maps values of the RoleClaim claim to workspace roles, for example
+// "admins:owner,staff:member"; users without a mapped value get DefaultRole,
+// or are refused when it is empty.
+type OIDC struct {
+	Issuer       string
+	ClientID     string   `split_words:"true"`
+	ClientSecret string   `split_words:"true"`
+	RedirectURL  string   `split_words:"true"`
+	Scopes       []string `default:"openid,email,profile"`
+	RoleClaim    string   `split_words:"true" default:"groups"`
+	Roles        map[string]string
+	DefaultRole  string        `split_words:"true"`
+	Workspace    string        `default:"default"`
+	JWKSCacheTTL time.Duration `envconfig:"JWKS_CACHE_TTL" default:"1h"`
+	LoginTTL     time.Duration `split_words:"true" default:"10m"`
+}
+
+func NewOIDC() OIDC {
+	var oidc OIDC
+	envconfig.MustProcess("OIDC", &oidc)
+
+	return oidc
+}
@@ -0,0 +1,75 @@
+package config
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/kelseyhightower/envconfig"
+)
+
+// Clients a Limit can count requests by. Requests without an identified user
+// or an API key are counted by IP.
+const (
+	LimitByIP     = "ip"
+	LimitByUser   = "user"
+	LimitByAPIKey = "apikey"
+)
+
+// Limit allows Requests per Window to each client, told apart By ip, user or
+// apikey. It is read as "by:requests/window", as in "user:300/1m", or "off".
+type Limit struct {
+	By       string
+	Requests int
+	Window   time.Duration
+}
+
+func (l *Limit) Decode(value string) error {
+	if value == "off" {
+		*l = Limit{}
+		return nil
+	}
+
+	by, rate, found := strings.Cut(value, ":")
+	if !found || (by != LimitByIP && by != LimitByUser && by != LimitByAPIKey) {
+		return fmt.Errorf("invalid rate limit %q: expected ip, user or apikey before the colon", value)
+	}
+
+	requests, window, found := strings.Cut(rate, "/")
+	if !found {
+		return fmt.Errorf("invalid rate limit %q: expected requests/window", value)
+	}
+
+	n, err := strconv.Atoi(requests)
+	if err != nil || n <= 0 {
+		return fmt.Errorf("invalid rate limit %q: requests must be a positive number", value)
+	}
+
+	d, err := time.ParseDuration(window)
+	if err != nil || d <= 0 {
+		return fmt.Errorf("invalid rate limit %q: window must be a positive duration", value)
+	}
+
+	*l = Limit{By: by, Requests: n, Window: d}
+
+	return nil
+}
+
+// RateLimit holds a Limit per route group. Groups reached before
+// authentication can only count by IP or API key.
+type RateLimit struct {
+	Enabled    bool  `default:"true"`
+	Users      Limit `default:"ip:10/1m"`
+	Sessions   Limit `default:"ip:30/1m"`
+	SSO        Limit `envconfig:"SSO" default:"ip:30/1m"`
+	Workspaces Limit `default:"user:120/1m"`
+	Tasks      Limit `default:"user:300/1m"`
+}
+
+func NewRateLimit() RateLimit {
+	var rateLimit RateLimit
+	envconfig.MustProcess("RATE_LIMIT", &rateLimit)
+
+	return rateLimit
+}
@@ -0,0 +1,205 @@
+package handler
+
+import (
+	"encoding/json"
+	"log/slog"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/henriqueassiss/advanced-golang-api/internal/domain/session"
+	"github.com/henriqueassiss/advanced-golang-api/internal/domain/session/useCase"
+	"github.com/henriqueassiss/advanced-golang-api/internal/utils/errorMsg"
+	"github.com/henriqueassiss/advanced-golang-api/internal/utils/identity"
+	"github.com/henriqueassiss/advanced-golang-api/internal/utils/reqRes"
+)
+
+type ISession struct {
+	useCase useCase.ISession
+	logger  *slog.Logger
+}
+
+func NewHandler(useCase useCase.ISession, logger *slog.Logger) *ISession {
+	return &ISession{
+		useCase: useCase,
+		logger:  logger,
+	}
+}
+
+func toSingleSession(s *session.Schema, currentSessionID string) SingleSession {
+	return SingleSession{
+		ID:         s.ID,
+		Device:     s.Device,
+		IP:         s.IP,
+		Current:    s.ID == currentSessionID,
+		CreatedAt:  s.CreatedAt,
+		LastSeenAt: s.LastSeenAt,
+	}
+}
+
+func toTokens(t *session.Tokens) Tokens {
+	return Tokens{
+		AccessToken:  t.AccessToken,
+		RefreshToken: t.RefreshToken,
+		ExpiresIn:    int64(t.ExpiresIn.Seconds()),
+	}
+}
+
+// ToSignedIn is the response of every endpoint that starts a session.
+func ToSignedIn(t *session.Tokens, s *session.Schema) SignedIn {
+	return SignedIn{
+		Tokens:  toTokens(t),
+		Session: toSingleSession(s, s.ID),
+	}
+}
+
+func (h *ISession) SignIn(w http.ResponseWriter, r *http.Request) {
+	var req SignIn
+	err := json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		reqRes.Error(h.logger, w, http.StatusBadRequest, err, nil)
+		return
+	}
+
+	if req.Email == "" ||
+		req.Password == "" {
+		reqRes.Error(h.logger, w, http.StatusBadRequest, errorMsg.ErrInvalidRequestData, req.Email)
+		return
+	}
+
+	device := reqRes.GetRequestDevice(r.UserAgent())
+	tokens, s, err := h.useCase.SignIn(r.Context(), req.Email, req.Password, device, reqRes.GetRequestIP(r), req.WorkspaceID)
+	if err != nil {
+		if err == errorMsg.ErrInvalidCredentials {
+			reqRes.Error(h.logger, w, http.StatusUnauthorized, err, req.Email)
+		} else {
+			reqRes.Error(h.logger, w, http.StatusInternalServerError, err, req.Email)
+		}
+
+		return
+	}
+
+	reqRes.Json(w, http.StatusOK, ToSignedIn(tokens, s))
+}
+
+func (h *ISession) Refresh(w http.ResponseWriter, r *http.Request) {
+	var req Refresh
+	err := json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		reqRes.Error(h.logger, w, http.StatusBadRequest, err, nil)
+		return
+	}
+
+	if req.RefreshToken == "" {
+		reqRes.Error(h.logger, w, http.StatusBadRequest, errorMsg.ErrInvalidRequestData, nil)
+		return
+	}
+
+	tokens, err := h.useCase.Refresh(r.Context(), req.RefreshToken, reqRes.GetRequestIP(r))
+	if err != nil {
+		if err == errorMsg.ErrUnauthorized || err == errorMsg.ErrRefreshTokenReused {
+			reqRes.Error(h.logger, w, http.StatusUnauthorized, err, nil)
+		} else {
+			reqRes.Error(h.logger, w, http.StatusInternalServerError, err, nil)
+		}
+
+		return
+	}
+
+	reqRes.Json(w, http.StatusOK, toTokens(tokens))
+}
+
+func (h *ISession) FindMany(w http.ResponseWriter, r *http.Request) {
+	i, ok := identity.FromContext(r.Context())
+	if !ok {
+		reqRes.Error(h.logger, w, http.StatusUnauthorized, errorMsg.ErrUnauthorized, nil)
+		return
+	}
+
+	ss, err := h.useCase.FindMany(r.Context(), i.UserID)
+	if err != nil {
+		reqRes.Error(h.logger, w, http.StatusInternalServerError, err, i.UserID)
+		return
+	}
+
+	res := make([]SingleSession, len(ss))
+	for j := range ss {
+		res[j] = toSingleSession(&ss[j], i.SessionID)
+	}
+
+	reqRes.Json(w, http.StatusOK, res)
+}
+
+func (h *ISession) Delete(w http.ResponseWriter, r *http.Request) {
+	i, ok := identity.FromContext(r.Context())
+	if !ok {
+		reqRes.Error(h.logger, w, http.StatusUnauthorized, errorMsg.ErrUnauthorized, nil)
+		return
+	}
+
+	sessionID := chi.URLParam(r, "sessionID")
+	if sessionID == "" {
+		reqRes.Error(h.logger, w, http.StatusBadRequest, errorMsg.ErrInvalidRequestData, sessionID)
+		return
+	}
+
+	err := h.useCase.Delete(r.Context(), i.UserID, sessionID)
+	if err != nil {
+		if err == errorMsg.ErrSessionNotFound {
+			reqRes.Error(h.logger, w, http.StatusNotFound, err, sessionID)
+		} else {
+			reqRes.Error(h.logger, w, http.StatusInternalServerError, err, sessionID)
+		}
+
+		return
+	}
+
+	reqRes.NoContent(w)
+}
+
+func (h *ISession) DeleteOthers(w http.ResponseWriter, r *http.Request) {
+	i, ok := identity.FromContext(r.Context())
+	if !ok {
+		reqRes.Error(h.logger, w, http.StatusUnauthorized, errorMsg.ErrUnauthorized, nil)
+		return
+	}
+
+	err := h.useCase.DeleteOthers(r.Context(), i.UserID, i.SessionID)
+	if err != nil {
+		reqRes.Error(h.logger, w, http.StatusInternalServerError, err, i.UserID)
+		return
+	}
+
+	reqRes.NoContent(w)
+}
+
+func (h *ISession) Logout(w http.ResponseWriter, r *http.Request) {
+	i, ok := identity.FromContext(r.Context())
+	if !ok {
+		reqRes.Error(h.logger, w, http.StatusUnauthorized, errorMsg.ErrUnauthorized, nil)
+		return
+	}
+
+	err := h.useCase.Delete(r.Context(), i.UserID, i.SessionID)
+	if err != nil {
+		reqRes.Error(h.logger, w, http.StatusInternalServerError, err, i.SessionID)
+		return
+	}
+
+	reqRes.Json(w, http.StatusOK, nil)
+}
+
+func (h *ISession) LogoutEverywhere(w http.ResponseWriter, r *http.Request) {
+	i, ok := identity.FromContext(r.Context())
+	if !ok {
+		reqRes.Error(h.logger, w, http.StatusUnauthorized, errorMsg.ErrUnauthorized, nil)
+		return
+	}
+
+	err := h.useCase.DeleteAll(r.Context(), i.UserID)
+	if err != nil {
+		reqRes.Error(h.logger, w, http.StatusInternalServerError, err, i.UserID)
+		return
+	}
+
+	reqRes.Json(w, http.StatusOK, nil)
+}
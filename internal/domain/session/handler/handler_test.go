@@ -0,0 +1,419 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/henriqueassiss/advanced-golang-api/internal/domain/session"
+	"github.com/henriqueassiss/advanced-golang-api/internal/domain/session/useCase"
+	"github.com/henriqueassiss/advanced-golang-api/internal/utils/errorMsg"
+	"github.com/henriqueassiss/advanced-golang-api/internal/utils/identity"
+	"github.com/henriqueassiss/advanced-golang-api/internal/utils/reqRes"
+
+	"github.com/henriqueassiss/advanced-golang-api/third_party/logger"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+)
+
+func authenticate(ctx context.Context, token string) (*identity.Identity, error) {
+	if token != "valid" {
+		return nil, errorMsg.ErrUnauthorized
+	}
+
+	return &identity.Identity{UserID: 1, SessionID: "1"}, nil
+}
+
+func TestSessionHandler_SignIn(t *testing.T) {
+	logger := logger.New()
+	date := time.Date(2000, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	type want struct {
+		status   int
+		response *reqRes.GenericResponse[*SignedIn]
+	}
+
+	type test struct {
+		name string
+		body string
+		err  error
+		want
+	}
+
+	tests := []test{
+		{
+			name: "Success",
+			body: `{"email": "test@test.com", "password": "12345678"}`,
+			want: want{
+				status: http.StatusOK,
+				response: &reqRes.GenericResponse[*SignedIn]{
+					Success: true,
+					Status:  http.StatusOK,
+					Data: &SignedIn{
+						Tokens: Tokens{
+							AccessToken:  "access",
+							RefreshToken: "1.secret",
+							ExpiresIn:    900,
+						},
+						Session: SingleSession{
+							ID:         "1",
+							Device:     "Test",
+							IP:         "127.0.0.1",
+							Current:    true,
+							CreatedAt:  date,
+							LastSeenAt: date,
+						},
+					},
+				},
+			},
+		},
+		{
+			name: "Fail - Missing password",
+			body: `{"email": "test@test.com"}`,
+			want: want{
+				status: http.StatusBadRequest,
+				response: &reqRes.GenericResponse[*SignedIn]{
+					Success: false,
+					Status:  http.StatusBadRequest,
+				},
+			},
+		},
+		{
+			name: "Fail - Invalid credentials",
+			body: `{"email": "test@test.com", "password": "wrong-password"}`,
+			err:  errorMsg.ErrInvalidCredentials,
+			want: want{
+				status: http.StatusUnauthorized,
+				response: &reqRes.GenericResponse[*SignedIn]{
+					Success: false,
+					Status:  http.StatusUnauthorized,
+				},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodPost, "/v1/sessions", strings.NewReader(tt.body))
+			w := httptest.NewRecorder()
+
+			uc := &useCase.SessionMock{
+				SignInFunc: func(ctx context.Context, email, password, device, ip string, workspaceID uint64) (*session.Tokens, *session.Schema, error) {
+					if tt.err != nil {
+						return nil, nil, tt.err
+					}
+
+					return &session.Tokens{
+						AccessToken:  "access",
+						RefreshToken: "1.secret",
+						ExpiresIn:    15 * time.Minute,
+					}, &session.Schema{
+						ID:         "1",
+						UserID:     1,
+						Device:     "Test",
+						IP:         ip,
+						CreatedAt:  date,
+						LastSeenAt: date,
+					}, nil
+				},
+			}
+
+			router := chi.NewRouter()
+			h := RegisterHTTPEndPoints(uc, logger, router)
+			r.RemoteAddr = "127.0.0.1:1234"
+			h.SignIn(w, r)
+
+			assert.Equal(t, tt.status, w.Code)
+
+			var got reqRes.GenericResponse[*SignedIn]
+			err := json.NewDecoder(w.Body).Decode(&got)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			assert.Equal(t, *tt.want.response, got)
+		})
+	}
+}
+
+func TestSessionHandler_Refresh(t *testing.T) {
+	logger := logger.New()
+
+	type test struct {
+		name   string
+		body   string
+		err    error
+		status int
+	}
+
+	tests := []test{
+		{
+			name:   "Success",
+			body:   `{"refreshToken": "1.secret"}`,
+			status: http.StatusOK,
+		},
+		{
+			name:   "Fail - Missing refresh token",
+			body:   `{}`,
+			status: http.StatusBadRequest,
+		},
+		{
+			name:   "Fail - Reused refresh token",
+			body:   `{"refreshToken": "1.secret"}`,
+			err:    errorMsg.ErrRefreshTokenReused,
+			status: http.StatusUnauthorized,
+		},
+		{
+			name:   "Fail - Simulating internal error",
+			body:   `{"refreshToken": "1.secret"}`,
+			err:    errors.New("some error"),
+			status: http.StatusInternalServerError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodPost, "/v1/sessions/refresh", strings.NewReader(tt.body))
+			w := httptest.NewRecorder()
+
+			uc := &useCase.SessionMock{
+				RefreshFunc: func(ctx context.Context, refreshToken, ip string) (*session.Tokens, error) {
+					if tt.err != nil {
+						return nil, tt.err
+					}
+
+					return &session.Tokens{AccessToken: "access", RefreshToken: "1.rotated"}, nil
+				},
+			}
+
+			router := chi.NewRouter()
+			RegisterHTTPEndPoints(uc, logger, router)
+			router.ServeHTTP(w, r)
+
+			assert.Equal(t, tt.status, w.Code)
+		})
+	}
+}
+
+func TestSessionHandler_FindMany(t *testing.T) {
+	logger := logger.New()
+	date := time.Date(2000, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	type want struct {
+		status   int
+		response *reqRes.GenericResponse[[]SingleSession]
+	}
+
+	type test struct {
+		name  string
+		token string
+		want
+	}
+
+	tests := []test{
+		{
+			name:  "Success",
+			token: "valid",
+			want: want{
+				status: http.StatusOK,
+				response: &reqRes.GenericResponse[[]SingleSession]{
+					Success: true,
+					Status:  http.StatusOK,
+					Data: []SingleSession{
+						{ID: "1", Device: "Current", Current: true, CreatedAt: date, LastSeenAt: date},
+						{ID: "2", Device: "Other", CreatedAt: date, LastSeenAt: date},
+					},
+				},
+			},
+		},
+		{
+			name:  "Fail - Invalid token",
+			token: "invalid",
+			want: want{
+				status: http.StatusUnauthorized,
+				response: &reqRes.GenericResponse[[]SingleSession]{
+					Success: false,
+					Status:  http.StatusUnauthorized,
+				},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodGet, "/v1/sessions/", nil)
+			r.Header.Set("Authorization", "Bearer "+tt.token)
+			w := httptest.NewRecorder()
+
+			uc := &useCase.SessionMock{
+				AuthenticateFunc: authenticate,
+				FindManyFunc: func(ctx context.Context, userID uint64) ([]session.Schema, error) {
+					return []session.Schema{
+						{ID: "1", UserID: userID, Device: "Current", CreatedAt: date, LastSeenAt: date},
+						{ID: "2", UserID: userID, Device: "Other", CreatedAt: date, LastSeenAt: date},
+					}, nil
+				},
+			}
+
+			router := chi.NewRouter()
+			RegisterHTTPEndPoints(uc, logger, router)
+			router.ServeHTTP(w, r)
+
+			assert.Equal(t, tt.status, w.Code)
+
+			var got reqRes.GenericResponse[[]SingleSession]
+			err := json.NewDecoder(w.Body).Decode(&got)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			assert.Equal(t, *tt.want.response, got)
+		})
+	}
+}
+
+func TestSessionHandler_Delete(t *testing.T) {
+	logger := logger.New()
+
+	type test struct {
+		name      string
+		sessionID string
+		err       error
+		status    int
+	}
+
+	tests := []test{
+		{
+			name:      "Success",
+			sessionID: "2",
+			status:    http.StatusNoContent,
+		},
+		{
+			name:      "Fail - Non-existent session",
+			sessionID: "3",
+			err:       errorMsg.ErrSessionNotFound,
+			status:    http.StatusNotFound,
+		},
+		{
+			name:      "Fail - Simulating internal error",
+			sessionID: "2",
+			err:       errors.New("some error"),
+			status:    http.StatusInternalServerError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodDelete, "/v1/sessions/"+tt.sessionID, nil)
+			r.Header.Set("Authorization", "Bearer valid")
+			w := httptest.NewRecorder()
+
+			uc := &useCase.SessionMock{
+				AuthenticateFunc: authenticate,
+				DeleteFunc: func(ctx context.Context, userID uint64, sessionID string) error {
+					assert.Equal(t, uint64(1), userID)
+					assert.Equal(t, tt.sessionID, sessionID)
+					return tt.err
+				},
+			}
+
+			router := chi.NewRouter()
+			RegisterHTTPEndPoints(uc, logger, router)
+			router.ServeHTTP(w, r)
+
+			assert.Equal(t, tt.status, w.Code)
+		})
+	}
+}
+
+func TestSessionHandler_DeleteOthers(t *testing.T) {
+	logger := logger.New()
+
+	r := httptest.NewRequest(http.MethodDelete, "/v1/sessions/", nil)
+	r.Header.Set("Authorization", "Bearer valid")
+	w := httptest.NewRecorder()
+
+	uc := &useCase.SessionMock{
+		AuthenticateFunc: authenticate,
+		DeleteOthersFunc: func(ctx context.Context, userID uint64, currentSessionID string) error {
+			assert.Equal(t, uint64(1), userID)
+			assert.Equal(t, "1", currentSessionID)
+			return nil
+		},
+	}
+
+	router := chi.NewRouter()
+	RegisterHTTPEndPoints(uc, logger, router)
+	router.ServeHTTP(w, r)
+
+	assert.Equal(t, http.StatusNoContent, w.Code)
+	assert.Empty(t, w.Body.String())
+}
+
+func TestSessionHandler_Logout(t *testing.T) {
+	logger := logger.New()
+
+	type test struct {
+		name   string
+		path   string
+		token  string
+		status int
+	}
+
+	tests := []test{
+		{
+			name:   "Success - Logout",
+			path:   "/v1/sessions/logout",
+			token:  "valid",
+			status: http.StatusOK,
+		},
+		{
+			name:   "Success - Logout everywhere",
+			path:   "/v1/sessions/logout-everywhere",
+			token:  "valid",
+			status: http.StatusOK,
+		},
+		{
+			name:   "Fail - Invalid token",
+			path:   "/v1/sessions/logout",
+			token:  "invalid",
+			status: http.StatusUnauthorized,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodPost, tt.path, nil)
+			r.Header.Set("Authorization", "Bearer "+tt.token)
+			w := httptest.NewRecorder()
+
+			var deleted []string
+			uc := &useCase.SessionMock{
+				AuthenticateFunc: authenticate,
+				DeleteFunc: func(ctx context.Context, userID uint64, sessionID string) error {
+					deleted = append(deleted, sessionID)
+					return nil
+				},
+				DeleteAllFunc: func(ctx context.Context, userID uint64) error {
+					deleted = append(deleted, "*")
+					return nil
+				},
+			}
+
+			router := chi.NewRouter()
+			RegisterHTTPEndPoints(uc, logger, router)
+			router.ServeHTTP(w, r)
+
+			assert.Equal(t, tt.status, w.Code)
+			if tt.status == http.StatusOK {
+				assert.Len(t, deleted, 1)
+			}
+		})
+	}
+}
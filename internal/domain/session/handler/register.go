@@ -0,0 +1,29 @@
+package handler
+
+import (
+	"log/slog"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/henriqueassiss/advanced-golang-api/internal/domain/session/useCase"
+	"github.com/henriqueassiss/advanced-golang-api/internal/middleware"
+)
+
+func RegisterHTTPEndPoints(u useCase.ISession, logger *slog.Logger, router *chi.Mux, middlewares ...func(http.Handler) http.Handler) *ISession {
+	handler := NewHandler(u, logger)
+	router.Route("/v1/sessions", func(router chi.Router) {
+		router.Use(middlewares...)
+		router.Post("/", handler.SignIn)
+		router.Post("/refresh", handler.Refresh)
+
+		router.Group(func(router chi.Router) {
+			router.Use(middleware.Authenticate(u, logger))
+			router.Get("/", handler.FindMany)
+			router.Delete("/", handler.DeleteOthers)
+			router.Delete("/{sessionID}", handler.Delete)
+			router.Post("/logout", handler.Logout)
+			router.Post("/logout-everywhere", handler.LogoutEverywhere)
+		})
+	})
+	return handler
+}
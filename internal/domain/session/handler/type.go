@@ -0,0 +1,33 @@
+package handler
+
+import "time"
+
+type SingleSession struct {
+	ID         string    `json:"id"`
+	Device     string    `json:"device"`
+	IP         string    `json:"ip"`
+	Current    bool      `json:"current"`
+	CreatedAt  time.Time `json:"createdAt"`
+	LastSeenAt time.Time `json:"lastSeenAt"`
+}
+
+type Tokens struct {
+	AccessToken  string `json:"accessToken"`
+	RefreshToken string `json:"refreshToken"`
+	ExpiresIn    int64  `json:"expiresIn"`
+}
+
+type SignIn struct {
+	Email       string `json:"email"`
+	Password    string `json:"password"`
+	WorkspaceID uint64 `json:"workspaceId"`
+}
+
+type SignedIn struct {
+	Tokens
+	Session SingleSession `json:"session"`
+}
+
+type Refresh struct {
+	RefreshToken string `json:"refreshToken"`
+}
@@ -0,0 +1,15 @@
+package repository
+
+import "fmt"
+
+func sessionKey(sessionID string) string {
+	return fmt.Sprintf("session:%s", sessionID)
+}
+
+func userSessionsTag(userID uint64) string {
+	return fmt.Sprintf("user:%d:sessions", userID)
+}
+
+func rotatedSecretKey(secret string) string {
+	return fmt.Sprintf("session:rotated:%s", secret)
+}
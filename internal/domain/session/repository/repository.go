@@ -0,0 +1,172 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"sort"
+	"time"
+
+	"github.com/henriqueassiss/advanced-golang-api/internal/domain/session"
+	"github.com/henriqueassiss/advanced-golang-api/internal/utils/errorMsg"
+	"github.com/henriqueassiss/advanced-golang-api/third_party/cache"
+)
+
+type ISession interface {
+	FindOne(ctx context.Context, sessionID string) (*session.Schema, error)
+	FindMany(ctx context.Context, userID uint64) ([]session.Schema, error)
+	FindRotated(ctx context.Context, secret string) (string, error)
+	Save(ctx context.Context, s *session.Schema) error
+	Rotate(ctx context.Context, s *session.Schema, previousSecret string) error
+	Touch(ctx context.Context, sessionID string, lastSeenAt time.Time) error
+	Delete(ctx context.Context, userID uint64, sessionIDs ...string) error
+}
+
+type Session struct {
+	cache cache.Cache
+	ttl   time.Duration
+}
+
+func New(cache cache.Cache, ttl time.Duration) *Session {
+	return &Session{
+		cache: cache,
+		ttl:   ttl,
+	}
+}
+
+func (r *Session) FindOne(ctx context.Context, sessionID string) (*session.Schema, error) {
+	s, _, err := r.find(ctx, sessionID)
+	return s, err
+}
+
+// find also returns the stored bytes, which are what Swap compares against.
+func (r *Session) find(ctx context.Context, sessionID string) (*session.Schema, []byte, error) {
+	data, err := r.cache.Get(ctx, sessionKey(sessionID))
+	if err != nil {
+		if err == cache.ErrNotFound {
+			return nil, nil, errorMsg.ErrSessionNotFound
+		}
+
+		return nil, nil, err
+	}
+
+	var s session.Schema
+	err = json.Unmarshal(data, &s)
+
+	return &s, data, err
+}
+
+func (r *Session) FindMany(ctx context.Context, userID uint64) ([]session.Schema, error) {
+	keys, err := r.cache.Keys(ctx, userSessionsTag(userID))
+	if err != nil {
+		return nil, err
+	}
+
+	sort.Strings(keys)
+
+	var ss []session.Schema
+	for _, key := range keys {
+		data, err := r.cache.Get(ctx, key)
+		if err != nil {
+			if err == cache.ErrNotFound {
+				continue
+			}
+
+			return nil, err
+		}
+
+		var s session.Schema
+		err = json.Unmarshal(data, &s)
+		if err != nil {
+			return nil, err
+		}
+
+		ss = append(ss, s)
+	}
+
+	return ss, nil
+}
+
+// FindRotated returns the ID of the session that used to be refreshed with
+// the given secret hash, or ErrSessionNotFound if the secret was never rotated.
+func (r *Session) FindRotated(ctx context.Context, secret string) (string, error) {
+	sessionID, err := r.cache.Get(ctx, rotatedSecretKey(secret))
+	if err == cache.ErrNotFound {
+		return "", errorMsg.ErrSessionNotFound
+	}
+
+	return string(sessionID), err
+}
+
+func (r *Session) Save(ctx context.Context, s *session.Schema) error {
+	data, err := json.Marshal(s)
+	if err != nil {
+		return err
+	}
+
+	return r.cache.Set(ctx, sessionKey(s.ID), data, r.ttl, userSessionsTag(s.UserID))
+}
+
+// Rotate saves s only if the stored session still holds previousSecret, so two
+// concurrent refreshes with the same token can never both succeed.
+func (r *Session) Rotate(ctx context.Context, s *session.Schema, previousSecret string) error {
+	current, stored, err := r.find(ctx, s.ID)
+	if err != nil {
+		return err
+	}
+
+	if current.Secret != previousSecret {
+		return errorMsg.ErrRefreshTokenReused
+	}
+
+	data, err := json.Marshal(s)
+	if err != nil {
+		return err
+	}
+
+	// The marker goes first: if the swap then loses a race, the marker is
+	// still right, since previousSecret was rotated by the winner.
+	err = r.cache.Set(ctx, rotatedSecretKey(previousSecret), []byte(s.ID), r.ttl)
+	if err != nil {
+		return err
+	}
+
+	ok, err := r.cache.Swap(ctx, sessionKey(s.ID), stored, data, r.ttl, userSessionsTag(s.UserID))
+	if err != nil {
+		return err
+	}
+
+	if !ok {
+		return errorMsg.ErrRefreshTokenReused
+	}
+
+	return nil
+}
+
+// Touch only moves the last-seen time forward, leaving the refresh secret and
+// the expiration untouched. Losing a race against a rotation is fine: the
+// rotation moved the last-seen time too.
+func (r *Session) Touch(ctx context.Context, sessionID string, lastSeenAt time.Time) error {
+	s, stored, err := r.find(ctx, sessionID)
+	if err != nil {
+		return err
+	}
+
+	s.LastSeenAt = lastSeenAt
+	data, err := json.Marshal(s)
+	if err != nil {
+		return err
+	}
+
+	_, err = r.cache.Swap(ctx, sessionKey(sessionID), stored, data, cache.KeepTTL)
+
+	return err
+}
+
+func (r *Session) Delete(ctx context.Context, userID uint64, sessionIDs ...string) error {
+	keys := make([]string, len(sessionIDs))
+	for i, id := range sessionIDs {
+		keys[i] = sessionKey(id)
+	}
+
+	return r.cache.Delete(ctx, keys...)
+}
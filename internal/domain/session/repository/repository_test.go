@@ -0,0 +1,175 @@
+package repository
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/henriqueassiss/advanced-golang-api/internal/domain/session"
+	"github.com/henriqueassiss/advanced-golang-api/internal/utils/errorMsg"
+	"github.com/henriqueassiss/advanced-golang-api/third_party/cache"
+	"github.com/stretchr/testify/assert"
+)
+
+func newSession(id string, userID uint64) *session.Schema {
+	date := time.Date(2000, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	return &session.Schema{
+		ID:         id,
+		Secret:     "secret",
+		UserID:     userID,
+		Device:     "Test",
+		IP:         "127.0.0.1",
+		CreatedAt:  date,
+		LastSeenAt: date,
+	}
+}
+
+func TestSessionRepository_FindOne(t *testing.T) {
+	cacheMock := cache.NewMock(t)
+	r := New(cacheMock, time.Hour)
+
+	s := newSession("1", 1)
+	err := r.Save(context.TODO(), s)
+	assert.Nil(t, err)
+
+	type want struct {
+		s   *session.Schema
+		err error
+	}
+
+	type test struct {
+		name      string
+		sessionID string
+		want
+	}
+
+	tests := []test{
+		{
+			name:      "Success",
+			sessionID: "1",
+			want: want{
+				s: s,
+			},
+		},
+		{
+			name:      "Fail - Non-existent session",
+			sessionID: "2",
+			want: want{
+				err: errorMsg.ErrSessionNotFound,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := r.FindOne(context.TODO(), tt.sessionID)
+			assert.Equal(t, tt.want.err, err)
+			assert.Equal(t, tt.want.s, got)
+		})
+	}
+}
+
+func TestSessionRepository_FindMany(t *testing.T) {
+	cacheMock := cache.NewMock(t)
+	r := New(cacheMock, time.Hour)
+
+	for _, s := range []*session.Schema{newSession("1", 1), newSession("2", 1), newSession("3", 2)} {
+		err := r.Save(context.TODO(), s)
+		assert.Nil(t, err)
+	}
+
+	err := cacheMock.Delete(context.TODO(), sessionKey("2"))
+	assert.Nil(t, err)
+
+	got, err := r.FindMany(context.TODO(), 1)
+	assert.Nil(t, err)
+	assert.Equal(t, []session.Schema{*newSession("1", 1)}, got)
+
+	keys, err := cacheMock.Keys(context.TODO(), userSessionsTag(1))
+	assert.Nil(t, err)
+	assert.Equal(t, []string{sessionKey("1")}, keys)
+}
+
+func TestSessionRepository_Touch(t *testing.T) {
+	cacheMock := cache.NewMock(t)
+	r := New(cacheMock, time.Hour)
+	ctx := context.TODO()
+
+	err := r.Save(ctx, newSession("1", 1))
+	assert.Nil(t, err)
+
+	data, err := cacheMock.Get(ctx, sessionKey("1"))
+	assert.Nil(t, err)
+	err = cacheMock.Set(ctx, sessionKey("1"), data, time.Minute)
+	assert.Nil(t, err)
+
+	now := time.Date(2001, 1, 1, 0, 0, 0, 0, time.UTC)
+	err = r.Touch(ctx, "1", now)
+	assert.Nil(t, err)
+
+	got, err := r.FindOne(ctx, "1")
+	assert.Nil(t, err)
+	assert.Equal(t, now, got.LastSeenAt)
+
+	ttl, err := cacheMock.TTL(ctx, sessionKey("1"))
+	assert.Nil(t, err)
+	assert.Equal(t, time.Minute, ttl, "the expiration is kept")
+
+	err = r.Delete(ctx, 1, "1")
+	assert.Nil(t, err)
+
+	err = r.Touch(ctx, "1", now)
+	assert.Equal(t, errorMsg.ErrSessionNotFound, err)
+
+	_, err = r.FindOne(ctx, "1")
+	assert.Equal(t, errorMsg.ErrSessionNotFound, err, "revoked sessions are not revived")
+}
+
+func TestSessionRepository_Delete(t *testing.T) {
+	cacheMock := cache.NewMock(t)
+	r := New(cacheMock, time.Hour)
+
+	for _, s := range []*session.Schema{newSession("1", 1), newSession("2", 1)} {
+		err := r.Save(context.TODO(), s)
+		assert.Nil(t, err)
+	}
+
+	err := r.Delete(context.TODO(), 1, "1")
+	assert.Nil(t, err)
+
+	_, err = r.FindOne(context.TODO(), "1")
+	assert.Equal(t, errorMsg.ErrSessionNotFound, err)
+
+	got, err := r.FindMany(context.TODO(), 1)
+	assert.Nil(t, err)
+	assert.Equal(t, []session.Schema{*newSession("2", 1)}, got)
+}
+
+func TestSessionRepository_Rotate(t *testing.T) {
+	cacheMock := cache.NewMock(t)
+	r := New(cacheMock, time.Hour)
+
+	s := newSession("1", 1)
+	err := r.Save(context.TODO(), s)
+	assert.Nil(t, err)
+
+	s.Secret = "rotated"
+	err = r.Rotate(context.TODO(), s, "secret")
+	assert.Nil(t, err)
+
+	sessionID, err := r.FindRotated(context.TODO(), "secret")
+	assert.Nil(t, err)
+	assert.Equal(t, "1", sessionID)
+
+	_, err = r.FindRotated(context.TODO(), "rotated")
+	assert.Equal(t, errorMsg.ErrSessionNotFound, err)
+
+	s.Secret = "rotated-again"
+	err = r.Rotate(context.TODO(), s, "secret")
+	assert.Equal(t, errorMsg.ErrRefreshTokenReused, err)
+
+	got, err := r.FindOne(context.TODO(), "1")
+	assert.Nil(t, err)
+	assert.Equal(t, "rotated", got.Secret)
+}
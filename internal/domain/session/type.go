@@ -0,0 +1,20 @@
+package session
+
+import "time"
+
+type Schema struct {
+	ID          string    `json:"id"`
+	Secret      string    `json:"secret"`
+	UserID      uint64    `json:"userId"`
+	WorkspaceID uint64    `json:"workspaceId,omitempty"`
+	Device      string    `json:"device"`
+	IP          string    `json:"ip"`
+	CreatedAt   time.Time `json:"createdAt"`
+	LastSeenAt  time.Time `json:"lastSeenAt"`
+}
+
+type Tokens struct {
+	AccessToken  string
+	RefreshToken string
+	ExpiresIn    time.Duration
+}
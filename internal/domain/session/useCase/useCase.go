@@ -0,0 +1,269 @@
+package useCase
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/hex"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/henriqueassiss/advanced-golang-api/internal/domain/session"
+	"github.com/henriqueassiss/advanced-golang-api/internal/domain/session/repository"
+	userUseCase "github.com/henriqueassiss/advanced-golang-api/internal/domain/user/useCase"
+	"github.com/henriqueassiss/advanced-golang-api/internal/utils/errorMsg"
+	"github.com/henriqueassiss/advanced-golang-api/internal/utils/identity"
+	"github.com/henriqueassiss/advanced-golang-api/third_party/token"
+)
+
+// touchInterval bounds how often an authenticated request rewrites the
+// session just to move its last-seen time forward.
+const touchInterval = time.Minute
+
+type ISession interface {
+	SignIn(ctx context.Context, email, password, device, ip string, workspaceID uint64) (*session.Tokens, *session.Schema, error)
+	Issue(ctx context.Context, userID uint64, device, ip string, workspaceID uint64) (*session.Tokens, *session.Schema, error)
+	Refresh(ctx context.Context, refreshToken, ip string) (*session.Tokens, error)
+	Authenticate(ctx context.Context, accessToken string) (*identity.Identity, error)
+	FindMany(ctx context.Context, userID uint64) ([]session.Schema, error)
+	Delete(ctx context.Context, userID uint64, sessionID string) error
+	DeleteOthers(ctx context.Context, userID uint64, currentSessionID string) error
+	DeleteAll(ctx context.Context, userID uint64) error
+}
+
+type Session struct {
+	repository repository.ISession
+	user       userUseCase.IUser
+	tokens     *token.Manager
+	logger     *slog.Logger
+}
+
+func New(repo repository.ISession, user userUseCase.IUser, tokens *token.Manager, logger *slog.Logger) *Session {
+	return &Session{
+		repository: repo,
+		user:       user,
+		tokens:     tokens,
+		logger:     logger,
+	}
+}
+
+func randomString(size int) (string, error) {
+	b := make([]byte, size)
+	_, err := rand.Read(b)
+	if err != nil {
+		return "", err
+	}
+
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+func hashSecret(secret string) string {
+	sum := sha256.Sum256([]byte(secret))
+	return hex.EncodeToString(sum[:])
+}
+
+func (uc *Session) issueTokens(s *session.Schema, secret string) (*session.Tokens, error) {
+	accessToken, err := uc.tokens.Sign(token.Payload{
+		UserID:      s.UserID,
+		SessionID:   s.ID,
+		WorkspaceID: s.WorkspaceID,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &session.Tokens{
+		AccessToken:  accessToken,
+		RefreshToken: s.ID + "." + secret,
+		ExpiresIn:    uc.tokens.TTL(),
+	}, nil
+}
+
+func (uc *Session) SignIn(ctx context.Context, email, password, device, ip string, workspaceID uint64) (*session.Tokens, *session.Schema, error) {
+	u, err := uc.user.Authenticate(ctx, email, password)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return uc.Issue(ctx, u.ID, device, ip, workspaceID)
+}
+
+// Issue starts a session for a user whose identity was already established,
+// by password or by an identity provider.
+func (uc *Session) Issue(ctx context.Context, userID uint64, device, ip string, workspaceID uint64) (*session.Tokens, *session.Schema, error) {
+	id, err := randomString(16)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	secret, err := randomString(32)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	now := time.Now()
+	s := session.Schema{
+		ID:          id,
+		Secret:      hashSecret(secret),
+		UserID:      userID,
+		WorkspaceID: workspaceID,
+		Device:      device,
+		IP:          ip,
+		CreatedAt:   now,
+		LastSeenAt:  now,
+	}
+
+	err = uc.repository.Save(ctx, &s)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	tokens, err := uc.issueTokens(&s, secret)
+
+	return tokens, &s, err
+}
+
+// Refresh rotates the refresh token of a session. Every session is a token
+// family: presenting a refresh token that was already rotated means it was
+// copied, so the whole family is revoked.
+func (uc *Session) Refresh(ctx context.Context, refreshToken, ip string) (*session.Tokens, error) {
+	id, secret, found := strings.Cut(refreshToken, ".")
+	if !found {
+		return nil, errorMsg.ErrUnauthorized
+	}
+
+	s, err := uc.repository.FindOne(ctx, id)
+	if err != nil {
+		if err == errorMsg.ErrSessionNotFound {
+			return nil, errorMsg.ErrUnauthorized
+		}
+
+		return nil, err
+	}
+
+	previousSecret := hashSecret(secret)
+	if subtle.ConstantTimeCompare([]byte(s.Secret), []byte(previousSecret)) != 1 {
+		rotatedID, err := uc.repository.FindRotated(ctx, previousSecret)
+		if err != nil || rotatedID != s.ID {
+			return nil, errorMsg.ErrUnauthorized
+		}
+
+		return nil, uc.revoke(ctx, s)
+	}
+
+	newSecret, err := randomString(32)
+	if err != nil {
+		return nil, err
+	}
+
+	s.Secret = hashSecret(newSecret)
+	s.IP = ip
+	s.LastSeenAt = time.Now()
+
+	err = uc.repository.Rotate(ctx, s, previousSecret)
+	if err != nil {
+		if err == errorMsg.ErrRefreshTokenReused {
+			return nil, uc.revoke(ctx, s)
+		}
+
+		if err == errorMsg.ErrSessionNotFound {
+			return nil, errorMsg.ErrUnauthorized
+		}
+
+		return nil, err
+	}
+
+	return uc.issueTokens(s, newSecret)
+}
+
+func (uc *Session) revoke(ctx context.Context, s *session.Schema) error {
+	uc.logger.Warn(errorMsg.ErrRefreshTokenReused.Error(), "session", s.ID, "user", s.UserID)
+
+	err := uc.repository.Delete(ctx, s.UserID, s.ID)
+	if err != nil {
+		return err
+	}
+
+	return errorMsg.ErrRefreshTokenReused
+}
+
+func (uc *Session) Authenticate(ctx context.Context, accessToken string) (*identity.Identity, error) {
+	p, err := uc.tokens.Parse(accessToken)
+	if err != nil {
+		return nil, errorMsg.ErrUnauthorized
+	}
+
+	s, err := uc.repository.FindOne(ctx, p.SessionID)
+	if err != nil {
+		if err == errorMsg.ErrSessionNotFound {
+			return nil, errorMsg.ErrUnauthorized
+		}
+
+		return nil, err
+	}
+
+	if s.UserID != p.UserID {
+		return nil, errorMsg.ErrUnauthorized
+	}
+
+	if time.Since(s.LastSeenAt) > touchInterval {
+		err = uc.repository.Touch(ctx, s.ID, time.Now())
+		if err != nil {
+			uc.logger.Error(err.Error(), "session", s.ID)
+		}
+	}
+
+	return &identity.Identity{
+		UserID:      s.UserID,
+		SessionID:   s.ID,
+		WorkspaceID: p.WorkspaceID,
+	}, nil
+}
+
+func (uc *Session) FindMany(ctx context.Context, userID uint64) ([]session.Schema, error) {
+	return uc.repository.FindMany(ctx, userID)
+}
+
+func (uc *Session) Delete(ctx context.Context, userID uint64, sessionID string) error {
+	s, err := uc.repository.FindOne(ctx, sessionID)
+	if err != nil {
+		return err
+	}
+
+	if s.UserID != userID {
+		return errorMsg.ErrSessionNotFound
+	}
+
+	return uc.repository.Delete(ctx, userID, sessionID)
+}
+
+func (uc *Session) deleteWhere(ctx context.Context, userID uint64, keep func(s *session.Schema) bool) error {
+	ss, err := uc.repository.FindMany(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	var ids []string
+	for i := range ss {
+		if !keep(&ss[i]) {
+			ids = append(ids, ss[i].ID)
+		}
+	}
+
+	return uc.repository.Delete(ctx, userID, ids...)
+}
+
+func (uc *Session) DeleteOthers(ctx context.Context, userID uint64, currentSessionID string) error {
+	return uc.deleteWhere(ctx, userID, func(s *session.Schema) bool {
+		return s.ID == currentSessionID
+	})
+}
+
+func (uc *Session) DeleteAll(ctx context.Context, userID uint64) error {
+	return uc.deleteWhere(ctx, userID, func(s *session.Schema) bool {
+		return false
+	})
+}
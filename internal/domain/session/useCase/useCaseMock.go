@@ -0,0 +1,51 @@
+package useCase
+
+import (
+	"context"
+
+	"github.com/henriqueassiss/advanced-golang-api/internal/domain/session"
+	"github.com/henriqueassiss/advanced-golang-api/internal/utils/identity"
+)
+
+type SessionMock struct {
+	SignInFunc       func(ctx context.Context, email, password, device, ip string, workspaceID uint64) (*session.Tokens, *session.Schema, error)
+	IssueFunc        func(ctx context.Context, userID uint64, device, ip string, workspaceID uint64) (*session.Tokens, *session.Schema, error)
+	RefreshFunc      func(ctx context.Context, refreshToken, ip string) (*session.Tokens, error)
+	AuthenticateFunc func(ctx context.Context, accessToken string) (*identity.Identity, error)
+	FindManyFunc     func(ctx context.Context, userID uint64) ([]session.Schema, error)
+	DeleteFunc       func(ctx context.Context, userID uint64, sessionID string) error
+	DeleteOthersFunc func(ctx context.Context, userID uint64, currentSessionID string) error
+	DeleteAllFunc    func(ctx context.Context, userID uint64) error
+}
+
+func (uc *SessionMock) SignIn(ctx context.Context, email, password, device, ip string, workspaceID uint64) (*session.Tokens, *session.Schema, error) {
+	return uc.SignInFunc(ctx, email, password, device, ip, workspaceID)
+}
+
+func (uc *SessionMock) Issue(ctx context.Context, userID uint64, device, ip string, workspaceID uint64) (*session.Tokens, *session.Schema, error) {
+	return uc.IssueFunc(ctx, userID, device, ip, workspaceID)
+}
+
+func (uc *SessionMock) Refresh(ctx context.Context, refreshToken, ip string) (*session.Tokens, error) {
+	return uc.RefreshFunc(ctx, refreshToken, ip)
+}
+
+func (uc *SessionMock) Authenticate(ctx context.Context, accessToken string) (*identity.Identity, error) {
+	return uc.AuthenticateFunc(ctx, accessToken)
+}
+
+func (uc *SessionMock) FindMany(ctx context.Context, userID uint64) ([]session.Schema, error) {
+	return uc.FindManyFunc(ctx, userID)
+}
+
+func (uc *SessionMock) Delete(ctx context.Context, userID uint64, sessionID string) error {
+	return uc.DeleteFunc(ctx, userID, sessionID)
+}
+
+func (uc *SessionMock) DeleteOthers(ctx context.Context, userID uint64, currentSessionID string) error {
+	return uc.DeleteOthersFunc(ctx, userID, currentSessionID)
+}
+
+func (uc *SessionMock) DeleteAll(ctx context.Context, userID uint64) error {
+	return uc.DeleteAllFunc(ctx, userID)
+}
@@ -0,0 +1,220 @@
+package useCase
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/henriqueassiss/advanced-golang-api/internal/domain/session/repository"
+	"github.com/henriqueassiss/advanced-golang-api/internal/domain/user"
+	userUseCase "github.com/henriqueassiss/advanced-golang-api/internal/domain/user/useCase"
+	"github.com/henriqueassiss/advanced-golang-api/internal/utils/errorMsg"
+
+	"github.com/henriqueassiss/advanced-golang-api/third_party/cache"
+	"github.com/henriqueassiss/advanced-golang-api/third_party/logger"
+	"github.com/henriqueassiss/advanced-golang-api/third_party/token"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func newUseCase(t *testing.T) *Session {
+	cacheMock := cache.NewMock(t)
+	r := repository.New(cacheMock, time.Hour)
+	u := &userUseCase.UserMock{
+		AuthenticateFunc: func(ctx context.Context, email, password string) (*user.Schema, error) {
+			if password != "12345678" {
+				return nil, errorMsg.ErrInvalidCredentials
+			}
+
+			return &user.Schema{ID: 1, Email: email}, nil
+		},
+	}
+
+	return New(r, u, token.New("secret", time.Minute), logger.New())
+}
+
+func TestSessionUseCase_SignIn(t *testing.T) {
+	uc := newUseCase(t)
+
+	type want struct {
+		err error
+	}
+
+	type test struct {
+		name     string
+		password string
+		want
+	}
+
+	tests := []test{
+		{
+			name:     "Success",
+			password: "12345678",
+		},
+		{
+			name:     "Fail - Invalid credentials",
+			password: "wrong-password",
+			want: want{
+				err: errorMsg.ErrInvalidCredentials,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tokens, s, err := uc.SignIn(context.TODO(), "test@test.com", tt.password, "Test", "127.0.0.1", 0)
+			assert.Equal(t, tt.want.err, err)
+			if tt.want.err != nil {
+				return
+			}
+
+			i, err := uc.Authenticate(context.TODO(), tokens.AccessToken)
+			assert.Nil(t, err)
+			assert.Equal(t, s.ID, i.SessionID)
+			assert.Equal(t, uint64(1), i.UserID)
+			assert.Equal(t, time.Minute, tokens.ExpiresIn)
+			assert.NotContains(t, tokens.RefreshToken, s.Secret)
+		})
+	}
+}
+
+func TestSessionUseCase_Authenticate(t *testing.T) {
+	uc := newUseCase(t)
+
+	tokens, s, err := uc.SignIn(context.TODO(), "test@test.com", "12345678", "Test", "127.0.0.1", 0)
+	assert.Nil(t, err)
+
+	foreign, err := token.New("other-secret", time.Minute).Sign(token.Payload{UserID: 1, SessionID: s.ID})
+	assert.Nil(t, err)
+
+	expired, err := token.New("secret", -time.Minute).Sign(token.Payload{UserID: 1, SessionID: s.ID})
+	assert.Nil(t, err)
+
+	tests := []struct {
+		name  string
+		token string
+		err   error
+	}{
+		{
+			name:  "Success",
+			token: tokens.AccessToken,
+		},
+		{
+			name:  "Fail - Refresh token used as access token",
+			token: tokens.RefreshToken,
+			err:   errorMsg.ErrUnauthorized,
+		},
+		{
+			name:  "Fail - Signed with another secret",
+			token: foreign,
+			err:   errorMsg.ErrUnauthorized,
+		},
+		{
+			name:  "Fail - Expired",
+			token: expired,
+			err:   errorMsg.ErrUnauthorized,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := uc.Authenticate(context.TODO(), tt.token)
+			assert.Equal(t, tt.err, err)
+		})
+	}
+}
+
+func TestSessionUseCase_Refresh(t *testing.T) {
+	uc := newUseCase(t)
+
+	first, _, err := uc.SignIn(context.TODO(), "test@test.com", "12345678", "Test", "127.0.0.1", 0)
+	assert.Nil(t, err)
+
+	second, err := uc.Refresh(context.TODO(), first.RefreshToken, "127.0.0.2")
+	assert.Nil(t, err)
+	assert.NotEqual(t, first.RefreshToken, second.RefreshToken)
+
+	_, err = uc.Authenticate(context.TODO(), second.AccessToken)
+	assert.Nil(t, err)
+
+	_, err = uc.Refresh(context.TODO(), "malformed", "127.0.0.2")
+	assert.Equal(t, errorMsg.ErrUnauthorized, err)
+
+	// Replaying the rotated token revokes the whole family, including the
+	// tokens issued after it.
+	_, err = uc.Refresh(context.TODO(), first.RefreshToken, "127.0.0.3")
+	assert.Equal(t, errorMsg.ErrRefreshTokenReused, err)
+
+	_, err = uc.Refresh(context.TODO(), second.RefreshToken, "127.0.0.2")
+	assert.Equal(t, errorMsg.ErrUnauthorized, err)
+
+	_, err = uc.Authenticate(context.TODO(), second.AccessToken)
+	assert.Equal(t, errorMsg.ErrUnauthorized, err)
+}
+
+func TestSessionUseCase_Delete(t *testing.T) {
+	uc := newUseCase(t)
+
+	tokens, s, err := uc.SignIn(context.TODO(), "test@test.com", "12345678", "Test", "127.0.0.1", 0)
+	assert.Nil(t, err)
+
+	err = uc.Delete(context.TODO(), 2, s.ID)
+	assert.Equal(t, errorMsg.ErrSessionNotFound, err)
+
+	err = uc.Delete(context.TODO(), 1, s.ID)
+	assert.Nil(t, err)
+
+	_, err = uc.Authenticate(context.TODO(), tokens.AccessToken)
+	assert.Equal(t, errorMsg.ErrUnauthorized, err)
+
+	_, err = uc.Refresh(context.TODO(), tokens.RefreshToken, "127.0.0.1")
+	assert.Equal(t, errorMsg.ErrUnauthorized, err)
+}
+
+func TestSessionUseCase_DeleteOthers(t *testing.T) {
+	uc := newUseCase(t)
+
+	currentTokens, current, err := uc.SignIn(context.TODO(), "test@test.com", "12345678", "Current", "127.0.0.1", 0)
+	assert.Nil(t, err)
+
+	otherTokens, _, err := uc.SignIn(context.TODO(), "test@test.com", "12345678", "Other", "127.0.0.2", 0)
+	assert.Nil(t, err)
+
+	err = uc.DeleteOthers(context.TODO(), 1, current.ID)
+	assert.Nil(t, err)
+
+	_, err = uc.Authenticate(context.TODO(), currentTokens.AccessToken)
+	assert.Nil(t, err)
+
+	_, err = uc.Authenticate(context.TODO(), otherTokens.AccessToken)
+	assert.Equal(t, errorMsg.ErrUnauthorized, err)
+
+	ss, err := uc.FindMany(context.TODO(), 1)
+	assert.Nil(t, err)
+	assert.Len(t, ss, 1)
+	assert.Equal(t, current.ID, ss[0].ID)
+}
+
+func TestSessionUseCase_DeleteAll(t *testing.T) {
+	uc := newUseCase(t)
+
+	var accessTokens []string
+	for i := 0; i < 3; i++ {
+		tokens, _, err := uc.SignIn(context.TODO(), "test@test.com", "12345678", "Test", "127.0.0.1", 0)
+		assert.Nil(t, err)
+
+		accessTokens = append(accessTokens, tokens.AccessToken)
+	}
+
+	err := uc.DeleteAll(context.TODO(), 1)
+	assert.Nil(t, err)
+
+	for _, accessToken := range accessTokens {
+		_, err = uc.Authenticate(context.TODO(), accessToken)
+		assert.Equal(t, errorMsg.ErrUnauthorized, err)
+	}
+
+	ss, err := uc.FindMany(context.TODO(), 1)
+	assert.Nil(t, err)
+	assert.Empty(t, ss)
+}
@@ -0,0 +1,74 @@
+package handler
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+
+	sessionHandler "github.com/henriqueassiss/advanced-golang-api/internal/domain/session/handler"
+	"github.com/henriqueassiss/advanced-golang-api/internal/domain/sso/useCase"
+	"github.com/henriqueassiss/advanced-golang-api/internal/utils/errorMsg"
+	"github.com/henriqueassiss/advanced-golang-api/internal/utils/reqRes"
+	"github.com/henriqueassiss/advanced-golang-api/third_party/oidc"
+)
+
+type ISSO struct {
+	useCase useCase.ISSO
+	logger  *slog.Logger
+}
+
+func NewHandler(useCase useCase.ISSO, logger *slog.Logger) *ISSO {
+	return &ISSO{
+		useCase: useCase,
+		logger:  logger,
+	}
+}
+
+func errorStatus(err error) int {
+	switch {
+	case err == errorMsg.ErrInvalidRequestData:
+		return http.StatusBadRequest
+	case err == errorMsg.ErrLoginExpired,
+		errors.Is(err, oidc.ErrExchange),
+		errors.Is(err, oidc.ErrInvalidIDToken):
+		return http.StatusUnauthorized
+	case err == errorMsg.ErrForbidden,
+		err == errorMsg.ErrEmailNotVerified:
+		return http.StatusForbidden
+	}
+
+	return http.StatusInternalServerError
+}
+
+func (h *ISSO) Login(w http.ResponseWriter, r *http.Request) {
+	authURL, err := h.useCase.Begin(r.Context())
+	if err != nil {
+		reqRes.Error(h.logger, w, http.StatusInternalServerError, err, nil)
+		return
+	}
+
+	http.Redirect(w, r, authURL, http.StatusFound)
+}
+
+func (h *ISSO) Callback(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	if q.Get("error") != "" {
+		reqRes.Error(h.logger, w, http.StatusUnauthorized, errorMsg.ErrUnauthorized, q.Get("error"))
+		return
+	}
+
+	state, code := q.Get("state"), q.Get("code")
+	if state == "" || code == "" {
+		reqRes.Error(h.logger, w, http.StatusBadRequest, errorMsg.ErrInvalidRequestData, nil)
+		return
+	}
+
+	device := reqRes.GetRequestDevice(r.UserAgent())
+	tokens, s, err := h.useCase.Complete(r.Context(), state, code, device, reqRes.GetRequestIP(r))
+	if err != nil {
+		reqRes.Error(h.logger, w, errorStatus(err), err, nil)
+		return
+	}
+
+	reqRes.Json(w, http.StatusOK, sessionHandler.ToSignedIn(tokens, s))
+}
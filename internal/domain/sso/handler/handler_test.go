@@ -0,0 +1,129 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/henriqueassiss/advanced-golang-api/internal/domain/session"
+	sessionHandler "github.com/henriqueassiss/advanced-golang-api/internal/domain/session/handler"
+	"github.com/henriqueassiss/advanced-golang-api/internal/domain/sso/useCase"
+	"github.com/henriqueassiss/advanced-golang-api/internal/utils/errorMsg"
+	"github.com/henriqueassiss/advanced-golang-api/internal/utils/reqRes"
+
+	"github.com/henriqueassiss/advanced-golang-api/third_party/logger"
+	"github.com/henriqueassiss/advanced-golang-api/third_party/oidc"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSSOHandler_Login(t *testing.T) {
+	uc := &useCase.SSOMock{
+		BeginFunc: func(ctx context.Context) (string, error) {
+			return "https://sso.example.com/authorize?state=s", nil
+		},
+	}
+
+	router := chi.NewRouter()
+	RegisterHTTPEndPoints(uc, logger.New(), router)
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/v1/sso/login", nil))
+
+	assert.Equal(t, http.StatusFound, w.Code)
+	assert.Equal(t, "https://sso.example.com/authorize?state=s", w.Header().Get("Location"))
+}
+
+func TestSSOHandler_Callback(t *testing.T) {
+	logger := logger.New()
+
+	type test struct {
+		name   string
+		query  string
+		err    error
+		status int
+	}
+
+	tests := []test{
+		{
+			name:   "Success",
+			query:  "state=s&code=c",
+			status: http.StatusOK,
+		},
+		{
+			name:   "Fail - Denied at the provider",
+			query:  "state=s&error=access_denied",
+			status: http.StatusUnauthorized,
+		},
+		{
+			name:   "Fail - Code not supplied",
+			query:  "state=s",
+			status: http.StatusBadRequest,
+		},
+		{
+			name:   "Fail - Unknown state",
+			query:  "state=s&code=c",
+			err:    errorMsg.ErrLoginExpired,
+			status: http.StatusUnauthorized,
+		},
+		{
+			name:   "Fail - Invalid id token",
+			query:  "state=s&code=c",
+			err:    fmt.Errorf("%w: nonce mismatch", oidc.ErrInvalidIDToken),
+			status: http.StatusUnauthorized,
+		},
+		{
+			name:   "Fail - No role",
+			query:  "state=s&code=c",
+			err:    errorMsg.ErrForbidden,
+			status: http.StatusForbidden,
+		},
+		{
+			name:   "Fail - Unverified email",
+			query:  "state=s&code=c",
+			err:    errorMsg.ErrEmailNotVerified,
+			status: http.StatusForbidden,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			uc := &useCase.SSOMock{
+				CompleteFunc: func(ctx context.Context, state, code, device, ip string) (*session.Tokens, *session.Schema, error) {
+					assert.Equal(t, "s", state)
+					assert.Equal(t, "c", code)
+
+					if tt.err != nil {
+						return nil, nil, tt.err
+					}
+
+					return &session.Tokens{AccessToken: "access"}, &session.Schema{ID: "1", IP: ip}, nil
+				},
+			}
+
+			router := chi.NewRouter()
+			RegisterHTTPEndPoints(uc, logger, router)
+
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/v1/sso/callback?"+tt.query, nil))
+
+			assert.Equal(t, tt.status, w.Code)
+
+			if tt.status == http.StatusOK {
+				var got reqRes.GenericResponse[sessionHandler.SignedIn]
+				err := json.NewDecoder(w.Body).Decode(&got)
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				assert.Equal(t, "access", got.Data.AccessToken)
+				assert.Equal(t, "1", got.Data.Session.ID)
+				assert.True(t, got.Data.Session.Current)
+			}
+		})
+	}
+}
@@ -0,0 +1,19 @@
+package handler
+
+import (
+	"log/slog"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/henriqueassiss/advanced-golang-api/internal/domain/sso/useCase"
+)
+
+func RegisterHTTPEndPoints(u useCase.ISSO, logger *slog.Logger, router *chi.Mux, middlewares ...func(http.Handler) http.Handler) *ISSO {
+	handler := NewHandler(u, logger)
+	router.Route("/v1/sso", func(router chi.Router) {
+		router.Use(middlewares...)
+		router.Get("/login", handler.Login)
+		router.Get("/callback", handler.Callback)
+	})
+	return handler
+}
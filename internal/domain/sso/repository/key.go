@@ -0,0 +1,7 @@
+package repository
+
+import "fmt"
+
+func loginKey(state string) string {
+	return fmt.Sprintf("sso:login:%s", state)
+}
@@ -0,0 +1,54 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/henriqueassiss/advanced-golang-api/internal/domain/sso"
+	"github.com/henriqueassiss/advanced-golang-api/internal/utils/errorMsg"
+	"github.com/henriqueassiss/advanced-golang-api/third_party/cache"
+)
+
+type ISSO interface {
+	Save(ctx context.Context, l *sso.Login) error
+	Take(ctx context.Context, state string) (*sso.Login, error)
+}
+
+type SSO struct {
+	cache cache.Cache
+	ttl   time.Duration
+}
+
+func New(cache cache.Cache, ttl time.Duration) *SSO {
+	return &SSO{
+		cache: cache,
+		ttl:   ttl,
+	}
+}
+
+func (r *SSO) Save(ctx context.Context, l *sso.Login) error {
+	data, err := json.Marshal(l)
+	if err != nil {
+		return err
+	}
+
+	return r.cache.Set(ctx, loginKey(l.State), data, r.ttl)
+}
+
+// Take returns and removes a pending login, so a callback cannot be replayed.
+func (r *SSO) Take(ctx context.Context, state string) (*sso.Login, error) {
+	data, err := r.cache.Pop(ctx, loginKey(state))
+	if err != nil {
+		if err == cache.ErrNotFound {
+			return nil, errorMsg.ErrLoginExpired
+		}
+
+		return nil, err
+	}
+
+	var l sso.Login
+	err = json.Unmarshal(data, &l)
+
+	return &l, err
+}
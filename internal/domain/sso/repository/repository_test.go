@@ -0,0 +1,36 @@
+package repository
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/henriqueassiss/advanced-golang-api/internal/domain/sso"
+	"github.com/henriqueassiss/advanced-golang-api/internal/utils/errorMsg"
+	"github.com/henriqueassiss/advanced-golang-api/third_party/cache"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSSORepository_Take(t *testing.T) {
+	cacheMock := cache.NewMock(t)
+	r := New(cacheMock, time.Minute)
+
+	l := &sso.Login{State: "state", Nonce: "nonce", Verifier: "verifier"}
+	err := r.Save(context.TODO(), l)
+	assert.Nil(t, err)
+
+	ttl, err := cacheMock.TTL(context.TODO(), loginKey("state"))
+	assert.Nil(t, err)
+	assert.Equal(t, time.Minute, ttl)
+
+	got, err := r.Take(context.TODO(), "state")
+	assert.Nil(t, err)
+	assert.Equal(t, l, got)
+
+	_, err = r.Take(context.TODO(), "state")
+	assert.Equal(t, errorMsg.ErrLoginExpired, err)
+
+	_, err = r.Take(context.TODO(), "unknown")
+	assert.Equal(t, errorMsg.ErrLoginExpired, err)
+}
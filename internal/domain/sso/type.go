@@ -0,0 +1,9 @@
+package sso
+
+// Login is an authorization request waiting for the identity provider to
+// redirect back. It is looked up by State and can be completed once.
+type Login struct {
+	State    string `json:"state"`
+	Nonce    string `json:"nonce"`
+	Verifier string `json:"verifier"`
+}
@@ -0,0 +1,134 @@
+package useCase
+
+import (
+	"context"
+	"log/slog"
+
+	"github.com/henriqueassiss/advanced-golang-api/config"
+	"github.com/henriqueassiss/advanced-golang-api/internal/domain/session"
+	sessionUseCase "github.com/henriqueassiss/advanced-golang-api/internal/domain/session/useCase"
+	"github.com/henriqueassiss/advanced-golang-api/internal/domain/sso"
+	"github.com/henriqueassiss/advanced-golang-api/internal/domain/sso/repository"
+	"github.com/henriqueassiss/advanced-golang-api/internal/domain/user"
+	userUseCase "github.com/henriqueassiss/advanced-golang-api/internal/domain/user/useCase"
+	"github.com/henriqueassiss/advanced-golang-api/internal/domain/workspace"
+	workspaceUseCase "github.com/henriqueassiss/advanced-golang-api/internal/domain/workspace/useCase"
+	"github.com/henriqueassiss/advanced-golang-api/internal/utils/errorMsg"
+	"github.com/henriqueassiss/advanced-golang-api/third_party/oidc"
+)
+
+type ISSO interface {
+	Begin(ctx context.Context) (string, error)
+	Complete(ctx context.Context, state, code, device, ip string) (*session.Tokens, *session.Schema, error)
+}
+
+type SSO struct {
+	repository repository.ISSO
+	provider   *oidc.Provider
+	user       userUseCase.IUser
+	workspace  workspaceUseCase.IWorkspace
+	session    sessionUseCase.ISession
+	cfg        config.OIDC
+	logger     *slog.Logger
+}
+
+func New(
+	repo repository.ISSO,
+	provider *oidc.Provider,
+	user userUseCase.IUser,
+	workspace workspaceUseCase.IWorkspace,
+	session sessionUseCase.ISession,
+	cfg config.OIDC,
+	logger *slog.Logger,
+) *SSO {
+	return &SSO{
+		repository: repo,
+		provider:   provider,
+		user:       user,
+		workspace:  workspace,
+		session:    session,
+		cfg:        cfg,
+		logger:     logger,
+	}
+}
+
+// Begin starts an authorization code flow and returns the provider URL the
+// user has to be sent to.
+func (uc *SSO) Begin(ctx context.Context) (string, error) {
+	var l sso.Login
+	for _, v := range []*string{&l.State, &l.Nonce, &l.Verifier} {
+		s, err := oidc.NewVerifier()
+		if err != nil {
+			return "", err
+		}
+
+		*v = s
+	}
+
+	err := uc.repository.Save(ctx, &l)
+	if err != nil {
+		return "", err
+	}
+
+	return uc.provider.AuthCodeURL(ctx, l.State, l.Nonce, l.Verifier)
+}
+
+// Complete handles the provider's redirect: it redeems the code, validates
+// the ID token, provisions the user and their workspace role and starts a
+// session.
+func (uc *SSO) Complete(ctx context.Context, state, code, device, ip string) (*session.Tokens, *session.Schema, error) {
+	l, err := uc.repository.Take(ctx, state)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	rawIDToken, err := uc.provider.Exchange(ctx, code, l.Verifier)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	claims, err := uc.provider.Verify(ctx, rawIDToken, l.Nonce)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	role := uc.role(claims)
+	if role == "" {
+		return nil, nil, errorMsg.ErrForbidden
+	}
+
+	u, err := uc.user.Provision(ctx, &user.Identity{
+		Issuer:  uc.cfg.Issuer,
+		Subject: claims.Subject,
+	}, claims.Name, claims.Email, claims.EmailVerified)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	workspaceID, err := uc.workspace.Provision(ctx, uc.cfg.Workspace, u.ID, role)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return uc.session.Issue(ctx, u.ID, device, ip, workspaceID)
+}
+
+// role maps the configured claim to a workspace role, preferring the most
+// privileged one when several values match.
+func (uc *SSO) role(claims *oidc.Claims) string {
+	role := ""
+	for _, v := range claims.Strings(uc.cfg.RoleClaim) {
+		switch uc.cfg.Roles[v] {
+		case workspace.RoleOwner:
+			return workspace.RoleOwner
+		case workspace.RoleMember:
+			role = workspace.RoleMember
+		}
+	}
+
+	if role == "" {
+		return uc.cfg.DefaultRole
+	}
+
+	return role
+}
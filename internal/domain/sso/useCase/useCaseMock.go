@@ -0,0 +1,20 @@
+package useCase
+
+import (
+	"context"
+
+	"github.com/henriqueassiss/advanced-golang-api/internal/domain/session"
+)
+
+type SSOMock struct {
+	BeginFunc    func(ctx context.Context) (string, error)
+	CompleteFunc func(ctx context.Context, state, code, device, ip string) (*session.Tokens, *session.Schema, error)
+}
+
+func (uc *SSOMock) Begin(ctx context.Context) (string, error) {
+	return uc.BeginFunc(ctx)
+}
+
+func (uc *SSOMock) Complete(ctx context.Context, state, code, device, ip string) (*session.Tokens, *session.Schema, error) {
+	return uc.CompleteFunc(ctx, state, code, device, ip)
+}
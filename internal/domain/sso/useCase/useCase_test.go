@@ -0,0 +1,168 @@
+package useCase
+
+import (
+	"context"
+	"errors"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/henriqueassiss/advanced-golang-api/config"
+	"github.com/henriqueassiss/advanced-golang-api/internal/domain/session"
+	sessionUseCase "github.com/henriqueassiss/advanced-golang-api/internal/domain/session/useCase"
+	"github.com/henriqueassiss/advanced-golang-api/internal/domain/sso/repository"
+	"github.com/henriqueassiss/advanced-golang-api/internal/domain/user"
+	userUseCase "github.com/henriqueassiss/advanced-golang-api/internal/domain/user/useCase"
+	"github.com/henriqueassiss/advanced-golang-api/internal/domain/workspace"
+	workspaceUseCase "github.com/henriqueassiss/advanced-golang-api/internal/domain/workspace/useCase"
+	"github.com/henriqueassiss/advanced-golang-api/internal/utils/errorMsg"
+
+	"github.com/henriqueassiss/advanced-golang-api/third_party/cache"
+	"github.com/henriqueassiss/advanced-golang-api/third_party/logger"
+	"github.com/henriqueassiss/advanced-golang-api/third_party/oidc"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+)
+
+type provisioned struct {
+	identity *user.Identity
+	email    string
+	verified bool
+	role     string
+}
+
+func newSSO(t *testing.T, cfg config.OIDC) (*SSO, *oidc.Mock, *provisioned) {
+	provider := oidc.NewMock(t)
+	cfg.Issuer = provider.Config.Issuer
+	cfg.RoleClaim = "groups"
+	cfg.Workspace = "default"
+
+	got := &provisioned{}
+	users := &userUseCase.UserMock{
+		ProvisionFunc: func(ctx context.Context, i *user.Identity, name, email string, emailVerified bool) (*user.Schema, error) {
+			got.identity, got.email, got.verified = i, email, emailVerified
+			return &user.Schema{ID: 7, Name: name, Email: email}, nil
+		},
+	}
+
+	workspaces := &workspaceUseCase.WorkspaceMock{
+		ProvisionFunc: func(ctx context.Context, slug string, userID uint64, role string) (uint64, error) {
+			got.role = role
+			return 3, nil
+		},
+	}
+
+	sessions := &sessionUseCase.SessionMock{
+		IssueFunc: func(ctx context.Context, userID uint64, device, ip string, workspaceID uint64) (*session.Tokens, *session.Schema, error) {
+			s := &session.Schema{ID: "s", UserID: userID, WorkspaceID: workspaceID, Device: device, IP: ip}
+			return &session.Tokens{AccessToken: "access", RefreshToken: "s.secret"}, s, nil
+		},
+	}
+
+	repo := repository.New(cache.NewMock(t), time.Minute)
+	uc := New(repo, oidc.New(provider.Config, nil), users, workspaces, sessions, cfg, logger.New())
+
+	return uc, provider, got
+}
+
+func TestSSOUseCase_Complete(t *testing.T) {
+	uc, provider, got := newSSO(t, config.OIDC{
+		Roles: map[string]string{"admins": workspace.RoleOwner, "staff": workspace.RoleMember},
+	})
+
+	authURL, err := uc.Begin(context.TODO())
+	assert.Nil(t, err)
+
+	redirect := provider.Authorize(t, authURL, jwt.MapClaims{
+		"sub":            "abc",
+		"email":          "test@test.com",
+		"email_verified": true,
+		"name":           "Test",
+		"groups":         []string{"staff", "admins"},
+	})
+
+	state, code := redirect.Query().Get("state"), redirect.Query().Get("code")
+	tokens, s, err := uc.Complete(context.TODO(), state, code, "Firefox", "127.0.0.1")
+	assert.Nil(t, err)
+	assert.Equal(t, "access", tokens.AccessToken)
+	assert.Equal(t, &session.Schema{ID: "s", UserID: 7, WorkspaceID: 3, Device: "Firefox", IP: "127.0.0.1"}, s)
+
+	assert.Equal(t, &user.Identity{Issuer: provider.Config.Issuer, Subject: "abc"}, got.identity)
+	assert.Equal(t, "test@test.com", got.email)
+	assert.True(t, got.verified)
+	assert.Equal(t, workspace.RoleOwner, got.role)
+
+	_, _, err = uc.Complete(context.TODO(), state, code, "Firefox", "127.0.0.1")
+	assert.Equal(t, errorMsg.ErrLoginExpired, err, "a callback cannot be replayed")
+}
+
+func TestSSOUseCase_Complete_Roles(t *testing.T) {
+	type test struct {
+		name        string
+		defaultRole string
+		groups      any
+		role        string
+		err         error
+	}
+
+	tests := []test{
+		{
+			name:   "Success - Mapped role",
+			groups: "staff",
+			role:   workspace.RoleMember,
+		},
+		{
+			name:        "Success - Default role",
+			defaultRole: workspace.RoleMember,
+			groups:      []string{"guests"},
+			role:        workspace.RoleMember,
+		},
+		{
+			name:   "Fail - No mapped role",
+			groups: []string{"guests"},
+			err:    errorMsg.ErrForbidden,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			uc, provider, got := newSSO(t, config.OIDC{
+				Roles:       map[string]string{"staff": workspace.RoleMember},
+				DefaultRole: tt.defaultRole,
+			})
+
+			authURL, err := uc.Begin(context.TODO())
+			assert.Nil(t, err)
+
+			redirect := provider.Authorize(t, authURL, jwt.MapClaims{
+				"sub":    "abc",
+				"email":  "test@test.com",
+				"groups": tt.groups,
+			})
+
+			_, _, err = uc.Complete(context.TODO(), redirect.Query().Get("state"), redirect.Query().Get("code"), "", "")
+			assert.Equal(t, tt.err, err)
+			assert.Equal(t, tt.role, got.role)
+
+			if tt.err != nil {
+				assert.Nil(t, got.identity, "refused users are not provisioned")
+			}
+		})
+	}
+}
+
+func TestSSOUseCase_Complete_InvalidCode(t *testing.T) {
+	uc, _, _ := newSSO(t, config.OIDC{DefaultRole: workspace.RoleMember})
+
+	authURL, err := uc.Begin(context.TODO())
+	assert.Nil(t, err)
+
+	u, err := url.Parse(authURL)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, _, err = uc.Complete(context.TODO(), u.Query().Get("state"), "forged-code", "", "")
+	assert.True(t, errors.Is(err, oidc.ErrExchange))
+}
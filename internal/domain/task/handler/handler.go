@@ -3,8 +3,11 @@ package handler
 import (
 	"database/sql"
 	"encoding/json"
+	"fmt"
 	"log/slog"
 	"net/http"
+	"strings"
+	"time"
 
 	"github.com/henriqueassiss/advanced-golang-api/internal/domain/task"
 	"github.com/henriqueassiss/advanced-golang-api/internal/domain/task/useCase"
@@ -24,6 +27,45 @@ func NewHandler(useCase useCase.ITask, logger *slog.Logger) *ITask {
 	}
 }
 
+// errorStatus maps use case errors to response statuses. Missing tasks keep
+// answering 400, as they always have.
+func errorStatus(err error) int {
+	switch err {
+	case sql.ErrNoRows, errorMsg.ErrInvalidRequestData, errorMsg.ErrNotWorkspaceMember:
+		return http.StatusBadRequest
+	case errorMsg.ErrUnauthorized:
+		return http.StatusUnauthorized
+	case errorMsg.ErrForbidden:
+		return http.StatusForbidden
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+func toSingleTask(t *task.Schema) SingleTask {
+	res := SingleTask{
+		ID:          t.ID,
+		Title:       t.Title,
+		Description: t.Description.Or(""),
+		Status:      t.Status,
+		OwnerID:     t.UserID,
+	}
+
+	if t.CreatedAt.Valid {
+		res.CreatedAt = &t.CreatedAt.Time
+	}
+
+	if t.UpdatedAt.Valid {
+		res.UpdatedAt = &t.UpdatedAt.Time
+	}
+
+	if t.CompletedAt.Valid {
+		res.CompletedAt = &t.CompletedAt.Time
+	}
+
+	return res
+}
+
 func (h *ITask) FindOne(w http.ResponseWriter, r *http.Request) {
 	taskID, err := reqRes.UInt64Param(r, "taskID", false)
 	if err != nil {
@@ -33,28 +75,37 @@ func (h *ITask) FindOne(w http.ResponseWriter, r *http.Request) {
 
 	t, err := h.useCase.FindOne(r.Context(), taskID)
 	if err != nil {
-		if err == sql.ErrNoRows {
-			reqRes.Error(h.logger, w, http.StatusBadRequest, err, taskID)
-		} else {
-			reqRes.Error(h.logger, w, http.StatusInternalServerError, err, taskID)
-		}
-
+		reqRes.Error(h.logger, w, errorStatus(err), err, taskID)
 		return
 	}
 
-	res := SingleTask{
-		ID:          t.ID,
-		Title:       t.Title,
-		Description: t.Description,
+	reqRes.Json(w, http.StatusOK, toSingleTask(t))
+}
+
+func (h *ITask) respondMany(w http.ResponseWriter, ts []task.Schema, err error) {
+	if err != nil {
+		reqRes.Error(h.logger, w, errorStatus(err), err, nil)
+		return
 	}
 
-	if t.UpdatedAt.Valid {
-		res.UpdatedAt = &t.UpdatedAt.Time
+	res := make([]SingleTask, len(ts))
+	for i := range ts {
+		res[i] = toSingleTask(&ts[i])
 	}
 
 	reqRes.Json(w, http.StatusOK, res)
 }
 
+func (h *ITask) FindAssigned(w http.ResponseWriter, r *http.Request) {
+	ts, err := h.useCase.FindAssigned(r.Context())
+	h.respondMany(w, ts, err)
+}
+
+func (h *ITask) FindShared(w http.ResponseWriter, r *http.Request) {
+	ts, err := h.useCase.FindShared(r.Context())
+	h.respondMany(w, ts, err)
+}
+
 func (h *ITask) Create(w http.ResponseWriter, r *http.Request) {
 	var req Create
 	err := json.NewDecoder(r.Body).Decode(&req)
@@ -71,15 +122,47 @@ func (h *ITask) Create(w http.ResponseWriter, r *http.Request) {
 	t := task.Schema{
 		Title:       req.Title,
 		Description: req.Description,
+		Status:      req.Status,
 	}
 
-	err = h.useCase.Create(r.Context(), &t)
+	created, err := h.useCase.Create(r.Context(), &t)
 	if err != nil {
-		reqRes.Error(h.logger, w, http.StatusInternalServerError, err, t)
+		reqRes.Error(h.logger, w, errorStatus(err), err, t)
 		return
 	}
 
-	reqRes.Json(w, http.StatusOK, nil)
+	reqRes.Created(w, fmt.Sprintf("/v1/task/%d", created.ID), toSingleTask(created))
+}
+
+// Import creates the tasks of a JSON array of Create bodies, decoding them
+// as they are stored rather than all at once.
+func (h *ITask) Import(w http.ResponseWriter, r *http.Request) {
+	dec := json.NewDecoder(r.Body)
+	token, err := dec.Token()
+	if err != nil || token != json.Delim('[') {
+		reqRes.Error(h.logger, w, http.StatusBadRequest, err, nil)
+		return
+	}
+
+	imported, err := h.useCase.Import(r.Context(), func() (*task.Schema, error) {
+		if !dec.More() {
+			return nil, nil
+		}
+
+		var req Create
+		err := dec.Decode(&req)
+		if err != nil {
+			return nil, errorMsg.ErrInvalidRequestData
+		}
+
+		return &task.Schema{Title: req.Title, Description: req.Description, Status: req.Status}, nil
+	})
+	if err != nil {
+		reqRes.Error(h.logger, w, errorStatus(err), err, Imported{Imported: imported})
+		return
+	}
+
+	reqRes.Json(w, http.StatusOK, Imported{Imported: imported})
 }
 
 func (h *ITask) Update(w http.ResponseWriter, r *http.Request) {
@@ -100,11 +183,12 @@ func (h *ITask) Update(w http.ResponseWriter, r *http.Request) {
 		ID:          req.ID,
 		Title:       req.Title,
 		Description: req.Description,
+		Status:      req.Status,
 	}
 
 	err = h.useCase.Update(r.Context(), &t)
 	if err != nil {
-		reqRes.Error(h.logger, w, http.StatusInternalServerError, err, t)
+		reqRes.Error(h.logger, w, errorStatus(err), err, t)
 		return
 	}
 
@@ -120,14 +204,196 @@ func (h *ITask) Delete(w http.ResponseWriter, r *http.Request) {
 
 	err = h.useCase.Delete(r.Context(), taskID)
 	if err != nil {
-		if err == sql.ErrNoRows {
-			reqRes.Error(h.logger, w, http.StatusBadRequest, err, taskID)
-		} else {
-			reqRes.Error(h.logger, w, http.StatusInternalServerError, err, taskID)
+		reqRes.Error(h.logger, w, errorStatus(err), err, taskID)
+		return
+	}
+
+	reqRes.NoContent(w)
+}
+
+func (h *ITask) SetAssignees(w http.ResponseWriter, r *http.Request) {
+	taskID, err := reqRes.UInt64Param(r, "taskID", false)
+	if err != nil {
+		reqRes.Error(h.logger, w, http.StatusBadRequest, errorMsg.ErrInvalidRequestData, taskID)
+		return
+	}
+
+	var req SetAssignees
+	err = json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		reqRes.Error(h.logger, w, http.StatusBadRequest, err, req)
+		return
+	}
+
+	err = h.useCase.SetAssignees(r.Context(), taskID, req.UserIDs)
+	if err != nil {
+		reqRes.Error(h.logger, w, errorStatus(err), err, req)
+		return
+	}
+
+	reqRes.Json(w, http.StatusOK, nil)
+}
+
+func (h *ITask) SetLabels(w http.ResponseWriter, r *http.Request) {
+	taskID, err := reqRes.UInt64Param(r, "taskID", false)
+	if err != nil {
+		reqRes.Error(h.logger, w, http.StatusBadRequest, errorMsg.ErrInvalidRequestData, taskID)
+		return
+	}
+
+	var req SetLabels
+	err = json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		reqRes.Error(h.logger, w, http.StatusBadRequest, err, req)
+		return
+	}
+
+	err = h.useCase.SetLabels(r.Context(), taskID, req.Labels)
+	if err != nil {
+		reqRes.Error(h.logger, w, errorStatus(err), err, req)
+		return
+	}
+
+	reqRes.Json(w, http.StatusOK, nil)
+}
+
+// statsQuery reads the dimensions to count by from by, given repeated or
+// comma-separated, and the range from from to to, both dates and inclusive,
+// which defaults to the last 30 days.
+func statsQuery(r *http.Request) (task.StatsQuery, error) {
+	values := r.URL.Query()
+	q := task.StatsQuery{Interval: values.Get("interval")}
+	if q.Interval == "" {
+		q.Interval = "day"
+	}
+
+	for _, by := range values["by"] {
+		for _, dimension := range strings.Split(by, ",") {
+			if dimension = strings.TrimSpace(dimension); dimension != "" {
+				q.By = append(q.By, dimension)
+			}
+		}
+	}
+
+	now := time.Now()
+	q.To = time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.Local)
+	if to := values.Get("to"); to != "" {
+		var err error
+		q.To, err = time.ParseInLocation(time.DateOnly, to, time.Local)
+		if err != nil {
+			return q, errorMsg.ErrInvalidRequestData
+		}
+	}
+
+	q.From = q.To.AddDate(0, 0, -29)
+	if from := values.Get("from"); from != "" {
+		var err error
+		q.From, err = time.ParseInLocation(time.DateOnly, from, time.Local)
+		if err != nil {
+			return q, errorMsg.ErrInvalidRequestData
+		}
+	}
+
+	return q, nil
+}
+
+func (h *ITask) Stats(w http.ResponseWriter, r *http.Request) {
+	q, err := statsQuery(r)
+	if err != nil {
+		reqRes.Error(h.logger, w, http.StatusBadRequest, err, r.URL.RawQuery)
+		return
+	}
+
+	stats, err := h.useCase.Stats(r.Context(), q)
+	if err != nil {
+		reqRes.Error(h.logger, w, errorStatus(err), err, q)
+		return
+	}
+
+	res := Stats{
+		Groups: make([]map[string]any, len(stats.Groups)),
+		Series: make([]StatsPoint, len(stats.Series)),
+	}
+
+	for i, g := range stats.Groups {
+		group := map[string]any{"count": g.Count}
+		for _, by := range q.By {
+			value := g.Period
+			switch by {
+			case task.ByStatus:
+				value = g.Status
+			case task.ByLabel:
+				value = g.Label
+			}
+
+			group[by] = nil
+			if value.Valid {
+				group[by] = value.String
+			}
 		}
 
+		res.Groups[i] = group
+	}
+
+	for i, p := range stats.Series {
+		res.Series[i] = StatsPoint(p)
+	}
+
+	reqRes.Json(w, http.StatusOK, res)
+}
+
+func (h *ITask) Share(w http.ResponseWriter, r *http.Request) {
+	taskID, err := reqRes.UInt64Param(r, "taskID", false)
+	if err != nil {
+		reqRes.Error(h.logger, w, http.StatusBadRequest, errorMsg.ErrInvalidRequestData, taskID)
+		return
+	}
+
+	var req Share
+	err = json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		reqRes.Error(h.logger, w, http.StatusBadRequest, err, req)
+		return
+	}
+
+	if req.UserID == 0 {
+		reqRes.Error(h.logger, w, http.StatusBadRequest, errorMsg.ErrInvalidRequestData, req)
+		return
+	}
+
+	s := task.Share{
+		TaskID:     taskID,
+		UserID:     req.UserID,
+		Permission: req.Permission,
+	}
+
+	err = h.useCase.Share(r.Context(), &s)
+	if err != nil {
+		reqRes.Error(h.logger, w, errorStatus(err), err, s)
 		return
 	}
 
 	reqRes.Json(w, http.StatusOK, nil)
 }
+
+func (h *ITask) Unshare(w http.ResponseWriter, r *http.Request) {
+	taskID, err := reqRes.UInt64Param(r, "taskID", false)
+	if err != nil {
+		reqRes.Error(h.logger, w, http.StatusBadRequest, errorMsg.ErrInvalidRequestData, taskID)
+		return
+	}
+
+	userID, err := reqRes.UInt64Param(r, "userID", false)
+	if err != nil {
+		reqRes.Error(h.logger, w, http.StatusBadRequest, errorMsg.ErrInvalidRequestData, userID)
+		return
+	}
+
+	err = h.useCase.Unshare(r.Context(), taskID, userID)
+	if err != nil {
+		reqRes.Error(h.logger, w, errorStatus(err), err, taskID)
+		return
+	}
+
+	reqRes.NoContent(w)
+}
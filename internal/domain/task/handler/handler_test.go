@@ -13,7 +13,9 @@ import (
 
 	"github.com/henriqueassiss/advanced-golang-api/internal/domain/task"
 	"github.com/henriqueassiss/advanced-golang-api/internal/domain/task/useCase"
+	"github.com/henriqueassiss/advanced-golang-api/internal/utils/errorMsg"
 	"github.com/henriqueassiss/advanced-golang-api/internal/utils/reqRes"
+	"github.com/henriqueassiss/advanced-golang-api/internal/utils/schema"
 
 	"github.com/henriqueassiss/advanced-golang-api/third_party/logger"
 
@@ -53,7 +55,7 @@ func TestTaskHandler_FindOne(t *testing.T) {
 				useCase: &task.Schema{
 					ID:          1,
 					Title:       "Test",
-					Description: "Test",
+					Description: schema.Some("Test"),
 					UpdatedAt: sql.NullTime{
 						Valid: true,
 						Time:  time.Date(2000, 1, 1, 0, 0, 0, 0, time.Local),
@@ -164,6 +166,7 @@ func TestTaskHandler_Create(t *testing.T) {
 
 	type want struct {
 		status   int
+		location string
 		response *reqRes.GenericResponse[any]
 		err      error
 	}
@@ -180,15 +183,25 @@ func TestTaskHandler_Create(t *testing.T) {
 			args: args{
 				req: &Create{
 					Title:       "Test",
-					Description: "Test",
+					Description: schema.Some("Test"),
 				},
 			},
 			want: want{
-				status: http.StatusOK,
+				status:   http.StatusCreated,
+				location: "/v1/task/1",
 				response: &reqRes.GenericResponse[any]{
 					Success: true,
-					Status:  http.StatusOK,
-					Data:    nil,
+					Status:  http.StatusCreated,
+					Data: map[string]any{
+						"id":          float64(1),
+						"title":       "Test",
+						"description": "Test",
+						"status":      "todo",
+						"ownerId":     float64(2),
+						"createdAt":   "2024-01-02T03:04:05Z",
+						"updatedAt":   "2024-01-02T03:04:05Z",
+						"completedAt": nil,
+					},
 				},
 				err: nil,
 			},
@@ -197,7 +210,7 @@ func TestTaskHandler_Create(t *testing.T) {
 			name: "Fail - Invalid title",
 			args: args{
 				req: &Create{
-					Description: "Test",
+					Description: schema.Some("Test"),
 				},
 			},
 			want: want{
@@ -215,7 +228,7 @@ func TestTaskHandler_Create(t *testing.T) {
 			args{
 				req: &Create{
 					Title:       "Test",
-					Description: "Test",
+					Description: schema.Some("Test"),
 				},
 			},
 			want{
@@ -241,8 +254,19 @@ func TestTaskHandler_Create(t *testing.T) {
 			w := httptest.NewRecorder()
 
 			uc := &useCase.TaskMock{
-				CreateFunc: func(ctx context.Context, t *task.Schema) error {
-					return tt.want.err
+				CreateFunc: func(ctx context.Context, t *task.Schema) (*task.Schema, error) {
+					if tt.want.err != nil {
+						return nil, tt.want.err
+					}
+
+					ownerID := uint64(2)
+					created := *t
+					created.ID, created.UserID = 1, &ownerID
+					created.Status = task.StatusTodo
+					created.CreatedAt = sql.NullTime{Time: time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC), Valid: true}
+					created.UpdatedAt = created.CreatedAt
+
+					return &created, nil
 				},
 			}
 
@@ -251,6 +275,7 @@ func TestTaskHandler_Create(t *testing.T) {
 			h.Create(w, r)
 
 			assert.Equal(t, tt.status, w.Code)
+			assert.Equal(t, tt.location, w.Header().Get("Location"))
 
 			var got reqRes.GenericResponse[any]
 			err = json.NewDecoder(w.Body).Decode(&got)
@@ -289,7 +314,7 @@ func TestTaskHandler_Update(t *testing.T) {
 				req: &Update{
 					ID:          1,
 					Title:       "Test",
-					Description: "Test",
+					Description: schema.Some("Test"),
 				},
 			},
 			want: want{
@@ -307,7 +332,7 @@ func TestTaskHandler_Update(t *testing.T) {
 			args: args{
 				req: &Update{
 					Title:       "Test",
-					Description: "Test",
+					Description: schema.Some("Test"),
 				},
 			},
 			want: want{
@@ -325,7 +350,7 @@ func TestTaskHandler_Update(t *testing.T) {
 			args: args{
 				req: &Update{
 					ID:          1,
-					Description: "Test",
+					Description: schema.Some("Test"),
 				},
 			},
 			want: want{
@@ -344,7 +369,7 @@ func TestTaskHandler_Update(t *testing.T) {
 				req: &Update{
 					ID:          1,
 					Title:       "Test",
-					Description: "Test",
+					Description: schema.Some("Test"),
 				},
 			},
 			want{
@@ -418,13 +443,8 @@ func TestTaskHandler_Delete(t *testing.T) {
 				taskID: "1",
 			},
 			want: want{
-				status: http.StatusOK,
-				response: &reqRes.GenericResponse[any]{
-					Success: true,
-					Status:  http.StatusOK,
-					Data:    nil,
-				},
-				err: nil,
+				status: http.StatusNoContent,
+				err:    nil,
 			},
 		},
 		{
@@ -495,6 +515,10 @@ func TestTaskHandler_Delete(t *testing.T) {
 			h.Delete(w, r)
 
 			assert.Equal(t, tt.status, w.Code)
+			if tt.status == http.StatusNoContent {
+				assert.Empty(t, w.Body.String())
+				return
+			}
 
 			var got reqRes.GenericResponse[any]
 			err := json.NewDecoder(w.Body).Decode(&got)
@@ -506,3 +530,192 @@ func TestTaskHandler_Delete(t *testing.T) {
 		})
 	}
 }
+
+func TestTaskHandler_Share(t *testing.T) {
+	logger := logger.New()
+
+	type want struct {
+		status int
+		err    error
+	}
+
+	type test struct {
+		name string
+		body string
+		want
+	}
+
+	tests := []test{
+		{
+			name: "Success",
+			body: `{"userId":2,"permission":"read"}`,
+			want: want{status: http.StatusOK},
+		},
+		{
+			name: "Fail - User id not supplied",
+			body: `{"permission":"read"}`,
+			want: want{status: http.StatusBadRequest},
+		},
+		{
+			name: "Fail - Not the owner",
+			body: `{"userId":2,"permission":"write"}`,
+			want: want{status: http.StatusForbidden, err: errorMsg.ErrForbidden},
+		},
+		{
+			name: "Fail - User outside the workspace",
+			body: `{"userId":9,"permission":"write"}`,
+			want: want{status: http.StatusBadRequest, err: errorMsg.ErrNotWorkspaceMember},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodPut, "/v1/task/1/shares", bytes.NewBufferString(tt.body))
+			w := httptest.NewRecorder()
+
+			var got *task.Share
+			uc := &useCase.TaskMock{
+				ShareFunc: func(ctx context.Context, s *task.Share) error {
+					got = s
+					return tt.want.err
+				},
+			}
+
+			router := chi.NewRouter()
+			RegisterHTTPEndPoints(uc, logger, router)
+			router.ServeHTTP(w, r)
+
+			assert.Equal(t, tt.status, w.Code)
+			if tt.status == http.StatusOK {
+				assert.Equal(t, &task.Share{TaskID: 1, UserID: 2, Permission: task.PermissionRead}, got)
+			}
+		})
+	}
+}
+
+func TestTaskHandler_Unshare(t *testing.T) {
+	logger := logger.New()
+
+	uc := &useCase.TaskMock{
+		UnshareFunc: func(ctx context.Context, taskID, userID uint64) error {
+			assert.Equal(t, uint64(1), taskID)
+			assert.Equal(t, uint64(2), userID)
+			return nil
+		},
+	}
+
+	router := chi.NewRouter()
+	RegisterHTTPEndPoints(uc, logger, router)
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodDelete, "/v1/task/1/shares/2", nil))
+	assert.Equal(t, http.StatusNoContent, w.Code)
+	assert.Empty(t, w.Body.String())
+}
+
+func TestTaskHandler_FindOne_Conditional(t *testing.T) {
+	logger := logger.New()
+	updatedAt := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	uc := &useCase.TaskMock{
+		FindOneFunc: func(ctx context.Context, taskID uint64) (*task.Schema, error) {
+			return &task.Schema{ID: taskID, Title: "Test", UpdatedAt: sql.NullTime{Time: updatedAt, Valid: true}}, nil
+		},
+	}
+
+	router := chi.NewRouter()
+	RegisterHTTPEndPoints(uc, logger, router)
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/v1/task/1", nil))
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, cacheControl, w.Header().Get("Cache-Control"))
+	assert.Equal(t, "Tue, 02 Jan 2024 03:04:05 GMT", w.Header().Get("Last-Modified"))
+
+	etag := w.Header().Get("ETag")
+	assert.NotEmpty(t, etag)
+
+	r := httptest.NewRequest(http.MethodGet, "/v1/task/1", nil)
+	r.Header.Set("If-None-Match", etag)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, r)
+	assert.Equal(t, http.StatusNotModified, w.Code)
+	assert.Equal(t, 0, w.Body.Len())
+
+	r = httptest.NewRequest(http.MethodGet, "/v1/task/1", nil)
+	r.Header.Set("If-Modified-Since", "Tue, 02 Jan 2024 03:04:05 GMT")
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, r)
+	assert.Equal(t, http.StatusNotModified, w.Code)
+}
+
+func TestTaskHandler_Stats(t *testing.T) {
+	logger := logger.New()
+
+	uc := &useCase.TaskMock{
+		StatsFunc: func(ctx context.Context, q task.StatsQuery) (*task.Stats, error) {
+			assert.Equal(t, task.StatsQuery{
+				By:       []string{task.ByStatus, task.ByLabel, task.ByCreatedWeek},
+				From:     time.Date(2026, 1, 1, 0, 0, 0, 0, time.Local),
+				To:       time.Date(2026, 1, 31, 0, 0, 0, 0, time.Local),
+				Interval: "week",
+			}, q)
+
+			return &task.Stats{
+				Groups: []task.Group{{
+					Status: sql.NullString{String: task.StatusDone, Valid: true},
+					Period: sql.NullString{String: "2026-01-05", Valid: true},
+					Count:  3,
+				}},
+				Series: []task.Point{{Period: "2026-01-05", Created: 3, Completed: 1}},
+			}, nil
+		},
+	}
+
+	router := chi.NewRouter()
+	RegisterHTTPEndPoints(uc, logger, router)
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/v1/task/stats?by=status,label&by=created_week&from=2026-01-01&to=2026-01-31&interval=week", nil))
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `{"status":200,"success":true,"data":{
+		"groups":[{"status":"done","label":null,"created_week":"2026-01-05","count":3}],
+		"series":[{"period":"2026-01-05","created":3,"completed":1}]
+	}}`, w.Body.String())
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/v1/task/stats?from=yesterday", nil))
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestTaskHandler_Import(t *testing.T) {
+	logger := logger.New()
+
+	uc := &useCase.TaskMock{
+		ImportFunc: func(ctx context.Context, next func() (*task.Schema, error)) (int64, error) {
+			var imported int64
+			for {
+				t, err := next()
+				if t == nil || err != nil {
+					return imported, err
+				}
+
+				imported++
+			}
+		},
+	}
+
+	router := chi.NewRouter()
+	RegisterHTTPEndPoints(uc, logger, router)
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/v1/task/import", bytes.NewBufferString(`[{"title":"First"},{"title":"Second","status":"done"}]`)))
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `{"status":200,"success":true,"data":{"imported":2}}`, w.Body.String())
+
+	for _, body := range []string{`{"title":"First"}`, `[{"title":"First"},{"title":`} {
+		w = httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/v1/task/import", bytes.NewBufferString(body)))
+		assert.Equal(t, http.StatusBadRequest, w.Code, body)
+	}
+}
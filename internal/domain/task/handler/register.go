@@ -2,18 +2,33 @@ package handler
 
 import (
 	"log/slog"
+	"net/http"
 
 	"github.com/go-chi/chi/v5"
 	"github.com/henriqueassiss/advanced-golang-api/internal/domain/task/useCase"
+	"github.com/henriqueassiss/advanced-golang-api/internal/middleware"
 )
 
-func RegisterHTTPEndPoints(u useCase.ITask, logger *slog.Logger, router *chi.Mux) *ITask {
+// Tasks are private to their readers and change at any time, so clients
+// revalidate on every use; unchanged tasks then cost a 304.
+const cacheControl = "private, no-cache"
+
+func RegisterHTTPEndPoints(u useCase.ITask, logger *slog.Logger, router *chi.Mux, middlewares ...func(http.Handler) http.Handler) *ITask {
 	handler := NewHandler(u, logger)
 	router.Route("/v1/task", func(router chi.Router) {
-		router.Get("/{taskID}", handler.FindOne)
+		router.Use(middlewares...)
+		router.With(middleware.Conditional(cacheControl)).Get("/assigned", handler.FindAssigned)
+		router.With(middleware.Conditional(cacheControl)).Get("/shared", handler.FindShared)
+		router.Get("/stats", handler.Stats)
+		router.With(middleware.Conditional(cacheControl)).Get("/{taskID}", handler.FindOne)
 		router.Post("/", handler.Create)
+		router.Post("/import", handler.Import)
 		router.Put("/", handler.Update)
 		router.Delete("/{taskID}", handler.Delete)
+		router.Put("/{taskID}/assignees", handler.SetAssignees)
+		router.Put("/{taskID}/labels", handler.SetLabels)
+		router.Put("/{taskID}/shares", handler.Share)
+		router.Delete("/{taskID}/shares/{userID}", handler.Unshare)
 	})
 	return handler
 }
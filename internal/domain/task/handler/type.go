@@ -1,21 +1,72 @@
 package handler
 
-import "time"
+import (
+	"time"
+
+	"github.com/henriqueassiss/advanced-golang-api/internal/utils/schema"
+)
 
 type SingleTask struct {
 	ID          uint64     `json:"id"`
 	Title       string     `json:"title"`
 	Description string     `json:"description"`
+	Status      string     `json:"status"`
+	OwnerID     *uint64    `json:"ownerId"`
+	CreatedAt   *time.Time `json:"createdAt"`
 	UpdatedAt   *time.Time `json:"updatedAt"`
+	CompletedAt *time.Time `json:"completedAt"`
+}
+
+func (t SingleTask) LastModified() time.Time {
+	if t.UpdatedAt == nil {
+		return time.Time{}
+	}
+
+	return *t.UpdatedAt
 }
 
+// Create and Update take the status of the task, todo by default.
 type Create struct {
-	Title       string `json:"title"`
-	Description string `json:"description"`
+	Title       string                  `json:"title"`
+	Description schema.Optional[string] `json:"description"`
+	Status      string                  `json:"status"`
 }
 
+type Imported struct {
+	Imported int64 `json:"imported"`
+}
+
+// Update leaves the description alone when it is left out and clears it when
+// it is null.
 type Update struct {
-	ID          uint64 `json:"id"`
-	Title       string `json:"title"`
-	Description string `json:"description"`
+	ID          uint64                  `json:"id"`
+	Title       string                  `json:"title"`
+	Description schema.Optional[string] `json:"description"`
+	Status      string                  `json:"status"`
+}
+
+type SetAssignees struct {
+	UserIDs []uint64 `json:"userIds"`
+}
+
+type SetLabels struct {
+	Labels []string `json:"labels"`
+}
+
+// Stats holds each group as an object with the value of every dimension
+// asked for, null for tasks without one, and their count.
+type Stats struct {
+	Groups []map[string]any `json:"groups"`
+	Series []StatsPoint     `json:"series"`
+}
+
+type StatsPoint struct {
+	Period    string `json:"period"`
+	Created   uint64 `json:"created"`
+	Completed uint64 `json:"completed"`
+}
+
+type Share struct {
+	UserID     uint64 `json:"userId"`
+	Permission string `json:"permission"`
 }
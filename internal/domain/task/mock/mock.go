@@ -1,13 +1,21 @@
 package mock
 
 import (
+	"context"
+
 	"github.com/brianvoe/gofakeit/v6"
 	"github.com/henriqueassiss/advanced-golang-api/internal/domain/task"
 	"github.com/henriqueassiss/advanced-golang-api/internal/domain/task/repository"
 	"github.com/henriqueassiss/advanced-golang-api/internal/utils/errorMsg"
+	"github.com/henriqueassiss/advanced-golang-api/internal/utils/persistence"
+	"github.com/henriqueassiss/advanced-golang-api/internal/utils/schema"
 	"github.com/jmoiron/sqlx"
 )
 
+// DefaultWorkspace is created by the workspaces migration so seeded tasks
+// always belong to a tenant.
+const DefaultWorkspace = `SELECT id FROM workspaces WHERE slug = 'default'`
+
 func verifyMockAuthenticity(db *sqlx.DB) error {
 	var count uint64
 	rows, err := db.Queryx(repository.Count)
@@ -35,20 +43,27 @@ func Mock(db *sqlx.DB) error {
 		return err
 	}
 
-	var ts []task.Schema
+	var workspaceID uint64
+	err = db.Get(&workspaceID, DefaultWorkspace)
+	if err != nil {
+		return err
+	}
+
+	var rows [][]any
 	for i := 0; i < 10; i++ {
 		var t task.Schema
 
 		t.Title = gofakeit.Sentence(3)
-		t.Description = gofakeit.SentenceSimple()
+		t.Description = schema.Some(gofakeit.SentenceSimple())
+		t.WorkspaceID = workspaceID
 
-		ts = append(ts, t)
+		rows = append(rows, []any{t.Title, t.Description, t.WorkspaceID})
 	}
 
-	_, err = db.NamedExec(`INSERT INTO tasks (title,
-	description)
-	VALUES (:title,
-	:description)`, ts)
+	_, err = persistence.Load(context.Background(), db, persistence.Bulk{
+		Table:   "tasks",
+		Columns: []string{"title", "description", "workspace_id"},
+	}, persistence.Rows(rows))
 
 	return err
 }
@@ -35,7 +35,12 @@ func TestMock(t *testing.T) {
 				rows := mock.NewRows([]string{"count"}).AddRow(0)
 				mock.ExpectQuery("SELECT").WillReturnRows(rows)
 
-				mock.ExpectExec("INSERT INTO tasks").WillReturnResult(sqlxmock.NewResult(0, 5))
+				workspaceRows := mock.NewRows([]string{"id"}).AddRow(1)
+				mock.ExpectQuery("SELECT id FROM workspaces").WillReturnRows(workspaceRows)
+
+				mock.ExpectBegin()
+				mock.ExpectExec(`INSERT INTO "tasks" \("title", "description", "workspace_id"\) VALUES \(\$1, \$2, \$3\), (.+), \(\$28, \$29, \$30\)$`).WillReturnResult(sqlxmock.NewResult(0, 10))
+				mock.ExpectCommit()
 			},
 		},
 		{
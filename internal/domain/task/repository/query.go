@@ -1,13 +1,99 @@
 package repository
 
+import (
+	"github.com/henriqueassiss/advanced-golang-api/internal/domain/task"
+	"github.com/henriqueassiss/advanced-golang-api/internal/utils/schema"
+)
+
 var (
 	Count = `SELECT count(*) FROM tasks`
 
-	Select = `SELECT ? FROM tasks t`
+	// SelectPermission is bound to the user ID three times, then to the task
+	// and workspace IDs.
+	SelectPermission = `SELECT CASE
+		WHEN t.user_id IS NULL OR t.user_id = ? THEN 'owner'
+		WHEN EXISTS (SELECT 1 FROM task_assignees a WHERE a.task_id = t.id AND a.user_id = ?) THEN 'write'
+		ELSE COALESCE((SELECT s.permission FROM task_shares s WHERE s.task_id = t.id AND s.user_id = ?), '')
+	END FROM tasks t WHERE t.id = ? AND t.workspace_id = ?`
+
+	DeleteAssignees = `DELETE FROM task_assignees
+	WHERE task_id IN (SELECT t.id FROM tasks t WHERE t.id = ? AND t.workspace_id = ?)`
+
+	// InsertAssignees is expanded by sqlx.In for the user IDs.
+	InsertAssignees = `INSERT INTO task_assignees (task_id, user_id)
+	SELECT t.id, m.user_id FROM tasks t
+	JOIN workspace_members m ON m.workspace_id = t.workspace_id
+	WHERE t.id = ? AND t.workspace_id = ? AND m.user_id IN (?)`
+
+	// InsertShare is bound to the permission first. Its WHERE tells SQLite
+	// that the upsert clause appended to it is not the ON of the join.
+	InsertShare = `INSERT INTO task_shares (task_id, user_id, permission)
+	SELECT t.id, m.user_id, ? FROM tasks t
+	JOIN workspace_members m ON m.workspace_id = t.workspace_id
+	WHERE t.id = ? AND t.workspace_id = ? AND m.user_id = ?`
+
+	DeleteShare = `DELETE FROM task_shares
+	WHERE task_id IN (SELECT t.id FROM tasks t WHERE t.id = ? AND t.workspace_id = ?) AND user_id = ?`
+
+	DeleteLabels = `DELETE FROM task_labels
+	WHERE task_id IN (SELECT t.id FROM tasks t WHERE t.id = ? AND t.workspace_id = ?)`
+
+	// InsertLabel is bound to the label first.
+	InsertLabel = `INSERT INTO task_labels (task_id, label)
+	SELECT t.id, ? FROM tasks t WHERE t.id = ? AND t.workspace_id = ?`
 
-	InsertInto = `INSERT INTO tasks (?) VALUES (?) RETURNING id`
+	// UpdateCompletedAt stamps when a task got done, once, and clears it when
+	// the task is no longer done.
+	UpdateCompletedAt = `UPDATE tasks
+	SET completed_at = CASE WHEN status = 'done' THEN COALESCE(completed_at, CURRENT_TIMESTAMP) END
+	WHERE id = ? AND workspace_id = ?`
 
-	Update = `UPDATE tasks SET ?, updated_at = CURRENT_TIMESTAMP WHERE id = $1`
+	// JoinLabels joins the labels of tasks counted by label. Tasks without
+	// labels are counted under none.
+	JoinLabels = `LEFT JOIN task_labels l ON l.task_id = t.id`
+)
+
+// Dimension is what counting tasks by a dimension groups them by: a column,
+// truncated to the day or week of Unit for periods.
+type Dimension struct {
+	Column string
+	Unit   string
+}
+
+// Dimensions are the only ones tasks can be counted by, so no dimension
+// asked for is ever written into a query.
+var Dimensions = map[string]Dimension{
+	task.ByStatus:        {Column: "t.status"},
+	task.ByLabel:         {Column: "l.label"},
+	task.ByCreatedDay:    {Column: "t.created_at", Unit: "day"},
+	task.ByCreatedWeek:   {Column: "t.created_at", Unit: "week"},
+	task.ByUpdatedDay:    {Column: "t.updated_at", Unit: "day"},
+	task.ByUpdatedWeek:   {Column: "t.updated_at", Unit: "week"},
+	task.ByCompletedDay:  {Column: "t.completed_at", Unit: "day"},
+	task.ByCompletedWeek: {Column: "t.completed_at", Unit: "week"},
+}
+
+// Where clauses restricting tasks to the ones a user can see. Readable is
+// bound to ReadableArgs; AssignedTo and SharedWith to the user ID alone.
+var (
+	Readable = `(t.user_id IS NULL OR t.user_id = ?
+	OR EXISTS (SELECT 1 FROM task_assignees a WHERE a.task_id = t.id AND a.user_id = ?)
+	OR EXISTS (SELECT 1 FROM task_shares s WHERE s.task_id = t.id AND s.user_id = ?))`
+
+	AssignedTo = `EXISTS (SELECT 1 FROM task_assignees a WHERE a.task_id = t.id AND a.user_id = ?)`
 
-	Delete = `DELETE FROM tasks WHERE id = $1`
+	SharedWith = `EXISTS (SELECT 1 FROM task_shares s WHERE s.task_id = t.id AND s.user_id = ?)`
 )
+
+// ReadableArgs binds Readable to userID.
+func ReadableArgs(userID uint64) []any {
+	return []any{userID, userID, userID}
+}
+
+// ReadableTask restricts tasks to taskID when userID can see it.
+func ReadableTask(taskID, userID uint64) schema.QueryParams {
+	return schema.QueryParams{
+		Where: "t.id = ? AND " + Readable,
+		Args:  append([]any{taskID}, ReadableArgs(userID)...),
+	}
+}
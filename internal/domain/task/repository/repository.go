@@ -2,83 +2,246 @@ package repository
 
 import (
 	"context"
+	"database/sql"
 	"strings"
 
 	"github.com/henriqueassiss/advanced-golang-api/internal/domain/task"
+	"github.com/henriqueassiss/advanced-golang-api/internal/utils/errorMsg"
+	"github.com/henriqueassiss/advanced-golang-api/internal/utils/persistence"
 	"github.com/henriqueassiss/advanced-golang-api/internal/utils/schema"
+	"github.com/henriqueassiss/advanced-golang-api/internal/utils/tenant"
+	"github.com/henriqueassiss/advanced-golang-api/third_party/database"
 
 	"github.com/jmoiron/sqlx"
-	_ "github.com/lib/pq"
 )
 
 type ITask interface {
 	FindOne(ctx context.Context, params schema.QueryParams) (*task.Schema, error)
 	FindMany(ctx context.Context, params schema.QueryParams) ([]task.Schema, error)
-	Create(ctx context.Context, t *task.Schema) error
+	Create(ctx context.Context, t *task.Schema) (*task.Schema, error)
 	Update(ctx context.Context, t *task.Schema) error
 	Delete(ctx context.Context, taskID uint64) error
+	FindPermission(ctx context.Context, taskID, userID uint64) (string, error)
+	SetAssignees(ctx context.Context, taskID uint64, userIDs []uint64) error
+	SaveShare(ctx context.Context, s *task.Share) error
+	DeleteShare(ctx context.Context, taskID, userID uint64) error
+	SetLabels(ctx context.Context, taskID uint64, labels []string) error
+	CountBy(ctx context.Context, by []string, params schema.QueryParams) ([]task.Group, error)
+	Import(ctx context.Context, next func() (*task.Schema, error)) (int64, error)
 }
 
+// Every Task method is scoped to the workspace carried by ctx and fails with
+// ErrTenantRequired when there is none.
 type Task struct {
-	db *sqlx.DB
+	*persistence.Repository[task.Schema]
+	db      *sqlx.DB
+	dialect database.Dialect
+	tx      *persistence.TxManager
 }
 
 func New(db *sqlx.DB) *Task {
 	return &Task{
-		db: db,
+		Repository: persistence.New[task.Schema](db, persistence.Table{
+			Name:      "tasks",
+			Alias:     "t",
+			Tenant:    "workspace_id",
+			Touched:   "updated_at",
+			Immutable: []string{"user_id"},
+		}),
+		db:      db,
+		dialect: database.DialectOf(db),
+		tx:      persistence.NewTxManager(db, sql.LevelDefault, 0),
 	}
 }
 
-func (r *Task) FindOne(ctx context.Context, params schema.QueryParams) (*task.Schema, error) {
-	if params.Select == "" {
-		params.Select = "t.*"
+// FindPermission returns the strongest task.Permission userID holds on the
+// task, or an empty string when the task is not visible to them.
+func (r *Task) FindPermission(ctx context.Context, taskID, userID uint64) (string, error) {
+	workspaceID, ok := tenant.FromContext(ctx)
+	if !ok {
+		return "", errorMsg.ErrTenantRequired
 	}
 
-	query := schema.PrepareFindQuery(Select, params)
+	var permission string
+	err := persistence.Conn(ctx, r.db).GetContext(ctx, &permission, r.dialect.Rebind(SelectPermission), userID, userID, userID, taskID, workspaceID)
 
-	var t task.Schema
-	err := r.db.GetContext(ctx, &t, query)
+	return permission, err
+}
+
+func (r *Task) SetAssignees(ctx context.Context, taskID uint64, userIDs []uint64) error {
+	workspaceID, ok := tenant.FromContext(ctx)
+	if !ok {
+		return errorMsg.ErrTenantRequired
+	}
 
-	return &t, err
+	return r.tx.Run(ctx, func(ctx context.Context) error {
+		tx := persistence.Conn(ctx, r.db)
+
+		_, err := tx.ExecContext(ctx, r.dialect.Rebind(DeleteAssignees), taskID, workspaceID)
+		if err != nil {
+			return err
+		}
+
+		if len(userIDs) != 0 {
+			query, args, err := sqlx.In(InsertAssignees, taskID, workspaceID, userIDs)
+			if err != nil {
+				return err
+			}
+
+			result, err := tx.ExecContext(ctx, r.dialect.Rebind(query), args...)
+			if err != nil {
+				return err
+			}
+
+			affected, err := result.RowsAffected()
+			if err != nil {
+				return err
+			}
+
+			if affected != int64(len(userIDs)) {
+				return errorMsg.ErrNotWorkspaceMember
+			}
+		}
+
+		return nil
+	})
 }
 
-func (r *Task) FindMany(ctx context.Context, params schema.QueryParams) ([]task.Schema, error) {
-	if params.Select == "" {
-		params.Select = "t.*"
+// Update also stamps when the task got done, or clears it, when its status
+// is set.
+func (r *Task) Update(ctx context.Context, t *task.Schema) error {
+	if t.Status == "" {
+		return r.Repository.Update(ctx, t)
 	}
 
-	query := schema.PrepareFindQuery(Select, params)
+	workspaceID, ok := tenant.FromContext(ctx)
+	if !ok {
+		return errorMsg.ErrTenantRequired
+	}
+
+	return r.tx.Run(ctx, func(ctx context.Context) error {
+		err := r.Repository.Update(ctx, t)
+		if err != nil {
+			return err
+		}
 
-	var ts []task.Schema
-	err := r.db.SelectContext(ctx, &ts, query)
+		_, err = persistence.Conn(ctx, r.db).ExecContext(ctx, r.dialect.Rebind(UpdateCompletedAt), t.ID, workspaceID)
 
-	return ts, err
+		return err
+	})
 }
 
-func (r *Task) Create(ctx context.Context, t *task.Schema) error {
-	fields, values := schema.ParseFieldsToInsertQuery(t)
+func (r *Task) SetLabels(ctx context.Context, taskID uint64, labels []string) error {
+	workspaceID, ok := tenant.FromContext(ctx)
+	if !ok {
+		return errorMsg.ErrTenantRequired
+	}
 
-	query := strings.Replace(InsertInto, "?", fields, 1)
+	return r.tx.Run(ctx, func(ctx context.Context) error {
+		tx := persistence.Conn(ctx, r.db)
 
-	query = strings.Replace(query, "?", values, 1)
+		_, err := tx.ExecContext(ctx, r.dialect.Rebind(DeleteLabels), taskID, workspaceID)
+		if err != nil {
+			return err
+		}
 
-	_, err := r.db.ExecContext(ctx, query)
+		for _, label := range labels {
+			_, err = tx.ExecContext(ctx, r.dialect.Rebind(InsertLabel), label, taskID, workspaceID)
+			if err != nil {
+				return err
+			}
+		}
 
-	return err
+		return nil
+	})
 }
 
-func (r *Task) Update(ctx context.Context, t *task.Schema) error {
-	fields := schema.ParseFieldsToUpdateQuery(t, "id", "task_colors", "task_infos")
+// CountBy counts the tasks matching params grouped by the dimensions by, in
+// their order, failing with ErrInvalidRequestData for unknown ones. Periods
+// are returned as Period.
+func (r *Task) CountBy(ctx context.Context, by []string, params schema.QueryParams) ([]task.Group, error) {
+	sel := make([]string, 0, len(by)+1)
+	group := make([]string, 0, len(by))
+	for _, name := range by {
+		d, ok := Dimensions[name]
+		if !ok {
+			return nil, errorMsg.ErrInvalidRequestData
+		}
+
+		expr, alias := d.Column, name
+		switch {
+		case d.Unit != "":
+			expr, alias = r.dialect.DateTrunc(d.Unit, d.Column), "period"
+		case name == task.ByLabel:
+			params.Join = append(params.Join, JoinLabels)
+		}
+
+		sel = append(sel, expr+" AS "+alias)
+		group = append(group, expr)
+	}
+
+	params.Select = strings.Join(append(sel, "count(*) AS count"), ", ")
+	params.GroupBy = strings.Join(group, ", ")
+	params.OrderBy = params.GroupBy
+
+	var groups []task.Group
+	err := r.Select(ctx, &groups, params)
+
+	return groups, err
+}
 
-	query := strings.Replace(Update, "?", fields, 1)
+func (r *Task) SaveShare(ctx context.Context, s *task.Share) error {
+	workspaceID, ok := tenant.FromContext(ctx)
+	if !ok {
+		return errorMsg.ErrTenantRequired
+	}
+
+	query := InsertShare + " " + r.dialect.Upsert([]string{"task_id", "user_id"}, []string{"permission"})
 
-	_, err := r.db.ExecContext(ctx, query, t.ID)
+	result, err := persistence.Conn(ctx, r.db).ExecContext(ctx, r.dialect.Rebind(query), s.Permission, s.TaskID, workspaceID, s.UserID)
+	if err != nil {
+		return err
+	}
+
+	affected, err := result.RowsAffected()
+	if err == nil && affected == 0 {
+		err = errorMsg.ErrNotWorkspaceMember
+	}
 
 	return err
 }
 
-func (r *Task) Delete(ctx context.Context, taskID uint64) error {
-	_, err := r.db.ExecContext(ctx, Delete, taskID)
+func (r *Task) DeleteShare(ctx context.Context, taskID, userID uint64) error {
+	workspaceID, ok := tenant.FromContext(ctx)
+	if !ok {
+		return errorMsg.ErrTenantRequired
+	}
+
+	_, err := persistence.Conn(ctx, r.db).ExecContext(ctx, r.dialect.Rebind(DeleteShare), taskID, workspaceID, userID)
 
 	return err
 }
+
+// Import loads the tasks next yields, until it yields nil, with
+// persistence.Load and returns how many it wrote. Chunks commit on their
+// own, so the tasks written before a failure stay.
+func (r *Task) Import(ctx context.Context, next func() (*task.Schema, error)) (int64, error) {
+	workspaceID, ok := tenant.FromContext(ctx)
+	if !ok {
+		return 0, errorMsg.ErrTenantRequired
+	}
+
+	b := persistence.Bulk{
+		Table:   "tasks",
+		Columns: []string{"title", "description", "status", "workspace_id", "user_id", "completed_at"},
+	}
+
+	return persistence.Load(ctx, r.db, b, func() ([]any, error) {
+		t, err := next()
+		if t == nil || err != nil {
+			return nil, err
+		}
+
+		return []any{t.Title, t.Description, t.Status, workspaceID, t.UserID, t.CompletedAt}, nil
+	})
+}
@@ -6,7 +6,9 @@ import (
 	"testing"
 
 	"github.com/henriqueassiss/advanced-golang-api/internal/domain/task"
+	"github.com/henriqueassiss/advanced-golang-api/internal/utils/errorMsg"
 	"github.com/henriqueassiss/advanced-golang-api/internal/utils/schema"
+	"github.com/henriqueassiss/advanced-golang-api/internal/utils/tenant"
 	"github.com/henriqueassiss/advanced-golang-api/third_party/database"
 	"github.com/stretchr/testify/assert"
 
@@ -39,7 +41,7 @@ func TestTaskRepository_FindOne(t *testing.T) {
 		{
 			name: "Success - No params",
 			args: args{
-				ctx:    context.TODO(),
+				ctx:    tenant.NewContext(context.TODO(), 1),
 				params: schema.QueryParams{},
 			},
 			beforeTest: func() {
@@ -62,7 +64,7 @@ func TestTaskRepository_FindOne(t *testing.T) {
 				t: &task.Schema{
 					ID:          1,
 					Title:       "Test",
-					Description: "Test",
+					Description: schema.Some("Test"),
 					UpdatedAt: sql.NullTime{
 						Valid: true,
 					},
@@ -72,7 +74,7 @@ func TestTaskRepository_FindOne(t *testing.T) {
 		{
 			name: "Success - No params",
 			args: args{
-				ctx: context.TODO(),
+				ctx: tenant.NewContext(context.TODO(), 1),
 				params: schema.QueryParams{
 					Select: "t.id",
 				},
@@ -111,6 +113,7 @@ func TestTaskRepository_Create(t *testing.T) {
 	}
 
 	type want struct {
+		id  uint64
 		err error
 	}
 
@@ -125,14 +128,17 @@ func TestTaskRepository_Create(t *testing.T) {
 		{
 			name: "Success",
 			args: args{
-				ctx: context.TODO(),
+				ctx: tenant.NewContext(context.TODO(), 1),
 				t: &task.Schema{
 					Title:       "Test",
-					Description: "Test",
+					Description: schema.Some("Test"),
 				},
 			},
 			beforeTest: func() {
-				mock.ExpectExec("INSERT INTO tasks").WillReturnResult(sqlxmock.NewResult(0, 1))
+				mock.ExpectQuery("INSERT INTO tasks").WillReturnRows(mock.NewRows([]string{"id"}).AddRow(1))
+			},
+			want: want{
+				id: 1,
 			},
 		},
 	}
@@ -141,8 +147,10 @@ func TestTaskRepository_Create(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			tt.beforeTest()
 
-			err := r.Create(tt.args.ctx, tt.args.t)
+			created, err := r.Create(tt.args.ctx, tt.args.t)
 			assert.Equal(t, tt.want.err, err)
+			assert.Equal(t, tt.want.id, created.ID)
+			assert.Equal(t, tt.want.id, tt.args.t.ID)
 		})
 	}
 }
@@ -172,17 +180,48 @@ func TestTaskRepository_Update(t *testing.T) {
 		{
 			name: "Success",
 			args: args{
-				ctx: context.TODO(),
+				ctx: tenant.NewContext(context.TODO(), 1),
 				t: &task.Schema{
 					ID:          1,
 					Title:       "Test",
-					Description: "Test",
+					Description: schema.Some("Test"),
 				},
 			},
 			beforeTest: func() {
 				mock.ExpectExec("UPDATE tasks").WillReturnResult(sqlxmock.NewResult(0, 1))
 			},
 		},
+		{
+			name: "Success - Null clears the description",
+			args: args{
+				ctx: tenant.NewContext(context.TODO(), 1),
+				t: &task.Schema{
+					ID:          1,
+					Title:       "Test",
+					Description: schema.Null[string](),
+				},
+			},
+			beforeTest: func() {
+				mock.ExpectExec(`UPDATE tasks SET title = \$1, description = \$2,`).
+					WithArgs("Test", nil, 1, 1).
+					WillReturnResult(sqlxmock.NewResult(0, 1))
+			},
+		},
+		{
+			name: "Success - Unset leaves the description alone",
+			args: args{
+				ctx: tenant.NewContext(context.TODO(), 1),
+				t: &task.Schema{
+					ID:    1,
+					Title: "Test",
+				},
+			},
+			beforeTest: func() {
+				mock.ExpectExec(`UPDATE tasks SET title = \$1, updated_at`).
+					WithArgs("Test", 1, 1).
+					WillReturnResult(sqlxmock.NewResult(0, 1))
+			},
+		},
 	}
 
 	for _, tt := range tests {
@@ -220,7 +259,7 @@ func TestTaskRepository_Delete(t *testing.T) {
 		{
 			name: "Success",
 			args: args{
-				ctx:    context.TODO(),
+				ctx:    tenant.NewContext(context.TODO(), 1),
 				taskID: 1,
 			},
 			beforeTest: func() {
@@ -239,3 +278,393 @@ func TestTaskRepository_Delete(t *testing.T) {
 		})
 	}
 }
+
+func TestTaskRepository_TenantIsolation(t *testing.T) {
+	db, mock := database.NewSqlxMock(t)
+	r := New(db)
+	defer db.Close()
+
+	tenantA := tenant.NewContext(context.TODO(), 1)
+	tenantB := tenant.NewContext(context.TODO(), 2)
+
+	t.Run("FindOne - Filters by the workspace in context", func(t *testing.T) {
+		rows := mock.NewRows([]string{"id", "title", "workspace_id"}).AddRow(1, "Test", 1)
+		mock.ExpectQuery(`SELECT t.\* FROM tasks t WHERE \(t.id = \$1\) AND t.workspace_id = \$2$`).WithArgs(1, 1).WillReturnRows(rows)
+
+		got, err := r.FindOne(tenantA, schema.QueryParams{Where: "t.id = ?", Args: []any{1}})
+		assert.Nil(t, err)
+		assert.Equal(t, uint64(1), got.WorkspaceID)
+
+		mock.ExpectQuery(`SELECT t.\* FROM tasks t WHERE \(t.id = \$1\) AND t.workspace_id = \$2$`).WithArgs(1, 2).WillReturnError(sql.ErrNoRows)
+
+		_, err = r.FindOne(tenantB, schema.QueryParams{Where: "t.id = ?", Args: []any{1}})
+		assert.Equal(t, sql.ErrNoRows, err)
+	})
+
+	t.Run("FindMany - An OR in the caller filter cannot escape the workspace", func(t *testing.T) {
+		rows := mock.NewRows([]string{"id"})
+		mock.ExpectQuery(`SELECT t.\* FROM tasks t WHERE \(t.id = \$1 OR t.id = \$2\) AND t.workspace_id = \$3$`).WithArgs(1, 2, 2).WillReturnRows(rows)
+
+		got, err := r.FindMany(tenantB, schema.QueryParams{Where: "t.id = ? OR t.id = ?", Args: []any{1, 2}})
+		assert.Nil(t, err)
+		assert.Empty(t, got)
+	})
+
+	t.Run("Create - Stamps the workspace in context over the one supplied", func(t *testing.T) {
+		mock.ExpectQuery(`INSERT INTO tasks \(title, workspace_id\) VALUES \(\$1, \$2\)`).WithArgs("Test", 2).WillReturnRows(mock.NewRows([]string{"id"}).AddRow(1))
+
+		ts := &task.Schema{Title: "Test", WorkspaceID: 1}
+		_, err := r.Create(tenantB, ts)
+		assert.Nil(t, err)
+		assert.Equal(t, uint64(2), ts.WorkspaceID)
+	})
+
+	t.Run("Update - Rows of another workspace are not found", func(t *testing.T) {
+		mock.ExpectExec("UPDATE tasks SET (.+) WHERE id = (.+) AND workspace_id =").
+			WithArgs("Test", 1, 2).
+			WillReturnResult(sqlxmock.NewResult(0, 0))
+
+		err := r.Update(tenantB, &task.Schema{ID: 1, Title: "Test", WorkspaceID: 1})
+		assert.Equal(t, sql.ErrNoRows, err)
+	})
+
+	t.Run("Delete - Filters by the workspace in context", func(t *testing.T) {
+		mock.ExpectExec("DELETE FROM tasks WHERE id = (.+) AND workspace_id =").
+			WithArgs(1, 2).
+			WillReturnResult(sqlxmock.NewResult(0, 0))
+
+		err := r.Delete(tenantB, 1)
+		assert.Nil(t, err)
+	})
+
+	t.Run("Missing workspace - Nothing is queried", func(t *testing.T) {
+		_, err := r.FindOne(context.TODO(), schema.QueryParams{})
+		assert.Equal(t, errorMsg.ErrTenantRequired, err)
+
+		_, err = r.FindMany(context.TODO(), schema.QueryParams{})
+		assert.Equal(t, errorMsg.ErrTenantRequired, err)
+
+		_, err = r.Create(context.TODO(), &task.Schema{Title: "Test"})
+		assert.Equal(t, errorMsg.ErrTenantRequired, err)
+
+		err = r.Update(context.TODO(), &task.Schema{ID: 1, Title: "Test"})
+		assert.Equal(t, errorMsg.ErrTenantRequired, err)
+
+		err = r.Delete(context.TODO(), 1)
+		assert.Equal(t, errorMsg.ErrTenantRequired, err)
+	})
+
+	assert.Nil(t, mock.ExpectationsWereMet())
+}
+
+func TestTaskRepository_Injection(t *testing.T) {
+	db, mock := database.NewSqlxMock(t)
+	r := New(db)
+	defer db.Close()
+
+	ctx := tenant.NewContext(context.TODO(), 2)
+	payloads := []string{
+		"$$); DROP TABLE tasks; --",
+		"' OR 1=1 --",
+	}
+
+	for _, payload := range payloads {
+		t.Run("Create - "+payload, func(t *testing.T) {
+			mock.ExpectQuery(`^INSERT INTO tasks \(title, workspace_id\) VALUES \(\$1, \$2\) RETURNING \*$`).
+				WithArgs(payload, 2).
+				WillReturnRows(mock.NewRows([]string{"id"}).AddRow(1))
+
+			_, err := r.Create(ctx, &task.Schema{Title: payload})
+			assert.Nil(t, err)
+		})
+
+		t.Run("Update - "+payload, func(t *testing.T) {
+			mock.ExpectExec(`^UPDATE tasks SET title = \$1, updated_at = CURRENT_TIMESTAMP WHERE id = \$2 AND workspace_id = \$3$`).
+				WithArgs(payload, 1, 2).
+				WillReturnResult(sqlxmock.NewResult(0, 1))
+
+			err := r.Update(ctx, &task.Schema{ID: 1, Title: payload})
+			assert.Nil(t, err)
+		})
+	}
+
+	assert.Nil(t, mock.ExpectationsWereMet())
+}
+
+func TestTaskRepository_SetAssignees(t *testing.T) {
+	db, mock := database.NewSqlxMock(t)
+	r := New(db)
+	defer db.Close()
+
+	type test struct {
+		name       string
+		userIDs    []uint64
+		beforeTest func()
+		err        error
+	}
+
+	tests := []test{
+		{
+			name:    "Success",
+			userIDs: []uint64{2, 3},
+			beforeTest: func() {
+				mock.ExpectBegin()
+				mock.ExpectExec("DELETE FROM task_assignees").WithArgs(1, 1).WillReturnResult(sqlxmock.NewResult(0, 1))
+				mock.ExpectExec("INSERT INTO task_assignees").WillReturnResult(sqlxmock.NewResult(0, 2))
+				mock.ExpectCommit()
+			},
+		},
+		{
+			name:    "Success - Clear",
+			userIDs: nil,
+			beforeTest: func() {
+				mock.ExpectBegin()
+				mock.ExpectExec("DELETE FROM task_assignees").WithArgs(1, 1).WillReturnResult(sqlxmock.NewResult(0, 2))
+				mock.ExpectCommit()
+			},
+		},
+		{
+			name:    "Fail - Assignee outside the workspace",
+			userIDs: []uint64{2, 9},
+			beforeTest: func() {
+				mock.ExpectBegin()
+				mock.ExpectExec("DELETE FROM task_assignees").WithArgs(1, 1).WillReturnResult(sqlxmock.NewResult(0, 1))
+				mock.ExpectExec("INSERT INTO task_assignees").WillReturnResult(sqlxmock.NewResult(0, 1))
+				mock.ExpectRollback()
+			},
+			err: errorMsg.ErrNotWorkspaceMember,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.beforeTest()
+
+			err := r.SetAssignees(tenant.NewContext(context.TODO(), 1), 1, tt.userIDs)
+			assert.Equal(t, tt.err, err)
+			assert.Nil(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestTaskRepository_FindOne_Readable(t *testing.T) {
+	db, mock := database.NewSqlxMock(t)
+	r := New(db)
+	defer db.Close()
+
+	mock.ExpectQuery(`SELECT t.\* FROM tasks t WHERE \(t.id = \$1 AND \(t.user_id IS NULL OR t.user_id = \$2
+	OR EXISTS \(SELECT 1 FROM task_assignees a WHERE a.task_id = t.id AND a.user_id = \$3\)
+	OR EXISTS \(SELECT 1 FROM task_shares s WHERE s.task_id = t.id AND s.user_id = \$4\)\)\) AND t.workspace_id = \$5`).
+		WithArgs(1, 2, 2, 2, 3).
+		WillReturnRows(mock.NewRows([]string{"id"}).AddRow(1))
+
+	got, err := r.FindOne(tenant.NewContext(context.TODO(), 3), ReadableTask(1, 2))
+	assert.Nil(t, err)
+	assert.Equal(t, &task.Schema{ID: 1}, got)
+	assert.Nil(t, mock.ExpectationsWereMet(), "the task ID never stands in for the user ID")
+}
+
+func TestTaskRepository_FindPermission(t *testing.T) {
+	db, mock := database.NewSqlxMock(t)
+	r := New(db)
+	defer db.Close()
+
+	mock.ExpectQuery("SELECT CASE (.+) FROM tasks t WHERE t.id = (.+) AND t.workspace_id =").
+		WithArgs(3, 3, 3, 1, 2).
+		WillReturnRows(mock.NewRows([]string{"case"}).AddRow(task.PermissionRead))
+
+	permission, err := r.FindPermission(tenant.NewContext(context.TODO(), 2), 1, 3)
+	assert.Nil(t, err)
+	assert.Equal(t, task.PermissionRead, permission)
+
+	_, err = r.FindPermission(context.TODO(), 1, 3)
+	assert.Equal(t, errorMsg.ErrTenantRequired, err)
+}
+
+// The queries run for real on SQLite, which speaks a different dialect from
+// the Postgres the mock stands for.
+func TestTaskRepository_SQLite(t *testing.T) {
+	db := database.NewSqlxSqlite(t)
+	r := New(db)
+
+	db.MustExec(`INSERT INTO users (name, email, password) VALUES ('Owner', 'owner@test.com', ''), ('Member', 'member@test.com', '')`)
+	db.MustExec(`INSERT INTO workspace_members (workspace_id, user_id) VALUES (1, 1), (1, 2)`)
+
+	ctx := tenant.NewContext(context.TODO(), 1)
+	owner := uint64(1)
+
+	created, err := r.Create(ctx, &task.Schema{Title: "Test", Description: schema.Some("Test"), UserID: &owner})
+	assert.Nil(t, err)
+	assert.Equal(t, uint64(1), created.ID)
+	assert.Equal(t, uint64(1), created.WorkspaceID)
+	assert.True(t, created.UpdatedAt.Valid)
+
+	err = r.Update(ctx, &task.Schema{ID: created.ID, Title: "Updated", Description: schema.Null[string]()})
+	assert.Nil(t, err)
+
+	got, err := r.FindOne(ctx, schema.QueryParams{Where: "t.id = ?", Args: []any{created.ID}, Limit: 1})
+	assert.Nil(t, err)
+	assert.Equal(t, "Updated", got.Title)
+	assert.True(t, got.Description.IsNull())
+
+	err = r.SetAssignees(ctx, created.ID, []uint64{2})
+	assert.Nil(t, err)
+
+	err = r.SetAssignees(ctx, created.ID, []uint64{2, 3})
+	assert.Equal(t, errorMsg.ErrNotWorkspaceMember, err)
+
+	permission, err := r.FindPermission(ctx, created.ID, 2)
+	assert.Nil(t, err)
+	assert.Equal(t, task.PermissionWrite, permission, "the failed assignment was rolled back")
+
+	err = r.SetAssignees(ctx, created.ID, nil)
+	assert.Nil(t, err)
+
+	for _, p := range []string{task.PermissionRead, task.PermissionWrite} {
+		err = r.SaveShare(ctx, &task.Share{TaskID: created.ID, UserID: 2, Permission: p})
+		assert.Nil(t, err)
+
+		permission, err = r.FindPermission(ctx, created.ID, 2)
+		assert.Nil(t, err)
+		assert.Equal(t, p, permission)
+	}
+
+	err = r.DeleteShare(ctx, created.ID, 2)
+	assert.Nil(t, err)
+
+	permission, err = r.FindPermission(ctx, created.ID, 2)
+	assert.Nil(t, err)
+	assert.Equal(t, "", permission)
+
+	err = r.Delete(ctx, created.ID)
+	assert.Nil(t, err)
+
+	_, err = r.FindOne(ctx, schema.QueryParams{Where: "t.id = ?", Args: []any{created.ID}})
+	assert.Equal(t, sql.ErrNoRows, err)
+}
+
+// Payloads are stored and read back as they were, and never run.
+func TestTaskRepository_Injection_SQLite(t *testing.T) {
+	db := database.NewSqlxSqlite(t)
+	r := New(db)
+	ctx := tenant.NewContext(context.TODO(), 1)
+
+	payloads := []string{
+		"'; DROP TABLE tasks; --",
+		"$$); DROP TABLE tasks; --",
+		"' OR 1=1 --",
+	}
+
+	for _, payload := range payloads {
+		t.Run(payload, func(t *testing.T) {
+			created, err := r.Create(ctx, &task.Schema{Title: payload, Description: schema.Some(payload)})
+			assert.Nil(t, err)
+
+			got, err := r.FindOne(ctx, schema.QueryParams{Where: "t.title = ?", Args: []any{payload}})
+			assert.Nil(t, err)
+			assert.Equal(t, created.ID, got.ID)
+			assert.Equal(t, payload, got.Title)
+			assert.Equal(t, schema.Some(payload), got.Description)
+
+			err = r.Update(ctx, &task.Schema{ID: created.ID, Title: payload + payload})
+			assert.Nil(t, err)
+
+			got, err = r.FindOne(ctx, schema.QueryParams{Where: "t.id = ?", Args: []any{created.ID}})
+			assert.Nil(t, err)
+			assert.Equal(t, payload+payload, got.Title)
+		})
+	}
+
+	var count int
+	err := db.Get(&count, "SELECT count(*) FROM tasks")
+	assert.Nil(t, err, "the tasks table survives")
+	assert.Equal(t, len(payloads), count)
+}
+
+func TestTaskRepository_CountBy(t *testing.T) {
+	db := database.NewSqlxSqlite(t)
+	r := New(db)
+	ctx := tenant.NewContext(context.TODO(), 1)
+
+	db.MustExec(`INSERT INTO tasks (title, workspace_id, status, created_at, completed_at) VALUES
+		('Monday', 1, 'todo', '2026-01-05 10:00:00', NULL),
+		('Wednesday', 1, 'done', '2026-01-07 10:00:00', '2026-01-08 10:00:00'),
+		('Next Monday', 1, 'doing', '2026-01-12 10:00:00', NULL)`)
+
+	err := r.SetLabels(ctx, 1, []string{"a", "b"})
+	assert.Nil(t, err)
+	err = r.SetLabels(ctx, 2, []string{"a"})
+	assert.Nil(t, err)
+
+	group := func(status, label, period string, count uint64) task.Group {
+		return task.Group{
+			Status: sql.NullString{String: status, Valid: status != ""},
+			Label:  sql.NullString{String: label, Valid: label != ""},
+			Period: sql.NullString{String: period, Valid: period != ""},
+			Count:  count,
+		}
+	}
+
+	tests := map[string]struct {
+		by   []string
+		want []task.Group
+	}{
+		"Status":       {by: []string{task.ByStatus}, want: []task.Group{group("doing", "", "", 1), group("done", "", "", 1), group("todo", "", "", 1)}},
+		"Label":        {by: []string{task.ByLabel}, want: []task.Group{group("", "", "", 1), group("", "a", "", 2), group("", "b", "", 1)}},
+		"Week":         {by: []string{task.ByCreatedWeek}, want: []task.Group{group("", "", "2026-01-05", 2), group("", "", "2026-01-12", 1)}},
+		"Completed":    {by: []string{task.ByCompletedDay}, want: []task.Group{group("", "", "", 2), group("", "", "2026-01-08", 1)}},
+		"Status - Day": {by: []string{task.ByStatus, task.ByCreatedDay}, want: []task.Group{group("doing", "", "2026-01-12", 1), group("done", "", "2026-01-07", 1), group("todo", "", "2026-01-05", 1)}},
+		"Nothing":      {by: nil, want: []task.Group{group("", "", "", 3)}},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			got, err := r.CountBy(ctx, tt.by, schema.QueryParams{})
+			assert.Nil(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+
+	got, err := r.CountBy(ctx, []string{task.ByStatus}, schema.QueryParams{Where: "t.created_at >= ?", Args: []any{"2026-01-06"}})
+	assert.Nil(t, err)
+	assert.Equal(t, []task.Group{group("doing", "", "", 1), group("done", "", "", 1)}, got, "params filter what is counted")
+
+	_, err = r.CountBy(ctx, []string{"t.title"}, schema.QueryParams{})
+	assert.Equal(t, errorMsg.ErrInvalidRequestData, err, "only known dimensions are counted by")
+
+	got, err = r.CountBy(tenant.NewContext(context.TODO(), 2), []string{task.ByStatus}, schema.QueryParams{})
+	assert.Nil(t, err)
+	assert.Empty(t, got, "other workspaces count their own tasks")
+}
+
+func TestTaskRepository_UpdateStatus(t *testing.T) {
+	db := database.NewSqlxSqlite(t)
+	r := New(db)
+	ctx := tenant.NewContext(context.TODO(), 1)
+
+	created, err := r.Create(ctx, &task.Schema{Title: "Test"})
+	assert.Nil(t, err)
+	assert.Equal(t, task.StatusTodo, created.Status)
+	assert.False(t, created.CompletedAt.Valid)
+
+	find := func() *task.Schema {
+		got, err := r.FindOne(ctx, schema.QueryParams{Where: "t.id = ?", Args: []any{created.ID}})
+		assert.Nil(t, err)
+		return got
+	}
+
+	assert.True(t, find().CreatedAt.Valid)
+
+	err = r.Update(ctx, &task.Schema{ID: created.ID, Status: task.StatusDone})
+	assert.Nil(t, err)
+	done := find()
+	assert.True(t, done.CompletedAt.Valid, "done tasks are completed")
+
+	err = r.Update(ctx, &task.Schema{ID: created.ID, Title: "Renamed"})
+	assert.Nil(t, err)
+	assert.Equal(t, done.CompletedAt, find().CompletedAt, "completion is only stamped once")
+
+	err = r.Update(ctx, &task.Schema{ID: created.ID, Status: task.StatusDoing})
+	assert.Nil(t, err)
+	assert.False(t, find().CompletedAt.Valid, "reopened tasks are no longer completed")
+}
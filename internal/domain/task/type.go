@@ -1,10 +1,99 @@
 package task
 
-import "database/sql"
+import (
+	"database/sql"
+	"time"
+
+	"github.com/henriqueassiss/advanced-golang-api/internal/utils/schema"
+)
+
+// Permissions a user can hold on a task. Tasks without an owner predate
+// ownership and are owned by every member of their workspace.
+const (
+	PermissionOwner = "owner"
+	PermissionWrite = "write"
+	PermissionRead  = "read"
+)
+
+// Statuses a task goes through. Tasks are completed when they are done.
+const (
+	StatusTodo  = "todo"
+	StatusDoing = "doing"
+	StatusDone  = "done"
+)
+
+func ValidStatus(status string) bool {
+	return status == StatusTodo || status == StatusDoing || status == StatusDone
+}
 
 type Schema struct {
-	ID          uint64       `db:"id"`
-	Title       string       `db:"title"`
-	Description string       `db:"description"`
-	UpdatedAt   sql.NullTime `db:"updated_at"`
+	ID          uint64                  `db:"id,pk,omitempty"`
+	Title       string                  `db:"title,omitempty"`
+	Description schema.Optional[string] `db:"description"`
+	Status      string                  `db:"status,omitempty"`
+	WorkspaceID uint64                  `db:"workspace_id,omitempty"`
+	UserID      *uint64                 `db:"user_id,omitempty"`
+	CreatedAt   sql.NullTime            `db:"created_at,readonly"`
+	UpdatedAt   sql.NullTime            `db:"updated_at,readonly"`
+	CompletedAt sql.NullTime            `db:"completed_at,omitempty"`
+}
+
+type Share struct {
+	TaskID     uint64 `db:"task_id"`
+	UserID     uint64 `db:"user_id"`
+	Permission string `db:"permission"`
+}
+
+// Change is announced on the task_changes channel whenever a task, its
+// assignees or its shares change. Tasks outside any workspace have no
+// WorkspaceID.
+type Change struct {
+	WorkspaceID *uint64 `json:"workspaceId"`
+	TaskID      uint64  `json:"taskId"`
+}
+
+// Dimensions tasks can be counted by. The period ones count tasks by the day
+// or week they were created, last updated or completed.
+const (
+	ByStatus        = "status"
+	ByLabel         = "label"
+	ByCreatedDay    = "created_day"
+	ByCreatedWeek   = "created_week"
+	ByUpdatedDay    = "updated_day"
+	ByUpdatedWeek   = "updated_week"
+	ByCompletedDay  = "completed_day"
+	ByCompletedWeek = "completed_week"
+)
+
+// Group is the count of the tasks sharing the values of the dimensions they
+// were counted by; the others are left out. Period is the first day of the
+// day or week, as 2006-01-02.
+type Group struct {
+	Status sql.NullString `db:"status"`
+	Label  sql.NullString `db:"label"`
+	Period sql.NullString `db:"period"`
+	Count  uint64         `db:"count"`
+}
+
+// StatsQuery asks for the counts of the tasks by the dimensions By, over the
+// days From to To. Tasks are in the range when the period they are counted
+// by is, or when they were created if they are counted by none. Interval is
+// the unit, day or week, of the series.
+type StatsQuery struct {
+	By       []string
+	From     time.Time
+	To       time.Time
+	Interval string
+}
+
+// Point counts the tasks created and completed in a period of a series.
+type Point struct {
+	Period    string
+	Created   uint64
+	Completed uint64
+}
+
+type Stats struct {
+	Groups []Group
+	Series []Point
 }
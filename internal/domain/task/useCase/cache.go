@@ -0,0 +1,256 @@
+package useCase
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"math"
+	"time"
+
+	"github.com/henriqueassiss/advanced-golang-api/internal/domain/task"
+	"github.com/henriqueassiss/advanced-golang-api/internal/utils/persistence"
+	"github.com/henriqueassiss/advanced-golang-api/internal/utils/tenant"
+	"github.com/henriqueassiss/advanced-golang-api/third_party/cache"
+)
+
+// versionTTLFactor keeps versions alive longer than the entries built from
+// them. An expired version only costs cache misses, since its replacement is
+// random, but it keeps versions of deleted tasks from piling up.
+const versionTTLFactor = 4
+
+// earlyExpirationBeta scales how early entries may be refreshed; above one
+// favours earlier refreshes.
+const earlyExpirationBeta = 1.0
+
+// refreshTimeout bounds a background refresh, and how long other replicas
+// leave the key to it.
+const refreshTimeout = 10 * time.Second
+
+func newVersion() (string, error) {
+	b := make([]byte, 8)
+	_, err := rand.Read(b)
+	if err != nil {
+		return "", err
+	}
+
+	return hex.EncodeToString(b), nil
+}
+
+// version returns the current version stored at key, creating one when there
+// is none. Versions are random rather than counters, so an evicted version can
+// never come back and resurrect entries built from it.
+func (uc *Task) version(ctx context.Context, key string) (string, error) {
+	stored, err := uc.cache.Get(ctx, key)
+	if err != cache.ErrNotFound {
+		return string(stored), err
+	}
+
+	v, err := newVersion()
+	if err != nil {
+		return "", err
+	}
+
+	ok, err := uc.cache.Add(ctx, key, []byte(v), uc.ttl*versionTTLFactor, versionsTag)
+	if err != nil || ok {
+		return v, err
+	}
+
+	stored, err = uc.cache.Get(ctx, key)
+
+	return string(stored), err
+}
+
+// entry is what cached stores: the value, when it stops being fresh and how
+// long it took to find, which sizes early expiration.
+type entry struct {
+	Value      json.RawMessage `json:"value"`
+	FreshUntil time.Time       `json:"freshUntil"`
+	Delta      time.Duration   `json:"delta"`
+}
+
+// expired reports whether e should be refreshed. Besides entries past their
+// freshness, it picks fresh entries with a probability that grows as their
+// end nears and with how slow they are to find (XFetch), so the refreshes
+// of a hot key spread out instead of all landing when it expires.
+func (uc *Task) expired(e entry) bool {
+	gap := time.Duration(float64(e.Delta) * earlyExpirationBeta * -math.Log(1-uc.random()))
+
+	return !uc.now().Add(gap).Before(e.FreshUntil)
+}
+
+// filled is what a fill found and how it was encoded for the cache.
+type filled struct {
+	value any
+	data  []byte
+}
+
+// fill runs find and stores its result under k. It reads from the primary:
+// a replica lagging behind a write would have the stale row cached under the
+// version the write created, and served for as long as the entry lives
+// rather than as long as the lag.
+func (uc *Task) fill(ctx context.Context, k string, find func(ctx context.Context) (any, error)) (filled, error) {
+	start := uc.now()
+	v, err := find(persistence.Pin(ctx))
+	if err != nil {
+		return filled{}, err
+	}
+
+	value, err := json.Marshal(v)
+	if err != nil {
+		return filled{}, err
+	}
+
+	data, err := json.Marshal(entry{
+		Value:      value,
+		FreshUntil: uc.now().Add(uc.ttl),
+		Delta:      uc.now().Sub(start),
+	})
+	if err == nil {
+		err = uc.cache.Set(ctx, k, data, uc.ttl+uc.staleTTL)
+	}
+
+	if err != nil {
+		uc.logger.Error(err.Error(), "key", k)
+	}
+
+	return filled{value: v, data: value}, nil
+}
+
+// refresh fills k in the background, at most once at a time per key across
+// replicas. It outlives the request, keeping only its values.
+func (uc *Task) refresh(ctx context.Context, k string, find func(ctx context.Context) (any, error)) {
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), refreshTimeout)
+
+	ok, err := uc.cache.Add(ctx, refreshKey(k), nil, refreshTimeout)
+	if err != nil || !ok {
+		cancel()
+		return
+	}
+
+	uc.refreshes.Add(1)
+	go func() {
+		defer uc.refreshes.Done()
+		defer cancel()
+
+		_, err, _ := uc.fills.Do(k, func() (any, error) {
+			return uc.fill(ctx, k, find)
+		})
+		if err != nil {
+			uc.logger.Error(err.Error(), "key", k)
+		}
+
+		err = uc.cache.Delete(ctx, refreshKey(k))
+		if err != nil {
+			uc.logger.Error(err.Error(), "key", k)
+		}
+	}()
+}
+
+// cached runs find on a cache miss and stores its result. Concurrent misses
+// of a key share one find. Expired entries are still served for staleTTL
+// while a single background refresh replaces them. The cache is an
+// optimization only: when it fails the read goes straight to the repository.
+func cached[T any](ctx context.Context, uc *Task, versionKey string, key func(version string) string, find func(ctx context.Context) (T, error)) (T, error) {
+	findAny := func(ctx context.Context) (any, error) {
+		return find(ctx)
+	}
+
+	version, err := uc.version(ctx, versionKey)
+	if err != nil {
+		uc.logger.Error(err.Error(), "key", versionKey)
+		return find(ctx)
+	}
+
+	var v T
+	k := key(version)
+	data, err := uc.cache.Get(ctx, k)
+	if err == nil {
+		var e entry
+		err = json.Unmarshal(data, &e)
+		if err == nil {
+			err = json.Unmarshal(e.Value, &v)
+		}
+
+		if err == nil {
+			if uc.expired(e) {
+				uc.refresh(ctx, k, findAny)
+			}
+
+			return v, nil
+		}
+	}
+
+	if err != cache.ErrNotFound {
+		uc.logger.Error(err.Error(), "key", k)
+	}
+
+	// The fill is shared, so one caller going away must not fail the others.
+	leader := false
+	result, err, _ := uc.fills.Do(k, func() (any, error) {
+		leader = true
+		return uc.fill(context.WithoutCancel(ctx), k, findAny)
+	})
+	if err != nil {
+		return v, err
+	}
+
+	// The caller that ran find gets its value as found; the others decode
+	// their own copy, as they would from the cache, rather than share it.
+	f := result.(filled)
+	if leader {
+		return f.value.(T), nil
+	}
+
+	err = json.Unmarshal(f.data, &v)
+
+	return v, err
+}
+
+// invalidate drops the cached lists of the workspace in context and, when
+// taskIDs are given, every cached read of those tasks.
+func (uc *Task) invalidate(ctx context.Context, taskIDs ...uint64) {
+	workspaceID, ok := tenant.FromContext(ctx)
+	if !ok {
+		return
+	}
+
+	uc.invalidateWorkspace(ctx, workspaceID, taskIDs...)
+}
+
+func (uc *Task) invalidateWorkspace(ctx context.Context, workspaceID uint64, taskIDs ...uint64) {
+	keys := []string{listVersionKey(workspaceID)}
+	for _, id := range taskIDs {
+		keys = append(keys, taskVersionKey(workspaceID, id))
+	}
+
+	for _, k := range keys {
+		v, err := newVersion()
+		if err == nil {
+			err = uc.cache.Set(ctx, k, []byte(v), uc.ttl*versionTTLFactor, versionsTag)
+		}
+
+		if err != nil {
+			uc.logger.Error(err.Error(), "key", k)
+		}
+	}
+}
+
+// Evict drops what is cached of a task changed elsewhere, as announced on the
+// task_changes channel.
+func (uc *Task) Evict(ctx context.Context, c task.Change) {
+	if c.WorkspaceID == nil {
+		return
+	}
+
+	uc.invalidateWorkspace(ctx, *c.WorkspaceID, c.TaskID)
+}
+
+// EvictAll drops every cached read, for when changes may have been missed.
+// Dropping the versions is enough to orphan every entry built from them.
+func (uc *Task) EvictAll(ctx context.Context) {
+	err := uc.cache.Invalidate(ctx, versionsTag)
+	if err != nil {
+		uc.logger.Error(err.Error(), "tag", versionsTag)
+	}
+}
@@ -0,0 +1,31 @@
+package useCase
+
+import "fmt"
+
+// Cached reads are stored under versioned keys. Invalidating replaces the
+// version, which orphans every entry built from the previous one until its
+// TTL runs out, so no key ever has to be enumerated.
+
+// versionsTag tags every version, so they can all be dropped at once.
+const versionsTag = "task:versions"
+
+func taskVersionKey(workspaceID, taskID uint64) string {
+	return fmt.Sprintf("task:%d:%d:version", workspaceID, taskID)
+}
+
+func taskKey(workspaceID, taskID uint64, version string, userID uint64) string {
+	return fmt.Sprintf("task:%d:%d:%s:user:%d", workspaceID, taskID, version, userID)
+}
+
+func listVersionKey(workspaceID uint64) string {
+	return fmt.Sprintf("tasks:%d:version", workspaceID)
+}
+
+func listKey(workspaceID uint64, version, view string, userID uint64) string {
+	return fmt.Sprintf("tasks:%d:%s:%s:user:%d", workspaceID, version, view, userID)
+}
+
+// refreshKey is held while an entry is being refreshed in the background.
+func refreshKey(key string) string {
+	return key + ":refresh"
+}
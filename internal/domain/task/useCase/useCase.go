@@ -2,60 +2,453 @@ package useCase
 
 import (
 	"context"
-	"fmt"
+	"database/sql"
 	"log/slog"
+	"math/rand"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
 
 	"github.com/henriqueassiss/advanced-golang-api/internal/domain/task"
 	"github.com/henriqueassiss/advanced-golang-api/internal/domain/task/repository"
+	"github.com/henriqueassiss/advanced-golang-api/internal/utils/errorMsg"
+	"github.com/henriqueassiss/advanced-golang-api/internal/utils/identity"
+	"github.com/henriqueassiss/advanced-golang-api/internal/utils/persistence"
 	"github.com/henriqueassiss/advanced-golang-api/internal/utils/schema"
-	"github.com/redis/go-redis/v9"
+	"github.com/henriqueassiss/advanced-golang-api/internal/utils/tenant"
+	"github.com/henriqueassiss/advanced-golang-api/third_party/cache"
+
+	"golang.org/x/sync/singleflight"
 )
 
 type ITask interface {
 	FindOne(ctx context.Context, taskID uint64) (*task.Schema, error)
-	Create(ctx context.Context, t *task.Schema) error
+	FindAssigned(ctx context.Context) ([]task.Schema, error)
+	FindShared(ctx context.Context) ([]task.Schema, error)
+	Create(ctx context.Context, t *task.Schema) (*task.Schema, error)
+	Import(ctx context.Context, next func() (*task.Schema, error)) (int64, error)
 	Update(ctx context.Context, t *task.Schema) error
 	Delete(ctx context.Context, taskID uint64) error
+	SetAssignees(ctx context.Context, taskID uint64, userIDs []uint64) error
+	Share(ctx context.Context, s *task.Share) error
+	Unshare(ctx context.Context, taskID, userID uint64) error
+	SetLabels(ctx context.Context, taskID uint64, labels []string) error
+	Stats(ctx context.Context, q task.StatsQuery) (*task.Stats, error)
 }
 
+const (
+	maxLabelLength = 64
+	// maxStatsDays bounds the range of stats, and so the length of series.
+	maxStatsDays = 366
+)
+
 type Task struct {
 	repository repository.ITask
+	tx         persistence.Transactor
 	logger     *slog.Logger
-	cache      *redis.Client
+	cache      cache.Cache
+	ttl        time.Duration
+	staleTTL   time.Duration
+
+	fills     singleflight.Group
+	refreshes sync.WaitGroup
+	now       func() time.Time
+	random    func() float64
 }
 
-func New(repo repository.ITask, logger *slog.Logger, cache *redis.Client) *Task {
+// New caches reads for ttl, then serves them for up to staleTTL more while
+// they are refreshed. Writes run in transactions of tx together with the
+// permission check allowing them.
+func New(repo repository.ITask, tx persistence.Transactor, logger *slog.Logger, cache cache.Cache, ttl, staleTTL time.Duration) *Task {
 	return &Task{
 		repository: repo,
+		tx:         tx,
 		logger:     logger,
 		cache:      cache,
+		ttl:        ttl,
+		staleTTL:   staleTTL,
+		now:        time.Now,
+		random:     rand.Float64,
+	}
+}
+
+func userID(ctx context.Context) (uint64, error) {
+	i, ok := identity.FromContext(ctx)
+	if !ok {
+		return 0, errorMsg.ErrUnauthorized
 	}
+
+	return i.UserID, nil
 }
 
+// authorize fails with sql.ErrNoRows when the task is not visible to the user
+// and with ErrForbidden when it is visible but not with any of the allowed
+// permissions.
+func (uc *Task) authorize(ctx context.Context, taskID uint64, allowed ...string) error {
+	uid, err := userID(ctx)
+	if err != nil {
+		return err
+	}
+
+	permission, err := uc.repository.FindPermission(ctx, taskID, uid)
+	if err != nil {
+		return err
+	}
+
+	if permission == "" {
+		return sql.ErrNoRows
+	}
+
+	for _, a := range allowed {
+		if permission == a {
+			return nil
+		}
+	}
+
+	return errorMsg.ErrForbidden
+}
+
+// FindOne is cached per user, since whether the task is visible at all
+// depends on who asks.
 func (uc *Task) FindOne(ctx context.Context, taskID uint64) (*task.Schema, error) {
-	return uc.repository.FindOne(ctx, schema.QueryParams{
-		Where: fmt.Sprintf("t.id = %d", taskID),
+	uid, err := userID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	workspaceID, ok := tenant.FromContext(ctx)
+	if !ok {
+		return nil, errorMsg.ErrTenantRequired
+	}
+
+	key := func(version string) string {
+		return taskKey(workspaceID, taskID, version, uid)
+	}
+
+	return cached(ctx, uc, taskVersionKey(workspaceID, taskID), key, func(ctx context.Context) (*task.Schema, error) {
+		return uc.repository.FindOne(ctx, repository.ReadableTask(taskID, uid))
+	})
+}
+
+func (uc *Task) findMany(ctx context.Context, view, where string) ([]task.Schema, error) {
+	uid, err := userID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	workspaceID, ok := tenant.FromContext(ctx)
+	if !ok {
+		return nil, errorMsg.ErrTenantRequired
+	}
+
+	key := func(version string) string {
+		return listKey(workspaceID, version, view, uid)
+	}
+
+	return cached(ctx, uc, listVersionKey(workspaceID), key, func(ctx context.Context) ([]task.Schema, error) {
+		return uc.repository.FindMany(ctx, schema.QueryParams{
+			Where:   where,
+			Args:    []any{uid},
+			OrderBy: "t.id",
+		})
 	})
 }
 
-func (uc *Task) Create(ctx context.Context, t *task.Schema) error {
-	return uc.repository.Create(ctx, t)
+func (uc *Task) FindAssigned(ctx context.Context) ([]task.Schema, error) {
+	return uc.findMany(ctx, "assigned", repository.AssignedTo)
+}
+
+func (uc *Task) FindShared(ctx context.Context) ([]task.Schema, error) {
+	return uc.findMany(ctx, "shared", repository.SharedWith)
+}
+
+// Create stores t, owned by the user in ctx, and returns it as stored.
+func (uc *Task) Create(ctx context.Context, t *task.Schema) (*task.Schema, error) {
+	uid, err := userID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if t.Status != "" && !task.ValidStatus(t.Status) {
+		return nil, errorMsg.ErrInvalidRequestData
+	}
+
+	if t.Status == task.StatusDone {
+		t.CompletedAt = sql.NullTime{Time: uc.now(), Valid: true}
+	}
+
+	t.UserID = &uid
+
+	created, err := uc.repository.Create(ctx, t)
+	if err != nil {
+		return nil, err
+	}
+
+	uc.invalidate(ctx)
+
+	return created, nil
+}
+
+// Import stores the tasks next yields as Create would, many at a time, and
+// returns how many it stored. It stops at the first invalid task; the tasks
+// stored before it stay.
+func (uc *Task) Import(ctx context.Context, next func() (*task.Schema, error)) (int64, error) {
+	uid, err := userID(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	imported, err := uc.repository.Import(ctx, func() (*task.Schema, error) {
+		t, err := next()
+		if t == nil || err != nil {
+			return nil, err
+		}
+
+		if t.Title == "" || t.Status != "" && !task.ValidStatus(t.Status) {
+			return nil, errorMsg.ErrInvalidRequestData
+		}
+
+		if t.Status == "" {
+			t.Status = task.StatusTodo
+		}
+
+		if t.Status == task.StatusDone {
+			t.CompletedAt = sql.NullTime{Time: uc.now(), Valid: true}
+		}
+
+		t.UserID = &uid
+
+		return t, nil
+	})
+
+	if imported > 0 {
+		uc.invalidate(ctx)
+	}
+
+	return imported, err
 }
 
 func (uc *Task) Update(ctx context.Context, t *task.Schema) error {
-	return uc.repository.Update(ctx, t)
+	if t.Status != "" && !task.ValidStatus(t.Status) {
+		return errorMsg.ErrInvalidRequestData
+	}
+
+	err := uc.tx.Run(ctx, func(ctx context.Context) error {
+		err := uc.authorize(ctx, t.ID, task.PermissionOwner, task.PermissionWrite)
+		if err != nil {
+			return err
+		}
+
+		return uc.repository.Update(ctx, t)
+	})
+	if err != nil {
+		return err
+	}
+
+	uc.invalidate(ctx, t.ID)
+
+	return nil
 }
 
 func (uc *Task) Delete(ctx context.Context, taskID uint64) error {
-	_, err := uc.repository.FindOne(ctx, schema.QueryParams{
-		Select: "c.id",
-		Where:  fmt.Sprintf("c.id = %d", taskID),
+	err := uc.tx.Run(ctx, func(ctx context.Context) error {
+		err := uc.authorize(ctx, taskID, task.PermissionOwner)
+		if err != nil {
+			return err
+		}
+
+		return uc.repository.Delete(ctx, taskID)
 	})
 	if err != nil {
 		return err
 	}
 
-	err = uc.repository.Delete(ctx, taskID)
+	uc.invalidate(ctx, taskID)
+
+	return nil
+}
+
+func (uc *Task) SetAssignees(ctx context.Context, taskID uint64, userIDs []uint64) error {
+	seen := make(map[uint64]bool, len(userIDs))
+	unique := make([]uint64, 0, len(userIDs))
+	for _, id := range userIDs {
+		if !seen[id] {
+			seen[id] = true
+			unique = append(unique, id)
+		}
+	}
+
+	err := uc.tx.Run(ctx, func(ctx context.Context) error {
+		err := uc.authorize(ctx, taskID, task.PermissionOwner, task.PermissionWrite)
+		if err != nil {
+			return err
+		}
+
+		return uc.repository.SetAssignees(ctx, taskID, unique)
+	})
+	if err != nil {
+		return err
+	}
+
+	uc.invalidate(ctx, taskID)
+
+	return nil
+}
+
+func (uc *Task) Share(ctx context.Context, s *task.Share) error {
+	if s.Permission != task.PermissionRead && s.Permission != task.PermissionWrite {
+		return errorMsg.ErrInvalidRequestData
+	}
+
+	err := uc.tx.Run(ctx, func(ctx context.Context) error {
+		err := uc.authorize(ctx, s.TaskID, task.PermissionOwner)
+		if err != nil {
+			return err
+		}
+
+		return uc.repository.SaveShare(ctx, s)
+	})
+	if err != nil {
+		return err
+	}
+
+	uc.invalidate(ctx, s.TaskID)
+
+	return nil
+}
+
+func (uc *Task) Unshare(ctx context.Context, taskID, userID uint64) error {
+	err := uc.tx.Run(ctx, func(ctx context.Context) error {
+		err := uc.authorize(ctx, taskID, task.PermissionOwner)
+		if err != nil {
+			return err
+		}
+
+		return uc.repository.DeleteShare(ctx, taskID, userID)
+	})
+	if err != nil {
+		return err
+	}
+
+	uc.invalidate(ctx, taskID)
+
+	return nil
+}
+
+// SetLabels replaces the labels of the task, ignoring repeated ones. Labels
+// are trimmed and must be between 1 and maxLabelLength characters long.
+func (uc *Task) SetLabels(ctx context.Context, taskID uint64, labels []string) error {
+	seen := make(map[string]bool, len(labels))
+	unique := make([]string, 0, len(labels))
+	for _, label := range labels {
+		label = strings.TrimSpace(label)
+		if label == "" || utf8.RuneCountInString(label) > maxLabelLength {
+			return errorMsg.ErrInvalidRequestData
+		}
+
+		if !seen[label] {
+			seen[label] = true
+			unique = append(unique, label)
+		}
+	}
+
+	return uc.tx.Run(ctx, func(ctx context.Context) error {
+		err := uc.authorize(ctx, taskID, task.PermissionOwner, task.PermissionWrite)
+		if err != nil {
+			return err
+		}
+
+		return uc.repository.SetLabels(ctx, taskID, unique)
+	})
+}
+
+// Stats counts the tasks visible to the user by the dimensions of q, along
+// with the series of tasks created and completed per q.Interval, over the
+// range of q. Periods without any task are counted in the series as zero.
+func (uc *Task) Stats(ctx context.Context, q task.StatsQuery) (*task.Stats, error) {
+	uid, err := userID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	// Tasks counted by a period are in the range when that period is, so
+	// there can only be one.
+	column := repository.Dimensions[task.ByCreatedDay].Column
+	periods := 0
+	seen := make(map[string]bool, len(q.By))
+	for _, by := range q.By {
+		d, ok := repository.Dimensions[by]
+		if !ok || seen[by] {
+			return nil, errorMsg.ErrInvalidRequestData
+		}
+
+		seen[by] = true
+		if d.Unit != "" {
+			column = d.Column
+			periods++
+		}
+	}
+
+	if periods > 1 || (q.Interval != "day" && q.Interval != "week") ||
+		q.To.Before(q.From) || q.To.Sub(q.From) >= maxStatsDays*24*time.Hour {
+		return nil, errorMsg.ErrInvalidRequestData
+	}
+
+	in := func(column string) schema.QueryParams {
+		return schema.QueryParams{
+			Where: repository.Readable + " AND " + column + " >= ? AND " + column + " < ?",
+			Args:  append(repository.ReadableArgs(uid), q.From, q.To.AddDate(0, 0, 1)),
+		}
+	}
+
+	stats := &task.Stats{}
+	if len(q.By) != 0 {
+		stats.Groups, err = uc.repository.CountBy(ctx, q.By, in(column))
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	created, err := uc.repository.CountBy(ctx, []string{"created_" + q.Interval}, in(repository.Dimensions[task.ByCreatedDay].Column))
+	if err != nil {
+		return nil, err
+	}
+
+	completed, err := uc.repository.CountBy(ctx, []string{"completed_" + q.Interval}, in(repository.Dimensions[task.ByCompletedDay].Column))
+	if err != nil {
+		return nil, err
+	}
+
+	stats.Series = series(q, created, completed)
+
+	return stats, nil
+}
+
+// series lays the counts of created and completed tasks over every period of
+// the range of q, starting on the one holding q.From.
+func series(q task.StatsQuery, created, completed []task.Group) []task.Point {
+	counts := func(groups []task.Group) map[string]uint64 {
+		m := make(map[string]uint64, len(groups))
+		for _, g := range groups {
+			m[g.Period.String] = g.Count
+		}
+
+		return m
+	}
+	c, d := counts(created), counts(completed)
+
+	start, step := q.From, 1
+	if q.Interval == "week" {
+		// Weeks start on Monday.
+		start, step = start.AddDate(0, 0, -(int(start.Weekday())+6)%7), 7
+	}
+
+	var points []task.Point
+	for p := start; !p.After(q.To); p = p.AddDate(0, 0, step) {
+		period := p.Format(time.DateOnly)
+		points = append(points, task.Point{Period: period, Created: c[period], Completed: d[period]})
+	}
 
-	return err
+	return points
 }
@@ -7,20 +7,40 @@ import (
 )
 
 type TaskMock struct {
-	FindOneFunc func(ctx context.Context, taskID uint64) (*task.Schema, error)
-	CreateFunc  func(ctx context.Context, t *task.Schema) error
-	UpdateFunc  func(ctx context.Context, t *task.Schema) error
-	DeleteFunc  func(ctx context.Context, taskID uint64) error
+	FindOneFunc      func(ctx context.Context, taskID uint64) (*task.Schema, error)
+	FindAssignedFunc func(ctx context.Context) ([]task.Schema, error)
+	FindSharedFunc   func(ctx context.Context) ([]task.Schema, error)
+	CreateFunc       func(ctx context.Context, t *task.Schema) (*task.Schema, error)
+	ImportFunc       func(ctx context.Context, next func() (*task.Schema, error)) (int64, error)
+	UpdateFunc       func(ctx context.Context, t *task.Schema) error
+	DeleteFunc       func(ctx context.Context, taskID uint64) error
+	SetAssigneesFunc func(ctx context.Context, taskID uint64, userIDs []uint64) error
+	ShareFunc        func(ctx context.Context, s *task.Share) error
+	UnshareFunc      func(ctx context.Context, taskID, userID uint64) error
+	SetLabelsFunc    func(ctx context.Context, taskID uint64, labels []string) error
+	StatsFunc        func(ctx context.Context, q task.StatsQuery) (*task.Stats, error)
 }
 
 func (uc *TaskMock) FindOne(ctx context.Context, taskID uint64) (*task.Schema, error) {
 	return uc.FindOneFunc(ctx, taskID)
 }
 
-func (uc *TaskMock) Create(ctx context.Context, t *task.Schema) error {
+func (uc *TaskMock) FindAssigned(ctx context.Context) ([]task.Schema, error) {
+	return uc.FindAssignedFunc(ctx)
+}
+
+func (uc *TaskMock) FindShared(ctx context.Context) ([]task.Schema, error) {
+	return uc.FindSharedFunc(ctx)
+}
+
+func (uc *TaskMock) Create(ctx context.Context, t *task.Schema) (*task.Schema, error) {
 	return uc.CreateFunc(ctx, t)
 }
 
+func (uc *TaskMock) Import(ctx context.Context, next func() (*task.Schema, error)) (int64, error) {
+	return uc.ImportFunc(ctx, next)
+}
+
 func (uc *TaskMock) Update(ctx context.Context, t *task.Schema) error {
 	return uc.UpdateFunc(ctx, t)
 }
@@ -28,3 +48,23 @@ func (uc *TaskMock) Update(ctx context.Context, t *task.Schema) error {
 func (uc *TaskMock) Delete(ctx context.Context, taskID uint64) error {
 	return uc.DeleteFunc(ctx, taskID)
 }
+
+func (uc *TaskMock) SetAssignees(ctx context.Context, taskID uint64, userIDs []uint64) error {
+	return uc.SetAssigneesFunc(ctx, taskID, userIDs)
+}
+
+func (uc *TaskMock) Share(ctx context.Context, s *task.Share) error {
+	return uc.ShareFunc(ctx, s)
+}
+
+func (uc *TaskMock) Unshare(ctx context.Context, taskID, userID uint64) error {
+	return uc.UnshareFunc(ctx, taskID, userID)
+}
+
+func (uc *TaskMock) SetLabels(ctx context.Context, taskID uint64, labels []string) error {
+	return uc.SetLabelsFunc(ctx, taskID, labels)
+}
+
+func (uc *TaskMock) Stats(ctx context.Context, q task.StatsQuery) (*task.Stats, error) {
+	return uc.StatsFunc(ctx, q)
+}
@@ -3,26 +3,39 @@ package useCase
 import (
 	"context"
 	"database/sql"
+	"strings"
+	"sync"
 	"testing"
 	"time"
 
 	"github.com/henriqueassiss/advanced-golang-api/internal/domain/task"
 	"github.com/henriqueassiss/advanced-golang-api/internal/domain/task/repository"
+	"github.com/henriqueassiss/advanced-golang-api/internal/utils/errorMsg"
+	"github.com/henriqueassiss/advanced-golang-api/internal/utils/identity"
+	"github.com/henriqueassiss/advanced-golang-api/internal/utils/persistence"
+	"github.com/henriqueassiss/advanced-golang-api/internal/utils/schema"
+	"github.com/henriqueassiss/advanced-golang-api/internal/utils/tenant"
 
 	"github.com/henriqueassiss/advanced-golang-api/third_party/cache"
 	"github.com/henriqueassiss/advanced-golang-api/third_party/database"
 	"github.com/henriqueassiss/advanced-golang-api/third_party/logger"
 
+	"github.com/jmoiron/sqlx"
+	"github.com/redis/go-redis/v9"
 	"github.com/stretchr/testify/assert"
 	sqlxmock "github.com/zhashkevych/go-sqlxmock"
 )
 
+func newContext(userID uint64) context.Context {
+	return identity.NewContext(tenant.NewContext(context.TODO(), 1), &identity.Identity{UserID: userID})
+}
+
 func TestTaskUseCase_FindOne(t *testing.T) {
 	logger := logger.New()
 	db, mock := database.NewSqlxMock(t)
 	cacheMock := cache.NewMock(t)
 	r := repository.New(db)
-	uc := New(r, logger, cacheMock)
+	uc := New(r, persistence.NewTxManager(db, sql.LevelDefault, 0), logger, cacheMock, time.Minute, 0)
 	defer db.Close()
 
 	type args struct {
@@ -46,7 +59,7 @@ func TestTaskUseCase_FindOne(t *testing.T) {
 		{
 			name: "Success",
 			args: args{
-				ctx:    context.TODO(),
+				ctx:    newContext(2),
 				taskID: 1,
 			},
 			beforeTest: func(taskID uint64) {
@@ -64,13 +77,15 @@ func TestTaskUseCase_FindOne(t *testing.T) {
 						Time:  time.Date(2000, 1, 1, 0, 0, 0, 0, time.Local),
 					},
 				)
-				mock.ExpectQuery("SELECT t.* FROM tasks t WHERE t.id =").WillReturnRows(taskRows)
+				mock.ExpectQuery(`SELECT t.\* FROM tasks t WHERE \(t.id = \$1 AND \(t.user_id IS NULL OR t.user_id = \$2(.+)\)\) AND t.workspace_id = \$5`).
+					WithArgs(taskID, 2, 2, 2, 1).
+					WillReturnRows(taskRows)
 			},
 			want: want{
 				t: &task.Schema{
 					ID:          1,
 					Title:       "Test",
-					Description: "Test",
+					Description: schema.Some("Test"),
 					UpdatedAt: sql.NullTime{
 						Valid: true,
 						Time:  time.Date(2000, 1, 1, 0, 0, 0, 0, time.Local),
@@ -96,7 +111,7 @@ func TestTaskUseCase_Create(t *testing.T) {
 	db, mock := database.NewSqlxMock(t)
 	cacheMock := cache.NewMock(t)
 	r := repository.New(db)
-	uc := New(r, logger, cacheMock)
+	uc := New(r, persistence.NewTxManager(db, sql.LevelDefault, 0), logger, cacheMock, time.Minute, 0)
 	defer db.Close()
 
 	type args struct {
@@ -119,11 +134,11 @@ func TestTaskUseCase_Create(t *testing.T) {
 		{
 			name: "Success",
 			args: args{
-				ctx: context.TODO(),
+				ctx: newContext(1),
 				t: &task.Schema{
 					ID:          1,
 					Title:       "Test",
-					Description: "Test",
+					Description: schema.Some("Test"),
 					UpdatedAt: sql.NullTime{
 						Valid: true,
 						Time:  time.Date(2000, 1, 1, 0, 0, 0, 0, time.Local),
@@ -131,7 +146,8 @@ func TestTaskUseCase_Create(t *testing.T) {
 				},
 			},
 			beforeTest: func(t *task.Schema) {
-				mock.ExpectExec("INSERT INTO tasks").WillReturnResult(sqlxmock.NewResult(0, 1))
+				mock.ExpectQuery("INSERT INTO tasks").
+					WillReturnRows(mock.NewRows([]string{"id", "title", "updated_at"}).AddRow(1, "Test", time.Date(2000, 1, 1, 0, 0, 0, 0, time.Local)))
 			},
 		},
 	}
@@ -140,8 +156,12 @@ func TestTaskUseCase_Create(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			tt.beforeTest(tt.args.t)
 
-			err := uc.Create(tt.args.ctx, tt.args.t)
+			created, err := uc.Create(tt.args.ctx, tt.args.t)
 			assert.Equal(t, tt.want.err, err)
+			if err == nil {
+				assert.Equal(t, uint64(1), created.ID)
+				assert.True(t, created.UpdatedAt.Valid, "timestamps come from the database")
+			}
 		})
 	}
 }
@@ -151,7 +171,7 @@ func TestTaskUseCase_Update(t *testing.T) {
 	db, mock := database.NewSqlxMock(t)
 	cacheMock := cache.NewMock(t)
 	r := repository.New(db)
-	uc := New(r, logger, cacheMock)
+	uc := New(r, persistence.NewTxManager(db, sql.LevelDefault, 0), logger, cacheMock, time.Minute, 0)
 	defer db.Close()
 
 	type args struct {
@@ -174,11 +194,11 @@ func TestTaskUseCase_Update(t *testing.T) {
 		{
 			name: "Success - No images",
 			args: args{
-				ctx: context.TODO(),
+				ctx: newContext(1),
 				t: &task.Schema{
 					ID:          1,
 					Title:       "Test",
-					Description: "Test",
+					Description: schema.Some("Test"),
 					UpdatedAt: sql.NullTime{
 						Valid: true,
 						Time:  time.Date(2000, 1, 1, 0, 0, 0, 0, time.Local),
@@ -186,7 +206,46 @@ func TestTaskUseCase_Update(t *testing.T) {
 				},
 			},
 			beforeTest: func(t *task.Schema) {
+				mock.ExpectBegin()
+				mock.ExpectQuery("SELECT CASE (.+) FROM tasks t").WithArgs(1, 1, 1, t.ID, 1).WillReturnRows(mock.NewRows([]string{"case"}).AddRow(task.PermissionWrite))
 				mock.ExpectExec("UPDATE tasks").WillReturnResult(sqlxmock.NewResult(0, 1))
+				mock.ExpectCommit()
+			},
+		},
+		{
+			name: "Fail - Read-only share",
+			args: args{
+				ctx: newContext(2),
+				t: &task.Schema{
+					ID:    1,
+					Title: "Test",
+				},
+			},
+			beforeTest: func(t *task.Schema) {
+				mock.ExpectBegin()
+				mock.ExpectQuery("SELECT CASE (.+) FROM tasks t").WithArgs(2, 2, 2, t.ID, 1).WillReturnRows(mock.NewRows([]string{"case"}).AddRow(task.PermissionRead))
+				mock.ExpectRollback()
+			},
+			want: want{
+				err: errorMsg.ErrForbidden,
+			},
+		},
+		{
+			name: "Fail - Task not visible",
+			args: args{
+				ctx: newContext(3),
+				t: &task.Schema{
+					ID:    1,
+					Title: "Test",
+				},
+			},
+			beforeTest: func(t *task.Schema) {
+				mock.ExpectBegin()
+				mock.ExpectQuery("SELECT CASE (.+) FROM tasks t").WithArgs(3, 3, 3, t.ID, 1).WillReturnRows(mock.NewRows([]string{"case"}).AddRow(""))
+				mock.ExpectRollback()
+			},
+			want: want{
+				err: sql.ErrNoRows,
 			},
 		},
 	}
@@ -206,7 +265,7 @@ func TestTaskUseCase_Delete(t *testing.T) {
 	db, mock := database.NewSqlxMock(t)
 	cacheMock := cache.NewMock(t)
 	r := repository.New(db)
-	uc := New(r, logger, cacheMock)
+	uc := New(r, persistence.NewTxManager(db, sql.LevelDefault, 0), logger, cacheMock, time.Minute, 0)
 	defer db.Close()
 
 	type args struct {
@@ -229,24 +288,28 @@ func TestTaskUseCase_Delete(t *testing.T) {
 		{
 			name: "Success",
 			args: args{
-				ctx:    context.TODO(),
+				ctx:    newContext(1),
 				taskID: 1,
 			},
 			beforeTest: func(taskID uint64) {
-				taskRows := mock.NewRows([]string{"id"}).AddRow(taskID)
+				taskRows := mock.NewRows([]string{"case"}).AddRow(task.PermissionOwner)
+				mock.ExpectBegin()
 				mock.ExpectQuery("SELECT (.+) FROM tasks").WillReturnRows(taskRows)
 
-				mock.ExpectExec("DELETE FROM tasks").WithArgs(taskID).WillReturnResult(sqlxmock.NewResult(0, 1))
+				mock.ExpectExec("DELETE FROM tasks").WithArgs(taskID, 1).WillReturnResult(sqlxmock.NewResult(0, 1))
+				mock.ExpectCommit()
 			},
 		},
 		{
 			name: "Fail - Invalid task",
 			args: args{
-				ctx:    context.TODO(),
+				ctx:    newContext(1),
 				taskID: 0,
 			},
 			beforeTest: func(taskID uint64) {
+				mock.ExpectBegin()
 				mock.ExpectQuery("SELECT (.+) FROM tasks").WillReturnError(sql.ErrNoRows)
+				mock.ExpectRollback()
 			},
 			want: want{
 				err: sql.ErrNoRows,
@@ -263,3 +326,571 @@ func TestTaskUseCase_Delete(t *testing.T) {
 		})
 	}
 }
+
+func TestTaskUseCase_SetAssignees(t *testing.T) {
+	logger := logger.New()
+	db, mock := database.NewSqlxMock(t)
+	cacheMock := cache.NewMock(t)
+	r := repository.New(db)
+	uc := New(r, persistence.NewTxManager(db, sql.LevelDefault, 0), logger, cacheMock, time.Minute, 0)
+	defer db.Close()
+
+	mock.ExpectBegin()
+	mock.ExpectQuery("SELECT CASE (.+) FROM tasks t").WithArgs(1, 1, 1, 1, 1).WillReturnRows(mock.NewRows([]string{"case"}).AddRow(task.PermissionOwner))
+	mock.ExpectExec("SAVEPOINT sp_1").WillReturnResult(sqlxmock.NewResult(0, 0))
+	mock.ExpectExec("DELETE FROM task_assignees").WithArgs(1, 1).WillReturnResult(sqlxmock.NewResult(0, 0))
+	mock.ExpectExec("INSERT INTO task_assignees").WillReturnResult(sqlxmock.NewResult(0, 2))
+	mock.ExpectExec("RELEASE SAVEPOINT sp_1").WillReturnResult(sqlxmock.NewResult(0, 0))
+	mock.ExpectCommit()
+
+	err := uc.SetAssignees(newContext(1), 1, []uint64{2, 3, 2})
+	assert.Nil(t, err)
+	assert.Nil(t, mock.ExpectationsWereMet())
+}
+
+func TestTaskUseCase_SetLabels(t *testing.T) {
+	logger := logger.New()
+	db, mock := database.NewSqlxMock(t)
+	cacheMock := cache.NewMock(t)
+	r := repository.New(db)
+	uc := New(r, persistence.NewTxManager(db, sql.LevelDefault, 0), logger, cacheMock, time.Minute, 0)
+	defer db.Close()
+
+	mock.ExpectBegin()
+	mock.ExpectQuery("SELECT CASE (.+) FROM tasks t").WithArgs(1, 1, 1, 1, 1).WillReturnRows(mock.NewRows([]string{"case"}).AddRow(task.PermissionWrite))
+	mock.ExpectExec("SAVEPOINT sp_1").WillReturnResult(sqlxmock.NewResult(0, 0))
+	mock.ExpectExec("DELETE FROM task_labels").WithArgs(1, 1).WillReturnResult(sqlxmock.NewResult(0, 0))
+	mock.ExpectExec("INSERT INTO task_labels").WithArgs("bug", 1, 1).WillReturnResult(sqlxmock.NewResult(0, 1))
+	mock.ExpectExec("INSERT INTO task_labels").WithArgs("urgent", 1, 1).WillReturnResult(sqlxmock.NewResult(0, 1))
+	mock.ExpectExec("RELEASE SAVEPOINT sp_1").WillReturnResult(sqlxmock.NewResult(0, 0))
+	mock.ExpectCommit()
+
+	err := uc.SetLabels(newContext(1), 1, []string{"bug", " urgent ", "bug"})
+	assert.Nil(t, err)
+	assert.Nil(t, mock.ExpectationsWereMet())
+
+	for _, labels := range [][]string{{""}, {strings.Repeat("a", maxLabelLength+1)}} {
+		err = uc.SetLabels(newContext(1), 1, labels)
+		assert.Equal(t, errorMsg.ErrInvalidRequestData, err)
+	}
+}
+
+func TestTaskUseCase_Stats(t *testing.T) {
+	logger := logger.New()
+	db, mock := database.NewSqlxMock(t)
+	cacheMock := cache.NewMock(t)
+	r := repository.New(db)
+	uc := New(r, persistence.NewTxManager(db, sql.LevelDefault, 0), logger, cacheMock, time.Minute, 0)
+	defer db.Close()
+
+	from := time.Date(2026, 1, 5, 0, 0, 0, 0, time.Local)
+	q := task.StatsQuery{By: []string{task.ByStatus, task.ByUpdatedDay}, From: from, To: from.AddDate(0, 0, 2), Interval: "day"}
+	end := from.AddDate(0, 0, 3)
+
+	mock.ExpectQuery(`^SELECT t.status AS status, to_char\(date_trunc\('day', t.updated_at\), 'YYYY-MM-DD'\) AS period, count\(\*\) AS count FROM tasks t WHERE \(.+ AND t.updated_at >= \$4 AND t.updated_at < \$5\) AND t.workspace_id = \$6 GROUP BY t.status, to_char(.+) ORDER BY t.status, to_char(.+)$`).
+		WithArgs(2, 2, 2, from, end, 1).
+		WillReturnRows(mock.NewRows([]string{"status", "period", "count"}).AddRow("done", "2026-01-06", 2))
+	mock.ExpectQuery(`AS period, count\(\*\) AS count FROM tasks t WHERE \(.+ AND t.created_at >= \$4 AND t.created_at < \$5\)`).
+		WithArgs(2, 2, 2, from, end, 1).
+		WillReturnRows(mock.NewRows([]string{"period", "count"}).AddRow("2026-01-05", 3).AddRow("2026-01-07", 1))
+	mock.ExpectQuery(`AS period, count\(\*\) AS count FROM tasks t WHERE \(.+ AND t.completed_at >= \$4 AND t.completed_at < \$5\)`).
+		WithArgs(2, 2, 2, from, end, 1).
+		WillReturnRows(mock.NewRows([]string{"period", "count"}).AddRow("2026-01-06", 2))
+
+	stats, err := uc.Stats(newContext(2), q)
+	assert.Nil(t, err)
+	assert.Equal(t, &task.Stats{
+		Groups: []task.Group{{
+			Status: sql.NullString{String: "done", Valid: true},
+			Period: sql.NullString{String: "2026-01-06", Valid: true},
+			Count:  2,
+		}},
+		Series: []task.Point{
+			{Period: "2026-01-05", Created: 3},
+			{Period: "2026-01-06", Completed: 2},
+			{Period: "2026-01-07", Created: 1},
+		},
+	}, stats)
+	assert.Nil(t, mock.ExpectationsWereMet())
+
+	invalid := map[string]task.StatsQuery{
+		"Unknown dimension":    {By: []string{"t.title"}, From: from, To: from, Interval: "day"},
+		"Repeated dimension":   {By: []string{task.ByStatus, task.ByStatus}, From: from, To: from, Interval: "day"},
+		"Two periods":          {By: []string{task.ByCreatedDay, task.ByUpdatedWeek}, From: from, To: from, Interval: "day"},
+		"Unknown interval":     {From: from, To: from, Interval: "month"},
+		"Range reversed":       {From: from, To: from.AddDate(0, 0, -1), Interval: "day"},
+		"Range over the limit": {From: from, To: from.AddDate(0, 0, maxStatsDays), Interval: "day"},
+	}
+
+	for name, q := range invalid {
+		_, err := uc.Stats(newContext(2), q)
+		assert.Equal(t, errorMsg.ErrInvalidRequestData, err, name)
+	}
+}
+
+func TestSeries(t *testing.T) {
+	// A Wednesday to the Tuesday after the next.
+	from := time.Date(2026, 1, 7, 0, 0, 0, 0, time.Local)
+	q := task.StatsQuery{From: from, To: from.AddDate(0, 0, 13), Interval: "week"}
+
+	got := series(q, []task.Group{{Period: sql.NullString{String: "2026-01-12", Valid: true}, Count: 4}}, nil)
+	assert.Equal(t, []task.Point{
+		{Period: "2026-01-05"},
+		{Period: "2026-01-12", Created: 4},
+		{Period: "2026-01-19"},
+	}, got, "weeks start on the Monday of the first day")
+}
+
+func TestTaskUseCase_Share(t *testing.T) {
+	logger := logger.New()
+	db, mock := database.NewSqlxMock(t)
+	cacheMock := cache.NewMock(t)
+	r := repository.New(db)
+	uc := New(r, persistence.NewTxManager(db, sql.LevelDefault, 0), logger, cacheMock, time.Minute, 0)
+	defer db.Close()
+
+	type test struct {
+		name       string
+		share      *task.Share
+		beforeTest func()
+		err        error
+	}
+
+	tests := []test{
+		{
+			name:  "Success",
+			share: &task.Share{TaskID: 1, UserID: 2, Permission: task.PermissionRead},
+			beforeTest: func() {
+				mock.ExpectBegin()
+				mock.ExpectQuery("SELECT CASE (.+) FROM tasks t").WithArgs(1, 1, 1, 1, 1).WillReturnRows(mock.NewRows([]string{"case"}).AddRow(task.PermissionOwner))
+				mock.ExpectExec("INSERT INTO task_shares").WithArgs(task.PermissionRead, 1, 1, 2).WillReturnResult(sqlxmock.NewResult(0, 1))
+				mock.ExpectCommit()
+			},
+		},
+		{
+			name:       "Fail - Invalid permission",
+			share:      &task.Share{TaskID: 1, UserID: 2, Permission: task.PermissionOwner},
+			beforeTest: func() {},
+			err:        errorMsg.ErrInvalidRequestData,
+		},
+		{
+			name:  "Fail - Only owners can share",
+			share: &task.Share{TaskID: 1, UserID: 2, Permission: task.PermissionRead},
+			beforeTest: func() {
+				mock.ExpectBegin()
+				mock.ExpectQuery("SELECT CASE (.+) FROM tasks t").WithArgs(1, 1, 1, 1, 1).WillReturnRows(mock.NewRows([]string{"case"}).AddRow(task.PermissionWrite))
+				mock.ExpectRollback()
+			},
+			err: errorMsg.ErrForbidden,
+		},
+		{
+			name:  "Fail - Not a member of the workspace",
+			share: &task.Share{TaskID: 1, UserID: 9, Permission: task.PermissionWrite},
+			beforeTest: func() {
+				mock.ExpectBegin()
+				mock.ExpectQuery("SELECT CASE (.+) FROM tasks t").WithArgs(1, 1, 1, 1, 1).WillReturnRows(mock.NewRows([]string{"case"}).AddRow(task.PermissionOwner))
+				mock.ExpectExec("INSERT INTO task_shares").WithArgs(task.PermissionWrite, 1, 1, 9).WillReturnResult(sqlxmock.NewResult(0, 0))
+				mock.ExpectRollback()
+			},
+			err: errorMsg.ErrNotWorkspaceMember,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.beforeTest()
+
+			err := uc.Share(newContext(1), tt.share)
+			assert.Equal(t, tt.err, err)
+		})
+	}
+}
+
+func TestTaskUseCase_FindAssignedAndShared(t *testing.T) {
+	logger := logger.New()
+	db, mock := database.NewSqlxMock(t)
+	cacheMock := cache.NewMock(t)
+	r := repository.New(db)
+	uc := New(r, persistence.NewTxManager(db, sql.LevelDefault, 0), logger, cacheMock, time.Minute, 0)
+	defer db.Close()
+
+	mock.ExpectQuery(`FROM tasks t WHERE \(EXISTS \(SELECT 1 FROM task_assignees a WHERE a.task_id = t.id AND a.user_id = \$1\)\) AND t.workspace_id = \$2 ORDER BY t.id`).
+		WithArgs(2, 1).
+		WillReturnRows(mock.NewRows([]string{"id", "title", "description"}).AddRow(1, "Assigned", nil))
+
+	ts, err := uc.FindAssigned(newContext(2))
+	assert.Nil(t, err)
+	assert.Equal(t, []task.Schema{{ID: 1, Title: "Assigned", Description: schema.Null[string]()}}, ts)
+
+	mock.ExpectQuery(`FROM tasks t WHERE \(EXISTS \(SELECT 1 FROM task_shares s WHERE s.task_id = t.id AND s.user_id = \$1\)\) AND t.workspace_id = \$2 ORDER BY t.id`).
+		WithArgs(2, 1).
+		WillReturnRows(mock.NewRows([]string{"id", "title", "description"}).AddRow(2, "Shared", nil))
+
+	ts, err = uc.FindShared(newContext(2))
+	assert.Nil(t, err)
+	assert.Equal(t, []task.Schema{{ID: 2, Title: "Shared", Description: schema.Null[string]()}}, ts)
+
+	_, err = uc.FindAssigned(tenant.NewContext(context.TODO(), 1))
+	assert.Equal(t, errorMsg.ErrUnauthorized, err)
+}
+
+func TestTaskUseCase_Cache(t *testing.T) {
+	logger := logger.New()
+	db, mock := database.NewSqlxMock(t)
+	cacheMock := cache.NewMock(t)
+	r := repository.New(db)
+	uc := New(r, persistence.NewTxManager(db, sql.LevelDefault, 0), logger, cacheMock, time.Minute, 0)
+	defer db.Close()
+
+	findOne := `SELECT t.\* FROM tasks t WHERE \(t.id = \$1 AND`
+	rows := func(title string) *sqlxmock.Rows {
+		return mock.NewRows([]string{"id", "title", "description"}).AddRow(1, title, nil)
+	}
+
+	t.Run("Miss then hit", func(t *testing.T) {
+		mock.ExpectQuery(findOne).WillReturnRows(rows("Test"))
+
+		for i := 0; i < 2; i++ {
+			got, err := uc.FindOne(newContext(1), 1)
+			assert.Nil(t, err)
+			assert.Equal(t, &task.Schema{ID: 1, Title: "Test", Description: schema.Null[string]()}, got)
+		}
+
+		assert.Nil(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Entries are per user", func(t *testing.T) {
+		mock.ExpectQuery(`SELECT t.\* FROM tasks t WHERE \(t.id = \$1 AND`).WithArgs(1, 2, 2, 2, 1).WillReturnError(sql.ErrNoRows)
+
+		_, err := uc.FindOne(newContext(2), 1)
+		assert.Equal(t, sql.ErrNoRows, err)
+		assert.Nil(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Entries expire", func(t *testing.T) {
+		version, err := cacheMock.Get(context.TODO(), taskVersionKey(1, 1))
+		assert.Nil(t, err)
+
+		ttl, err := cacheMock.TTL(context.TODO(), taskKey(1, 1, string(version), 1))
+		assert.Nil(t, err)
+		assert.Equal(t, time.Minute, ttl)
+	})
+
+	t.Run("Update invalidates", func(t *testing.T) {
+		mock.ExpectBegin()
+		mock.ExpectQuery("SELECT CASE (.+) FROM tasks t").WillReturnRows(mock.NewRows([]string{"case"}).AddRow(task.PermissionOwner))
+		mock.ExpectExec("UPDATE tasks").WillReturnResult(sqlxmock.NewResult(0, 1))
+		mock.ExpectCommit()
+		mock.ExpectQuery(findOne).WillReturnRows(rows("Updated"))
+
+		err := uc.Update(newContext(1), &task.Schema{ID: 1, Title: "Updated"})
+		assert.Nil(t, err)
+
+		got, err := uc.FindOne(newContext(1), 1)
+		assert.Nil(t, err)
+		assert.Equal(t, "Updated", got.Title)
+		assert.Nil(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Failed writes keep the cache", func(t *testing.T) {
+		mock.ExpectBegin()
+		mock.ExpectQuery("SELECT CASE (.+) FROM tasks t").WillReturnRows(mock.NewRows([]string{"case"}).AddRow(task.PermissionRead))
+		mock.ExpectRollback()
+
+		err := uc.Update(newContext(1), &task.Schema{ID: 1, Title: "Forbidden"})
+		assert.Equal(t, errorMsg.ErrForbidden, err)
+
+		got, err := uc.FindOne(newContext(1), 1)
+		assert.Nil(t, err)
+		assert.Equal(t, "Updated", got.Title)
+		assert.Nil(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Delete invalidates", func(t *testing.T) {
+		mock.ExpectBegin()
+		mock.ExpectQuery("SELECT CASE (.+) FROM tasks t").WillReturnRows(mock.NewRows([]string{"case"}).AddRow(task.PermissionOwner))
+		mock.ExpectExec("DELETE FROM tasks").WillReturnResult(sqlxmock.NewResult(0, 1))
+		mock.ExpectCommit()
+		mock.ExpectQuery(findOne).WillReturnError(sql.ErrNoRows)
+
+		err := uc.Delete(newContext(1), 1)
+		assert.Nil(t, err)
+
+		_, err = uc.FindOne(newContext(1), 1)
+		assert.Equal(t, sql.ErrNoRows, err)
+		assert.Nil(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Lists are invalidated by any write in the workspace", func(t *testing.T) {
+		assigned := `FROM tasks t WHERE \(EXISTS \(SELECT 1 FROM task_assignees a`
+		mock.ExpectQuery(assigned).WillReturnRows(mock.NewRows([]string{"id", "title", "description"}).AddRow(2, "Assigned", nil))
+
+		for i := 0; i < 2; i++ {
+			ts, err := uc.FindAssigned(newContext(1))
+			assert.Nil(t, err)
+			assert.Len(t, ts, 1)
+		}
+
+		mock.ExpectBegin()
+		mock.ExpectQuery("SELECT CASE (.+) FROM tasks t").WillReturnRows(mock.NewRows([]string{"case"}).AddRow(task.PermissionOwner))
+		mock.ExpectExec("SAVEPOINT sp_1").WillReturnResult(sqlxmock.NewResult(0, 0))
+		mock.ExpectExec("DELETE FROM task_assignees").WillReturnResult(sqlxmock.NewResult(0, 1))
+		mock.ExpectExec("RELEASE SAVEPOINT sp_1").WillReturnResult(sqlxmock.NewResult(0, 0))
+		mock.ExpectCommit()
+		mock.ExpectQuery(assigned).WillReturnRows(mock.NewRows([]string{"id", "title"}))
+
+		err := uc.SetAssignees(newContext(1), 2, nil)
+		assert.Nil(t, err)
+
+		ts, err := uc.FindAssigned(newContext(1))
+		assert.Nil(t, err)
+		assert.Len(t, ts, 0)
+		assert.Nil(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestTaskUseCase_CacheCoalescing(t *testing.T) {
+	logger := logger.New()
+	db, mock := database.NewSqlxMock(t)
+	r := repository.New(db)
+	uc := New(r, persistence.NewTxManager(db, sql.LevelDefault, 0), logger, cache.NewMock(t), time.Minute, 0)
+	defer db.Close()
+
+	mock.ExpectQuery(`SELECT t.\* FROM tasks t WHERE \(t.id = \$1 AND`).
+		WillDelayFor(100 * time.Millisecond).
+		WillReturnRows(mock.NewRows([]string{"id", "title", "description"}).AddRow(1, "Test", nil))
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			got, err := uc.FindOne(newContext(1), 1)
+			assert.Nil(t, err)
+			assert.Equal(t, &task.Schema{ID: 1, Title: "Test", Description: schema.Null[string]()}, got)
+		}()
+	}
+
+	wg.Wait()
+	assert.Nil(t, mock.ExpectationsWereMet(), "concurrent misses share one query")
+}
+
+func TestTaskUseCase_CacheStale(t *testing.T) {
+	logger := logger.New()
+	db, mock := database.NewSqlxMock(t)
+	r := repository.New(db)
+	uc := New(r, persistence.NewTxManager(db, sql.LevelDefault, 0), logger, cache.NewMock(t), time.Minute, time.Minute)
+	defer db.Close()
+
+	now := time.Now()
+	uc.now = func() time.Time { return now }
+	uc.random = func() float64 { return 0 }
+
+	findOne := `SELECT t.\* FROM tasks t WHERE \(t.id = \$1 AND`
+	rows := func(title string) *sqlxmock.Rows {
+		return mock.NewRows([]string{"id", "title", "description"}).AddRow(1, title, nil)
+	}
+
+	mock.ExpectQuery(findOne).WillReturnRows(rows("Test"))
+	_, err := uc.FindOne(newContext(1), 1)
+	assert.Nil(t, err)
+
+	now = now.Add(59 * time.Second)
+	got, err := uc.FindOne(newContext(1), 1)
+	assert.Nil(t, err)
+	assert.Equal(t, "Test", got.Title)
+	uc.refreshes.Wait()
+	assert.Nil(t, mock.ExpectationsWereMet(), "fresh entries are not refreshed")
+
+	now = now.Add(2 * time.Second)
+	mock.ExpectQuery(findOne).WillReturnRows(rows("Refreshed"))
+
+	got, err = uc.FindOne(newContext(1), 1)
+	assert.Nil(t, err)
+	assert.Equal(t, "Test", got.Title, "expired entries are served while they are refreshed")
+
+	uc.refreshes.Wait()
+	assert.Nil(t, mock.ExpectationsWereMet())
+
+	got, err = uc.FindOne(newContext(1), 1)
+	assert.Nil(t, err)
+	assert.Equal(t, "Refreshed", got.Title)
+}
+
+func TestTaskUseCase_CacheEarlyExpiration(t *testing.T) {
+	logger := logger.New()
+	db, mock := database.NewSqlxMock(t)
+	r := repository.New(db)
+	uc := New(r, persistence.NewTxManager(db, sql.LevelDefault, 0), logger, cache.NewMock(t), time.Minute, time.Minute)
+	defer db.Close()
+
+	now := time.Now()
+	uc.now = func() time.Time { return now }
+
+	findOne := `SELECT t.\* FROM tasks t WHERE \(t.id = \$1 AND`
+	mock.ExpectQuery(findOne).WillReturnRows(mock.NewRows([]string{"id", "title", "description"}).AddRow(1, "Test", nil))
+
+	// The query takes a second of the fake clock, so an unlucky draw of
+	// -ln(1-0.99999) * 1s, about 11.5s, refreshes entries 11.5s early.
+	uc.repository = slowRepository{ITask: r, advance: func() { now = now.Add(time.Second) }}
+	_, err := uc.FindOne(newContext(1), 1)
+	assert.Nil(t, err)
+
+	uc.random = func() float64 { return 0.99999 }
+	now = now.Add(47 * time.Second)
+	_, err = uc.FindOne(newContext(1), 1)
+	assert.Nil(t, err)
+	uc.refreshes.Wait()
+	assert.Nil(t, mock.ExpectationsWereMet(), "entries are not refreshed too early")
+
+	now = now.Add(2 * time.Second)
+	mock.ExpectQuery(findOne).WillReturnRows(mock.NewRows([]string{"id", "title", "description"}).AddRow(1, "Test", nil))
+	_, err = uc.FindOne(newContext(1), 1)
+	assert.Nil(t, err)
+	uc.refreshes.Wait()
+	assert.Nil(t, mock.ExpectationsWereMet(), "entries near their end are refreshed early")
+}
+
+type slowRepository struct {
+	repository.ITask
+	advance func()
+}
+
+func (r slowRepository) FindOne(ctx context.Context, params schema.QueryParams) (*task.Schema, error) {
+	r.advance()
+	return r.ITask.FindOne(ctx, params)
+}
+
+func TestTaskUseCase_Evict(t *testing.T) {
+	logger := logger.New()
+	db, mock := database.NewSqlxMock(t)
+	r := repository.New(db)
+	uc := New(r, persistence.NewTxManager(db, sql.LevelDefault, 0), logger, cache.NewMemory(0, 0), time.Minute, 0)
+	defer db.Close()
+
+	findOne := `SELECT t.\* FROM tasks t WHERE \(t.id = \$1 AND`
+	rows := func() *sqlxmock.Rows {
+		return mock.NewRows([]string{"id", "title", "description"}).AddRow(1, "Test", nil)
+	}
+
+	mock.ExpectQuery(findOne).WillReturnRows(rows())
+	_, err := uc.FindOne(newContext(1), 1)
+	assert.Nil(t, err)
+
+	workspaceID := uint64(2)
+	uc.Evict(context.TODO(), task.Change{WorkspaceID: &workspaceID, TaskID: 1})
+	uc.Evict(context.TODO(), task.Change{TaskID: 1})
+	_, err = uc.FindOne(newContext(1), 1)
+	assert.Nil(t, err)
+	assert.Nil(t, mock.ExpectationsWereMet(), "changes elsewhere keep the cache")
+
+	workspaceID = 1
+	uc.Evict(context.TODO(), task.Change{WorkspaceID: &workspaceID, TaskID: 1})
+	mock.ExpectQuery(findOne).WillReturnRows(rows())
+	_, err = uc.FindOne(newContext(1), 1)
+	assert.Nil(t, err)
+	assert.Nil(t, mock.ExpectationsWereMet())
+
+	uc.EvictAll(context.TODO())
+	mock.ExpectQuery(findOne).WillReturnRows(rows())
+	_, err = uc.FindOne(newContext(1), 1)
+	assert.Nil(t, err)
+	assert.Nil(t, mock.ExpectationsWereMet())
+}
+
+func TestTaskUseCase_CacheUnavailable(t *testing.T) {
+	logger := logger.New()
+	db, mock := database.NewSqlxMock(t)
+	client := redis.NewClient(&redis.Options{Addr: "127.0.0.1:0"})
+	client.Close()
+	r := repository.New(db)
+	uc := New(r, persistence.NewTxManager(db, sql.LevelDefault, 0), logger, cache.NewRedis(client), time.Minute, 0)
+	defer db.Close()
+
+	mock.ExpectQuery(`SELECT t.\* FROM tasks t WHERE \(t.id = \$1 AND`).WillReturnRows(mock.NewRows([]string{"id", "title", "description"}).AddRow(1, "Test", nil))
+
+	got, err := uc.FindOne(newContext(1), 1)
+	assert.Nil(t, err)
+	assert.Equal(t, &task.Schema{ID: 1, Title: "Test", Description: schema.Null[string]()}, got)
+	assert.Nil(t, mock.ExpectationsWereMet())
+}
+
+func TestTaskUseCase_FindOne_LaggingReplica(t *testing.T) {
+	primary, replica := database.NewSqlxSqlite(t), database.NewSqlxSqlite(t)
+	ctx := newContext(1)
+
+	for _, db := range []*sqlx.DB{primary, replica} {
+		_, err := repository.New(db).Create(ctx, &task.Schema{Title: "Old"})
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	replicas := persistence.NewReplicas(primary, replica)
+	replicas.Check(ctx, logger.New())
+
+	r := repository.New(primary)
+	r.ReadFrom(replicas)
+	uc := New(r, persistence.NewTxManager(primary, sql.LevelDefault, 0), logger.New(), cache.NewMemory(0, 0), time.Minute, time.Minute)
+
+	got, err := uc.FindOne(ctx, 1)
+	assert.Nil(t, err)
+	assert.Equal(t, "Old", got.Title)
+
+	err = uc.Update(ctx, &task.Schema{ID: 1, Title: "New"})
+	assert.Nil(t, err)
+
+	stale, err := r.FindOne(ctx, schema.QueryParams{Where: "t.id = ?", Args: []any{1}})
+	assert.Nil(t, err)
+	assert.Equal(t, "Old", stale.Title, "the replica has not caught up")
+
+	for i := 0; i < 2; i++ {
+		got, err = uc.FindOne(ctx, 1)
+		assert.Nil(t, err)
+		assert.Equal(t, "New", got.Title, "the cache is filled from the primary")
+	}
+}
+
+func TestTaskUseCase_Import(t *testing.T) {
+	db := database.NewSqlxSqlite(t)
+	db.MustExec(`INSERT INTO users (name, email, password) VALUES ('Owner', 'owner@test.com', '')`)
+	r := repository.New(db)
+	uc := New(r, persistence.NewTxManager(db, sql.LevelDefault, 0), logger.New(), cache.NewMemory(0, 0), time.Minute, 0)
+	ctx := newContext(1)
+
+	next := func(tasks ...task.Schema) func() (*task.Schema, error) {
+		return func() (*task.Schema, error) {
+			if len(tasks) == 0 {
+				return nil, nil
+			}
+
+			t := tasks[0]
+			tasks = tasks[1:]
+			return &t, nil
+		}
+	}
+
+	imported, err := uc.Import(ctx, next(task.Schema{Title: "Bad", Status: "lost"}))
+	assert.Equal(t, errorMsg.ErrInvalidRequestData, err)
+	assert.Equal(t, int64(0), imported)
+
+	imported, err = uc.Import(ctx, next(
+		task.Schema{Title: "First", Description: schema.Some("Imported")},
+		task.Schema{Title: "Second", Status: task.StatusDone},
+	))
+	assert.Nil(t, err)
+	assert.Equal(t, int64(2), imported)
+
+	tasks, err := r.FindMany(ctx, schema.QueryParams{Where: "t.user_id = ?", Args: []any{1}, OrderBy: "t.id"})
+	assert.Nil(t, err)
+	if assert.Len(t, tasks, 2) {
+		assert.Equal(t, "First", tasks[0].Title)
+		assert.Equal(t, schema.Some("Imported"), tasks[0].Description)
+		assert.Equal(t, task.StatusTodo, tasks[0].Status)
+		assert.False(t, tasks[0].CompletedAt.Valid)
+		assert.Equal(t, task.StatusDone, tasks[1].Status)
+		assert.True(t, tasks[1].CompletedAt.Valid)
+		assert.Equal(t, uint64(1), tasks[1].WorkspaceID)
+	}
+}
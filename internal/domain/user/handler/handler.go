@@ -0,0 +1,58 @@
+package handler
+
+import (
+	"encoding/json"
+	"log/slog"
+	"net/http"
+
+	"github.com/henriqueassiss/advanced-golang-api/internal/domain/user"
+	"github.com/henriqueassiss/advanced-golang-api/internal/domain/user/useCase"
+	"github.com/henriqueassiss/advanced-golang-api/internal/utils/errorMsg"
+	"github.com/henriqueassiss/advanced-golang-api/internal/utils/reqRes"
+)
+
+type IUser struct {
+	useCase useCase.IUser
+	logger  *slog.Logger
+}
+
+func NewHandler(useCase useCase.IUser, logger *slog.Logger) *IUser {
+	return &IUser{
+		useCase: useCase,
+		logger:  logger,
+	}
+}
+
+func (h *IUser) Create(w http.ResponseWriter, r *http.Request) {
+	var req Create
+	err := json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		reqRes.Error(h.logger, w, http.StatusBadRequest, err, nil)
+		return
+	}
+
+	if req.Name == "" ||
+		req.Email == "" ||
+		len(req.Password) < 8 {
+		reqRes.Error(h.logger, w, http.StatusBadRequest, errorMsg.ErrInvalidRequestData, req.Email)
+		return
+	}
+
+	u := user.Schema{
+		Name:     req.Name,
+		Email:    req.Email,
+		Password: req.Password,
+	}
+
+	err = h.useCase.Create(r.Context(), &u)
+	if err != nil {
+		reqRes.Error(h.logger, w, http.StatusInternalServerError, err, u.Email)
+		return
+	}
+
+	reqRes.Json(w, http.StatusOK, SingleUser{
+		ID:    u.ID,
+		Name:  u.Name,
+		Email: u.Email,
+	})
+}
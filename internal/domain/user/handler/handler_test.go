@@ -0,0 +1,132 @@
+package handler
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/henriqueassiss/advanced-golang-api/internal/domain/user"
+	"github.com/henriqueassiss/advanced-golang-api/internal/domain/user/useCase"
+	"github.com/henriqueassiss/advanced-golang-api/internal/utils/reqRes"
+
+	"github.com/henriqueassiss/advanced-golang-api/third_party/logger"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUserHandler_Create(t *testing.T) {
+	logger := logger.New()
+
+	type args struct {
+		req *Create
+	}
+
+	type want struct {
+		status   int
+		response *reqRes.GenericResponse[*SingleUser]
+		err      error
+	}
+
+	type test struct {
+		name string
+		args
+		want
+	}
+
+	tests := []test{
+		{
+			name: "Success",
+			args: args{
+				req: &Create{
+					Name:     "Test",
+					Email:    "test@test.com",
+					Password: "12345678",
+				},
+			},
+			want: want{
+				status: http.StatusOK,
+				response: &reqRes.GenericResponse[*SingleUser]{
+					Success: true,
+					Status:  http.StatusOK,
+					Data: &SingleUser{
+						ID:    1,
+						Name:  "Test",
+						Email: "test@test.com",
+					},
+				},
+			},
+		},
+		{
+			name: "Fail - Short password",
+			args: args{
+				req: &Create{
+					Name:     "Test",
+					Email:    "test@test.com",
+					Password: "123",
+				},
+			},
+			want: want{
+				status: http.StatusBadRequest,
+				response: &reqRes.GenericResponse[*SingleUser]{
+					Success: false,
+					Status:  http.StatusBadRequest,
+				},
+			},
+		},
+		{
+			name: "Fail - Simulating internal error",
+			args: args{
+				req: &Create{
+					Name:     "Test",
+					Email:    "test@test.com",
+					Password: "12345678",
+				},
+			},
+			want: want{
+				status: http.StatusInternalServerError,
+				response: &reqRes.GenericResponse[*SingleUser]{
+					Success: false,
+					Status:  http.StatusInternalServerError,
+				},
+				err: errors.New("some error"),
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			err := json.NewEncoder(&buf).Encode(tt.args.req)
+			assert.Nil(t, err)
+
+			r := httptest.NewRequest(http.MethodPost, "/v1/user", &buf)
+			w := httptest.NewRecorder()
+
+			uc := &useCase.UserMock{
+				CreateFunc: func(ctx context.Context, u *user.Schema) error {
+					u.ID = 1
+					return tt.want.err
+				},
+			}
+
+			router := chi.NewRouter()
+			h := RegisterHTTPEndPoints(uc, logger, router)
+			h.Create(w, r)
+
+			assert.Equal(t, tt.status, w.Code)
+
+			var got reqRes.GenericResponse[*SingleUser]
+			err = json.NewDecoder(w.Body).Decode(&got)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			assert.Equal(t, *tt.want.response, got)
+		})
+	}
+}
@@ -0,0 +1,18 @@
+package handler
+
+import (
+	"log/slog"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/henriqueassiss/advanced-golang-api/internal/domain/user/useCase"
+)
+
+func RegisterHTTPEndPoints(u useCase.IUser, logger *slog.Logger, router *chi.Mux, middlewares ...func(http.Handler) http.Handler) *IUser {
+	handler := NewHandler(u, logger)
+	router.Route("/v1/user", func(router chi.Router) {
+		router.Use(middlewares...)
+		router.Post("/", handler.Create)
+	})
+	return handler
+}
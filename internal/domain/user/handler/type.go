@@ -0,0 +1,16 @@
+package handler
+
+import "time"
+
+type SingleUser struct {
+	ID        uint64     `json:"id"`
+	Name      string     `json:"name"`
+	Email     string     `json:"email"`
+	UpdatedAt *time.Time `json:"updatedAt"`
+}
+
+type Create struct {
+	Name     string `json:"name"`
+	Email    string `json:"email"`
+	Password string `json:"password"`
+}
@@ -0,0 +1,15 @@
+package repository
+
+var (
+	SelectByEmail = `SELECT u.* FROM users u WHERE u.email = ?`
+
+	SelectByIdentity = `SELECT u.* FROM users u
+	JOIN user_identities i ON i.user_id = u.id
+	WHERE i.issuer = ? AND i.subject = ?`
+
+	// InsertWithoutPassword creates users that only sign in through an identity
+	// provider. An empty hash never matches any password.
+	InsertWithoutPassword = `INSERT INTO users (name, email, password) VALUES (?, ?, '')`
+
+	InsertIdentity = `INSERT INTO user_identities (issuer, subject, user_id) VALUES (?, ?, ?)`
+)
@@ -0,0 +1,79 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/henriqueassiss/advanced-golang-api/internal/domain/user"
+	"github.com/henriqueassiss/advanced-golang-api/internal/utils/persistence"
+	"github.com/henriqueassiss/advanced-golang-api/internal/utils/schema"
+	"github.com/henriqueassiss/advanced-golang-api/third_party/database"
+
+	"github.com/jmoiron/sqlx"
+)
+
+type IUser interface {
+	FindOne(ctx context.Context, params schema.QueryParams) (*user.Schema, error)
+	FindByEmail(ctx context.Context, email string) (*user.Schema, error)
+	FindByIdentity(ctx context.Context, issuer, subject string) (*user.Schema, error)
+	Create(ctx context.Context, u *user.Schema) (*user.Schema, error)
+	CreateWithIdentity(ctx context.Context, u *user.Schema, i *user.Identity) error
+	SaveIdentity(ctx context.Context, i *user.Identity) error
+}
+
+type User struct {
+	*persistence.Repository[user.Schema]
+	db      *sqlx.DB
+	dialect database.Dialect
+	tx      *persistence.TxManager
+}
+
+func New(db *sqlx.DB) *User {
+	return &User{
+		Repository: persistence.New[user.Schema](db, persistence.Table{
+			Name:    "users",
+			Alias:   "u",
+			Touched: "updated_at",
+		}),
+		db:      db,
+		dialect: database.DialectOf(db),
+		tx:      persistence.NewTxManager(db, sql.LevelDefault, 0),
+	}
+}
+
+func (r *User) FindByEmail(ctx context.Context, email string) (*user.Schema, error) {
+	var u user.Schema
+	err := persistence.Conn(ctx, r.db).GetContext(ctx, &u, r.dialect.Rebind(SelectByEmail), email)
+
+	return &u, err
+}
+
+func (r *User) FindByIdentity(ctx context.Context, issuer, subject string) (*user.Schema, error) {
+	var u user.Schema
+	err := persistence.Conn(ctx, r.db).GetContext(ctx, &u, r.dialect.Rebind(SelectByIdentity), issuer, subject)
+
+	return &u, err
+}
+
+// CreateWithIdentity creates a user without a password together with its link
+// to the identity provider.
+func (r *User) CreateWithIdentity(ctx context.Context, u *user.Schema, i *user.Identity) error {
+	return r.tx.Run(ctx, func(ctx context.Context) error {
+		tx := persistence.Conn(ctx, r.db)
+
+		id, err := persistence.Insert(ctx, r.db, "id", InsertWithoutPassword, u.Name, u.Email)
+		if err != nil {
+			return err
+		}
+
+		u.ID, i.UserID = id, id
+		_, err = tx.ExecContext(ctx, r.dialect.Rebind(InsertIdentity), i.Issuer, i.Subject, i.UserID)
+
+		return err
+	})
+}
+
+func (r *User) SaveIdentity(ctx context.Context, i *user.Identity) error {
+	_, err := persistence.Conn(ctx, r.db).ExecContext(ctx, r.dialect.Rebind(InsertIdentity), i.Issuer, i.Subject, i.UserID)
+	return err
+}
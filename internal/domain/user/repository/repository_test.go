@@ -0,0 +1,161 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	"github.com/henriqueassiss/advanced-golang-api/internal/domain/user"
+	"github.com/henriqueassiss/advanced-golang-api/third_party/database"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUserRepository_FindByEmail(t *testing.T) {
+	db, mock := database.NewSqlxMock(t)
+	r := New(db)
+	defer db.Close()
+
+	type args struct {
+		ctx   context.Context
+		email string
+	}
+
+	type want struct {
+		u   *user.Schema
+		err error
+	}
+
+	type test struct {
+		name string
+		args
+		beforeTest func()
+		want
+	}
+
+	tests := []test{
+		{
+			name: "Success",
+			args: args{
+				ctx:   context.TODO(),
+				email: "test@test.com",
+			},
+			beforeTest: func() {
+				rows := mock.NewRows([]string{"id", "name", "email", "password"}).
+					AddRow(1, "Test", "test@test.com", "hash")
+				mock.ExpectQuery("SELECT u.\\* FROM users u WHERE u.email =").
+					WithArgs("test@test.com").
+					WillReturnRows(rows)
+			},
+			want: want{
+				u: &user.Schema{
+					ID:       1,
+					Name:     "Test",
+					Email:    "test@test.com",
+					Password: "hash",
+				},
+			},
+		},
+		{
+			name: "Fail - Non-existent email",
+			args: args{
+				ctx:   context.TODO(),
+				email: "none@test.com",
+			},
+			beforeTest: func() {
+				mock.ExpectQuery("SELECT u.\\* FROM users u WHERE u.email =").
+					WithArgs("none@test.com").
+					WillReturnError(sql.ErrNoRows)
+			},
+			want: want{
+				u:   &user.Schema{},
+				err: sql.ErrNoRows,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.beforeTest()
+
+			got, err := r.FindByEmail(tt.args.ctx, tt.args.email)
+			assert.Equal(t, tt.want.err, err)
+			assert.Equal(t, tt.want.u, got)
+		})
+	}
+}
+
+func TestUserRepository_Create(t *testing.T) {
+	db, mock := database.NewSqlxMock(t)
+	r := New(db)
+	defer db.Close()
+
+	type args struct {
+		ctx context.Context
+		u   *user.Schema
+	}
+
+	type want struct {
+		id  uint64
+		err error
+	}
+
+	type test struct {
+		name string
+		args
+		beforeTest func()
+		want
+	}
+
+	tests := []test{
+		{
+			name: "Success",
+			args: args{
+				ctx: context.TODO(),
+				u: &user.Schema{
+					Name:     "Test",
+					Email:    "test@test.com",
+					Password: "hash",
+				},
+			},
+			beforeTest: func() {
+				rows := mock.NewRows([]string{"id"}).AddRow(1)
+				mock.ExpectQuery("INSERT INTO users").WillReturnRows(rows)
+			},
+			want: want{
+				id: 1,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.beforeTest()
+
+			created, err := r.Create(tt.args.ctx, tt.args.u)
+			assert.Equal(t, tt.want.err, err)
+			assert.Equal(t, tt.want.id, created.ID)
+			assert.Equal(t, tt.want.id, tt.args.u.ID)
+		})
+	}
+}
+
+func TestUserRepository_SQLite(t *testing.T) {
+	db := database.NewSqlxSqlite(t)
+	r := New(db)
+
+	created, err := r.Create(context.TODO(), &user.Schema{Name: "Test", Email: "test@test.com", Password: "hash"})
+	assert.Nil(t, err)
+	assert.Equal(t, uint64(1), created.ID)
+	assert.True(t, created.UpdatedAt.Valid)
+
+	u := &user.Schema{Name: "SSO", Email: "sso@test.com"}
+	i := &user.Identity{Issuer: "https://sso", Subject: "abc"}
+	err = r.CreateWithIdentity(context.TODO(), u, i)
+	assert.Nil(t, err)
+	assert.Equal(t, uint64(2), u.ID)
+	assert.Equal(t, uint64(2), i.UserID)
+
+	got, err := r.FindByIdentity(context.TODO(), "https://sso", "abc")
+	assert.Nil(t, err)
+	assert.Equal(t, "sso@test.com", got.Email)
+}
@@ -0,0 +1,18 @@
+package user
+
+import "database/sql"
+
+type Schema struct {
+	ID        uint64       `db:"id,pk,omitempty"`
+	Name      string       `db:"name,omitempty"`
+	Email     string       `db:"email,omitempty"`
+	Password  string       `db:"password,omitempty"`
+	UpdatedAt sql.NullTime `db:"updated_at,readonly"`
+}
+
+// Identity links a user to their account at an external identity provider.
+type Identity struct {
+	Issuer  string `db:"issuer"`
+	Subject string `db:"subject"`
+	UserID  uint64 `db:"user_id"`
+}
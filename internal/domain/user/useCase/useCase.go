@@ -0,0 +1,119 @@
+package useCase
+
+import (
+	"context"
+	"database/sql"
+	"log/slog"
+	"strings"
+
+	"github.com/henriqueassiss/advanced-golang-api/internal/domain/user"
+	"github.com/henriqueassiss/advanced-golang-api/internal/domain/user/repository"
+	"github.com/henriqueassiss/advanced-golang-api/internal/utils/errorMsg"
+	"github.com/henriqueassiss/advanced-golang-api/internal/utils/schema"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+type IUser interface {
+	FindOne(ctx context.Context, userID uint64) (*user.Schema, error)
+	Create(ctx context.Context, u *user.Schema) error
+	Authenticate(ctx context.Context, email, password string) (*user.Schema, error)
+	Provision(ctx context.Context, i *user.Identity, name, email string, emailVerified bool) (*user.Schema, error)
+}
+
+type User struct {
+	repository repository.IUser
+	logger     *slog.Logger
+}
+
+func New(repo repository.IUser, logger *slog.Logger) *User {
+	return &User{
+		repository: repo,
+		logger:     logger,
+	}
+}
+
+func (uc *User) FindOne(ctx context.Context, userID uint64) (*user.Schema, error) {
+	return uc.repository.FindOne(ctx, schema.QueryParams{
+		Where: "u.id = ?",
+		Args:  []any{userID},
+	})
+}
+
+func (uc *User) Create(ctx context.Context, u *user.Schema) error {
+	hash, err := bcrypt.GenerateFromPassword([]byte(u.Password), bcrypt.DefaultCost)
+	if err != nil {
+		return err
+	}
+
+	u.Email = strings.ToLower(strings.TrimSpace(u.Email))
+	u.Password = string(hash)
+
+	_, err = uc.repository.Create(ctx, u)
+
+	return err
+}
+
+func (uc *User) Authenticate(ctx context.Context, email, password string) (*user.Schema, error) {
+	u, err := uc.repository.FindByEmail(ctx, strings.ToLower(strings.TrimSpace(email)))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, errorMsg.ErrInvalidCredentials
+		}
+
+		return nil, err
+	}
+
+	err = bcrypt.CompareHashAndPassword([]byte(u.Password), []byte(password))
+	if err != nil {
+		return nil, errorMsg.ErrInvalidCredentials
+	}
+
+	return u, nil
+}
+
+// Provision returns the user behind an identity provider account, creating it
+// on first sign-in. An existing account is only linked by email when the
+// provider vouches for the address, otherwise anyone able to register that
+// email at the provider could take the account over.
+func (uc *User) Provision(ctx context.Context, i *user.Identity, name, email string, emailVerified bool) (*user.Schema, error) {
+	u, err := uc.repository.FindByIdentity(ctx, i.Issuer, i.Subject)
+	if err == nil {
+		i.UserID = u.ID
+		return u, nil
+	}
+
+	if err != sql.ErrNoRows {
+		return nil, err
+	}
+
+	email = strings.ToLower(strings.TrimSpace(email))
+	if email == "" {
+		return nil, errorMsg.ErrInvalidRequestData
+	}
+
+	u, err = uc.repository.FindByEmail(ctx, email)
+	if err == nil {
+		if !emailVerified {
+			return nil, errorMsg.ErrEmailNotVerified
+		}
+
+		i.UserID = u.ID
+		return u, uc.repository.SaveIdentity(ctx, i)
+	}
+
+	if err != sql.ErrNoRows {
+		return nil, err
+	}
+
+	if name == "" {
+		name = email
+	}
+
+	u = &user.Schema{
+		Name:  name,
+		Email: email,
+	}
+
+	return u, uc.repository.CreateWithIdentity(ctx, u, i)
+}
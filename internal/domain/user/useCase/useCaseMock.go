@@ -0,0 +1,30 @@
+package useCase
+
+import (
+	"context"
+
+	"github.com/henriqueassiss/advanced-golang-api/internal/domain/user"
+)
+
+type UserMock struct {
+	FindOneFunc      func(ctx context.Context, userID uint64) (*user.Schema, error)
+	CreateFunc       func(ctx context.Context, u *user.Schema) error
+	AuthenticateFunc func(ctx context.Context, email, password string) (*user.Schema, error)
+	ProvisionFunc    func(ctx context.Context, i *user.Identity, name, email string, emailVerified bool) (*user.Schema, error)
+}
+
+func (uc *UserMock) FindOne(ctx context.Context, userID uint64) (*user.Schema, error) {
+	return uc.FindOneFunc(ctx, userID)
+}
+
+func (uc *UserMock) Create(ctx context.Context, u *user.Schema) error {
+	return uc.CreateFunc(ctx, u)
+}
+
+func (uc *UserMock) Authenticate(ctx context.Context, email, password string) (*user.Schema, error) {
+	return uc.AuthenticateFunc(ctx, email, password)
+}
+
+func (uc *UserMock) Provision(ctx context.Context, i *user.Identity, name, email string, emailVerified bool) (*user.Schema, error) {
+	return uc.ProvisionFunc(ctx, i, name, email, emailVerified)
+}
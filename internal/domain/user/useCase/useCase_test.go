@@ -0,0 +1,224 @@
+package useCase
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	"github.com/henriqueassiss/advanced-golang-api/internal/domain/user"
+	"github.com/henriqueassiss/advanced-golang-api/internal/domain/user/repository"
+	"github.com/henriqueassiss/advanced-golang-api/internal/utils/errorMsg"
+
+	"github.com/henriqueassiss/advanced-golang-api/third_party/database"
+	"github.com/henriqueassiss/advanced-golang-api/third_party/logger"
+
+	"github.com/stretchr/testify/assert"
+	sqlxmock "github.com/zhashkevych/go-sqlxmock"
+	"golang.org/x/crypto/bcrypt"
+)
+
+func TestUserUseCase_Create(t *testing.T) {
+	logger := logger.New()
+	db, mock := database.NewSqlxMock(t)
+	r := repository.New(db)
+	uc := New(r, logger)
+	defer db.Close()
+
+	u := &user.Schema{
+		Name:     "Test",
+		Email:    " Test@Test.com ",
+		Password: "12345678",
+	}
+
+	rows := mock.NewRows([]string{"id"}).AddRow(1)
+	mock.ExpectQuery("INSERT INTO users").WillReturnRows(rows)
+
+	err := uc.Create(context.TODO(), u)
+	assert.Nil(t, err)
+	assert.Equal(t, uint64(1), u.ID)
+	assert.Equal(t, "test@test.com", u.Email)
+	assert.Nil(t, bcrypt.CompareHashAndPassword([]byte(u.Password), []byte("12345678")))
+}
+
+func TestUserUseCase_Authenticate(t *testing.T) {
+	logger := logger.New()
+	db, mock := database.NewSqlxMock(t)
+	r := repository.New(db)
+	uc := New(r, logger)
+	defer db.Close()
+
+	hash, err := bcrypt.GenerateFromPassword([]byte("12345678"), bcrypt.MinCost)
+	assert.Nil(t, err)
+
+	type args struct {
+		ctx      context.Context
+		email    string
+		password string
+	}
+
+	type want struct {
+		id  uint64
+		err error
+	}
+
+	type test struct {
+		name string
+		args
+		beforeTest func()
+		want
+	}
+
+	tests := []test{
+		{
+			name: "Success",
+			args: args{
+				ctx:      context.TODO(),
+				email:    "test@test.com",
+				password: "12345678",
+			},
+			beforeTest: func() {
+				rows := mock.NewRows([]string{"id", "email", "password"}).AddRow(1, "test@test.com", string(hash))
+				mock.ExpectQuery("SELECT (.+) FROM users").WithArgs("test@test.com").WillReturnRows(rows)
+			},
+			want: want{
+				id: 1,
+			},
+		},
+		{
+			name: "Fail - Wrong password",
+			args: args{
+				ctx:      context.TODO(),
+				email:    "test@test.com",
+				password: "wrong-password",
+			},
+			beforeTest: func() {
+				rows := mock.NewRows([]string{"id", "email", "password"}).AddRow(1, "test@test.com", string(hash))
+				mock.ExpectQuery("SELECT (.+) FROM users").WithArgs("test@test.com").WillReturnRows(rows)
+			},
+			want: want{
+				err: errorMsg.ErrInvalidCredentials,
+			},
+		},
+		{
+			name: "Fail - Non-existent email",
+			args: args{
+				ctx:      context.TODO(),
+				email:    "none@test.com",
+				password: "12345678",
+			},
+			beforeTest: func() {
+				mock.ExpectQuery("SELECT (.+) FROM users").WithArgs("none@test.com").WillReturnError(sql.ErrNoRows)
+			},
+			want: want{
+				err: errorMsg.ErrInvalidCredentials,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.beforeTest()
+
+			got, err := uc.Authenticate(tt.args.ctx, tt.args.email, tt.args.password)
+			assert.Equal(t, tt.want.err, err)
+			if tt.want.err == nil {
+				assert.Equal(t, tt.want.id, got.ID)
+			}
+		})
+	}
+}
+
+func TestUserUseCase_Provision(t *testing.T) {
+	logger := logger.New()
+	db, mock := database.NewSqlxMock(t)
+	r := repository.New(db)
+	uc := New(r, logger)
+	defer db.Close()
+
+	type args struct {
+		email         string
+		emailVerified bool
+	}
+
+	type want struct {
+		id  uint64
+		err error
+	}
+
+	type test struct {
+		name string
+		args
+		beforeTest func()
+		want
+	}
+
+	tests := []test{
+		{
+			name: "Success - Known identity",
+			args: args{email: "test@test.com"},
+			beforeTest: func() {
+				rows := mock.NewRows([]string{"id", "email"}).AddRow(1, "test@test.com")
+				mock.ExpectQuery("SELECT u.\\* FROM users u JOIN user_identities i").WithArgs("https://sso", "abc").WillReturnRows(rows)
+			},
+			want: want{id: 1},
+		},
+		{
+			name: "Success - Link verified email",
+			args: args{email: "Test@Test.com", emailVerified: true},
+			beforeTest: func() {
+				mock.ExpectQuery("SELECT u.\\* FROM users u JOIN user_identities i").WithArgs("https://sso", "abc").WillReturnError(sql.ErrNoRows)
+				rows := mock.NewRows([]string{"id", "email"}).AddRow(2, "test@test.com")
+				mock.ExpectQuery("SELECT u.\\* FROM users u WHERE u.email =").WithArgs("test@test.com").WillReturnRows(rows)
+				mock.ExpectExec("INSERT INTO user_identities").WithArgs("https://sso", "abc", 2).WillReturnResult(sqlxmock.NewResult(0, 1))
+			},
+			want: want{id: 2},
+		},
+		{
+			name: "Success - Create user",
+			args: args{email: "new@test.com"},
+			beforeTest: func() {
+				mock.ExpectQuery("SELECT u.\\* FROM users u JOIN user_identities i").WithArgs("https://sso", "abc").WillReturnError(sql.ErrNoRows)
+				mock.ExpectQuery("SELECT u.\\* FROM users u WHERE u.email =").WithArgs("new@test.com").WillReturnError(sql.ErrNoRows)
+				mock.ExpectBegin()
+				mock.ExpectQuery("INSERT INTO users").WithArgs("New", "new@test.com").WillReturnRows(mock.NewRows([]string{"id"}).AddRow(3))
+				mock.ExpectExec("INSERT INTO user_identities").WithArgs("https://sso", "abc", 3).WillReturnResult(sqlxmock.NewResult(0, 1))
+				mock.ExpectCommit()
+			},
+			want: want{id: 3},
+		},
+		{
+			name: "Fail - Unverified email of an existing user",
+			args: args{email: "test@test.com"},
+			beforeTest: func() {
+				mock.ExpectQuery("SELECT u.\\* FROM users u JOIN user_identities i").WithArgs("https://sso", "abc").WillReturnError(sql.ErrNoRows)
+				rows := mock.NewRows([]string{"id", "email"}).AddRow(2, "test@test.com")
+				mock.ExpectQuery("SELECT u.\\* FROM users u WHERE u.email =").WithArgs("test@test.com").WillReturnRows(rows)
+			},
+			want: want{err: errorMsg.ErrEmailNotVerified},
+		},
+		{
+			name: "Fail - No email",
+			args: args{},
+			beforeTest: func() {
+				mock.ExpectQuery("SELECT u.\\* FROM users u JOIN user_identities i").WithArgs("https://sso", "abc").WillReturnError(sql.ErrNoRows)
+			},
+			want: want{err: errorMsg.ErrInvalidRequestData},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.beforeTest()
+
+			i := &user.Identity{Issuer: "https://sso", Subject: "abc"}
+			u, err := uc.Provision(context.TODO(), i, "New", tt.args.email, tt.args.emailVerified)
+			assert.Equal(t, tt.want.err, err)
+			if err == nil {
+				assert.Equal(t, tt.want.id, u.ID)
+				assert.Equal(t, tt.want.id, i.UserID)
+			}
+
+			assert.Nil(t, mock.ExpectationsWereMet())
+		})
+	}
+}
@@ -0,0 +1,143 @@
+package handler
+
+import (
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"regexp"
+
+	"github.com/henriqueassiss/advanced-golang-api/internal/domain/workspace"
+	"github.com/henriqueassiss/advanced-golang-api/internal/domain/workspace/useCase"
+	"github.com/henriqueassiss/advanced-golang-api/internal/utils/errorMsg"
+	"github.com/henriqueassiss/advanced-golang-api/internal/utils/identity"
+	"github.com/henriqueassiss/advanced-golang-api/internal/utils/reqRes"
+)
+
+// slugPattern keeps slugs usable as subdomains.
+var slugPattern = regexp.MustCompile(`^[a-z0-9]([a-z0-9-]{0,61}[a-z0-9])?$`)
+
+type IWorkspace struct {
+	useCase useCase.IWorkspace
+	logger  *slog.Logger
+}
+
+func NewHandler(useCase useCase.IWorkspace, logger *slog.Logger) *IWorkspace {
+	return &IWorkspace{
+		useCase: useCase,
+		logger:  logger,
+	}
+}
+
+func toSingleWorkspace(w *workspace.Schema) SingleWorkspace {
+	res := SingleWorkspace{
+		ID:   w.ID,
+		Name: w.Name,
+		Slug: w.Slug,
+	}
+
+	if w.UpdatedAt.Valid {
+		res.UpdatedAt = &w.UpdatedAt.Time
+	}
+
+	return res
+}
+
+func (h *IWorkspace) FindMany(w http.ResponseWriter, r *http.Request) {
+	i, ok := identity.FromContext(r.Context())
+	if !ok {
+		reqRes.Error(h.logger, w, http.StatusUnauthorized, errorMsg.ErrUnauthorized, nil)
+		return
+	}
+
+	ws, err := h.useCase.FindMany(r.Context(), i.UserID)
+	if err != nil {
+		reqRes.Error(h.logger, w, http.StatusInternalServerError, err, i.UserID)
+		return
+	}
+
+	res := make([]SingleWorkspace, len(ws))
+	for j := range ws {
+		res[j] = toSingleWorkspace(&ws[j])
+	}
+
+	reqRes.Json(w, http.StatusOK, res)
+}
+
+func (h *IWorkspace) Create(w http.ResponseWriter, r *http.Request) {
+	i, ok := identity.FromContext(r.Context())
+	if !ok {
+		reqRes.Error(h.logger, w, http.StatusUnauthorized, errorMsg.ErrUnauthorized, nil)
+		return
+	}
+
+	var req Create
+	err := json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		reqRes.Error(h.logger, w, http.StatusBadRequest, err, req)
+		return
+	}
+
+	if req.Name == "" ||
+		!slugPattern.MatchString(req.Slug) {
+		reqRes.Error(h.logger, w, http.StatusBadRequest, errorMsg.ErrInvalidRequestData, req)
+		return
+	}
+
+	ws := workspace.Schema{
+		Name: req.Name,
+		Slug: req.Slug,
+	}
+
+	err = h.useCase.Create(r.Context(), &ws, i.UserID)
+	if err != nil {
+		reqRes.Error(h.logger, w, http.StatusInternalServerError, err, ws)
+		return
+	}
+
+	reqRes.Json(w, http.StatusOK, toSingleWorkspace(&ws))
+}
+
+func (h *IWorkspace) SaveMember(w http.ResponseWriter, r *http.Request) {
+	i, ok := identity.FromContext(r.Context())
+	if !ok {
+		reqRes.Error(h.logger, w, http.StatusUnauthorized, errorMsg.ErrUnauthorized, nil)
+		return
+	}
+
+	workspaceID, err := reqRes.UInt64Param(r, "workspaceID", false)
+	if err != nil {
+		reqRes.Error(h.logger, w, http.StatusBadRequest, errorMsg.ErrInvalidRequestData, workspaceID)
+		return
+	}
+
+	var req SaveMember
+	err = json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		reqRes.Error(h.logger, w, http.StatusBadRequest, err, req)
+		return
+	}
+
+	if req.UserID == 0 {
+		reqRes.Error(h.logger, w, http.StatusBadRequest, errorMsg.ErrInvalidRequestData, req)
+		return
+	}
+
+	m := workspace.Member{
+		WorkspaceID: workspaceID,
+		UserID:      req.UserID,
+		Role:        req.Role,
+	}
+
+	err = h.useCase.SaveMember(r.Context(), i.UserID, &m)
+	if err != nil {
+		if err == errorMsg.ErrForbidden {
+			reqRes.Error(h.logger, w, http.StatusForbidden, err, m)
+		} else {
+			reqRes.Error(h.logger, w, http.StatusInternalServerError, err, m)
+		}
+
+		return
+	}
+
+	reqRes.Json(w, http.StatusOK, nil)
+}
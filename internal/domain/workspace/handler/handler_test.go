@@ -0,0 +1,124 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/henriqueassiss/advanced-golang-api/internal/domain/workspace"
+	"github.com/henriqueassiss/advanced-golang-api/internal/domain/workspace/useCase"
+	"github.com/henriqueassiss/advanced-golang-api/internal/utils/errorMsg"
+	"github.com/henriqueassiss/advanced-golang-api/internal/utils/identity"
+
+	"github.com/henriqueassiss/advanced-golang-api/third_party/logger"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+)
+
+func withIdentity(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		next.ServeHTTP(w, r.WithContext(identity.NewContext(r.Context(), &identity.Identity{UserID: 1})))
+	})
+}
+
+func TestWorkspaceHandler_Create(t *testing.T) {
+	logger := logger.New()
+
+	type test struct {
+		name   string
+		body   string
+		status int
+	}
+
+	tests := []test{
+		{
+			name:   "Success",
+			body:   `{"name": "Test", "slug": "test-team"}`,
+			status: http.StatusOK,
+		},
+		{
+			name:   "Fail - Slug is not a valid subdomain",
+			body:   `{"name": "Test", "slug": "Test.Team"}`,
+			status: http.StatusBadRequest,
+		},
+		{
+			name:   "Fail - Missing name",
+			body:   `{"slug": "test"}`,
+			status: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodPost, "/v1/workspaces", strings.NewReader(tt.body))
+			w := httptest.NewRecorder()
+
+			uc := &useCase.WorkspaceMock{
+				CreateFunc: func(ctx context.Context, w *workspace.Schema, ownerID uint64) error {
+					assert.Equal(t, uint64(1), ownerID)
+					w.ID = 1
+					return nil
+				},
+			}
+
+			router := chi.NewRouter()
+			RegisterHTTPEndPoints(uc, logger, router, withIdentity)
+			router.ServeHTTP(w, r)
+
+			assert.Equal(t, tt.status, w.Code)
+		})
+	}
+}
+
+func TestWorkspaceHandler_SaveMember(t *testing.T) {
+	logger := logger.New()
+
+	type test struct {
+		name   string
+		body   string
+		err    error
+		status int
+	}
+
+	tests := []test{
+		{
+			name:   "Success",
+			body:   `{"userId": 2}`,
+			status: http.StatusOK,
+		},
+		{
+			name:   "Fail - Missing user",
+			body:   `{}`,
+			status: http.StatusBadRequest,
+		},
+		{
+			name:   "Fail - Not the owner",
+			body:   `{"userId": 2}`,
+			err:    errorMsg.ErrForbidden,
+			status: http.StatusForbidden,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodPut, "/v1/workspaces/1/members", strings.NewReader(tt.body))
+			w := httptest.NewRecorder()
+
+			uc := &useCase.WorkspaceMock{
+				SaveMemberFunc: func(ctx context.Context, actorID uint64, m *workspace.Member) error {
+					assert.Equal(t, uint64(1), m.WorkspaceID)
+					return tt.err
+				},
+			}
+
+			router := chi.NewRouter()
+			RegisterHTTPEndPoints(uc, logger, router, withIdentity)
+			router.ServeHTTP(w, r)
+
+			assert.Equal(t, tt.status, w.Code)
+		})
+	}
+}
@@ -0,0 +1,20 @@
+package handler
+
+import (
+	"log/slog"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/henriqueassiss/advanced-golang-api/internal/domain/workspace/useCase"
+)
+
+func RegisterHTTPEndPoints(u useCase.IWorkspace, logger *slog.Logger, router *chi.Mux, middlewares ...func(http.Handler) http.Handler) *IWorkspace {
+	handler := NewHandler(u, logger)
+	router.Route("/v1/workspaces", func(router chi.Router) {
+		router.Use(middlewares...)
+		router.Get("/", handler.FindMany)
+		router.Post("/", handler.Create)
+		router.Put("/{workspaceID}/members", handler.SaveMember)
+	})
+	return handler
+}
@@ -0,0 +1,20 @@
+package handler
+
+import "time"
+
+type SingleWorkspace struct {
+	ID        uint64     `json:"id"`
+	Name      string     `json:"name"`
+	Slug      string     `json:"slug"`
+	UpdatedAt *time.Time `json:"updatedAt"`
+}
+
+type Create struct {
+	Name string `json:"name"`
+	Slug string `json:"slug"`
+}
+
+type SaveMember struct {
+	UserID uint64 `json:"userId"`
+	Role   string `json:"role"`
+}
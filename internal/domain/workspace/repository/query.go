@@ -0,0 +1,16 @@
+package repository
+
+var (
+	SelectBySlug = `SELECT w.* FROM workspaces w WHERE w.slug = ?`
+
+	SelectByMember = `SELECT w.* FROM workspaces w
+	JOIN workspace_members m ON m.workspace_id = w.id
+	WHERE m.user_id = ?
+	ORDER BY w.id`
+
+	SelectMember = `SELECT m.* FROM workspace_members m WHERE m.workspace_id = ? AND m.user_id = ?`
+
+	InsertInto = `INSERT INTO workspaces (name, slug) VALUES (?, ?)`
+
+	InsertMember = `INSERT INTO workspace_members (workspace_id, user_id, role) VALUES (?, ?, ?)`
+)
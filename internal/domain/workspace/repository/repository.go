@@ -0,0 +1,82 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/henriqueassiss/advanced-golang-api/internal/domain/workspace"
+	"github.com/henriqueassiss/advanced-golang-api/internal/utils/persistence"
+	"github.com/henriqueassiss/advanced-golang-api/third_party/database"
+
+	"github.com/jmoiron/sqlx"
+)
+
+type IWorkspace interface {
+	FindBySlug(ctx context.Context, slug string) (*workspace.Schema, error)
+	FindMany(ctx context.Context, userID uint64) ([]workspace.Schema, error)
+	FindMember(ctx context.Context, workspaceID, userID uint64) (*workspace.Member, error)
+	Create(ctx context.Context, w *workspace.Schema, ownerID uint64) error
+	SaveMember(ctx context.Context, m *workspace.Member) error
+}
+
+type Workspace struct {
+	db      *sqlx.DB
+	dialect database.Dialect
+	tx      *persistence.TxManager
+}
+
+func New(db *sqlx.DB) *Workspace {
+	return &Workspace{
+		db:      db,
+		dialect: database.DialectOf(db),
+		tx:      persistence.NewTxManager(db, sql.LevelDefault, 0),
+	}
+}
+
+func (r *Workspace) FindBySlug(ctx context.Context, slug string) (*workspace.Schema, error) {
+	var w workspace.Schema
+	err := persistence.Conn(ctx, r.db).GetContext(ctx, &w, r.dialect.Rebind(SelectBySlug), slug)
+
+	return &w, err
+}
+
+func (r *Workspace) FindMany(ctx context.Context, userID uint64) ([]workspace.Schema, error) {
+	var ws []workspace.Schema
+	err := persistence.Conn(ctx, r.db).SelectContext(ctx, &ws, r.dialect.Rebind(SelectByMember), userID)
+
+	return ws, err
+}
+
+func (r *Workspace) FindMember(ctx context.Context, workspaceID, userID uint64) (*workspace.Member, error) {
+	var m workspace.Member
+	err := persistence.Conn(ctx, r.db).GetContext(ctx, &m, r.dialect.Rebind(SelectMember), workspaceID, userID)
+
+	return &m, err
+}
+
+func (r *Workspace) Create(ctx context.Context, w *workspace.Schema, ownerID uint64) error {
+	return r.tx.Run(ctx, func(ctx context.Context) error {
+		tx := persistence.Conn(ctx, r.db)
+
+		id, err := persistence.Insert(ctx, r.db, "id", InsertInto, w.Name, w.Slug)
+		if err != nil {
+			return err
+		}
+
+		w.ID = id
+		_, err = tx.ExecContext(ctx, r.upsertMember(), w.ID, ownerID, workspace.RoleOwner)
+
+		return err
+	})
+}
+
+func (r *Workspace) SaveMember(ctx context.Context, m *workspace.Member) error {
+	_, err := persistence.Conn(ctx, r.db).ExecContext(ctx, r.upsertMember(), m.WorkspaceID, m.UserID, m.Role)
+
+	return err
+}
+
+// upsertMember inserts a member or changes the role of an existing one.
+func (r *Workspace) upsertMember() string {
+	return r.dialect.Rebind(InsertMember + " " + r.dialect.Upsert([]string{"workspace_id", "user_id"}, []string{"role"}))
+}
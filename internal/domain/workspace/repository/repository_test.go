@@ -0,0 +1,165 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+
+	"github.com/henriqueassiss/advanced-golang-api/internal/domain/workspace"
+	"github.com/henriqueassiss/advanced-golang-api/third_party/database"
+	"github.com/stretchr/testify/assert"
+
+	sqlxmock "github.com/zhashkevych/go-sqlxmock"
+)
+
+func TestWorkspaceRepository_FindMember(t *testing.T) {
+	db, mock := database.NewSqlxMock(t)
+	r := New(db)
+	defer db.Close()
+
+	type args struct {
+		workspaceID uint64
+		userID      uint64
+	}
+
+	type want struct {
+		m   *workspace.Member
+		err error
+	}
+
+	type test struct {
+		name string
+		args
+		beforeTest func()
+		want
+	}
+
+	tests := []test{
+		{
+			name: "Success",
+			args: args{
+				workspaceID: 1,
+				userID:      1,
+			},
+			beforeTest: func() {
+				rows := mock.NewRows([]string{"workspace_id", "user_id", "role"}).AddRow(1, 1, workspace.RoleOwner)
+				mock.ExpectQuery("SELECT m.\\* FROM workspace_members m").WithArgs(1, 1).WillReturnRows(rows)
+			},
+			want: want{
+				m: &workspace.Member{
+					WorkspaceID: 1,
+					UserID:      1,
+					Role:        workspace.RoleOwner,
+				},
+			},
+		},
+		{
+			name: "Fail - Not a member",
+			args: args{
+				workspaceID: 2,
+				userID:      1,
+			},
+			beforeTest: func() {
+				mock.ExpectQuery("SELECT m.\\* FROM workspace_members m").WithArgs(2, 1).WillReturnError(sql.ErrNoRows)
+			},
+			want: want{
+				m:   &workspace.Member{},
+				err: sql.ErrNoRows,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.beforeTest()
+
+			got, err := r.FindMember(context.TODO(), tt.args.workspaceID, tt.args.userID)
+			assert.Equal(t, tt.want.err, err)
+			assert.Equal(t, tt.want.m, got)
+		})
+	}
+}
+
+func TestWorkspaceRepository_Create(t *testing.T) {
+	db, mock := database.NewSqlxMock(t)
+	r := New(db)
+	defer db.Close()
+
+	type want struct {
+		id  uint64
+		err error
+	}
+
+	type test struct {
+		name       string
+		beforeTest func()
+		want
+	}
+
+	tests := []test{
+		{
+			name: "Success",
+			beforeTest: func() {
+				mock.ExpectBegin()
+				mock.ExpectQuery("INSERT INTO workspaces").WithArgs("Test", "test").WillReturnRows(mock.NewRows([]string{"id"}).AddRow(1))
+				mock.ExpectExec("INSERT INTO workspace_members").WithArgs(1, 1, workspace.RoleOwner).WillReturnResult(sqlxmock.NewResult(0, 1))
+				mock.ExpectCommit()
+			},
+			want: want{
+				id: 1,
+			},
+		},
+		{
+			name: "Fail - Membership is rolled back with the workspace",
+			beforeTest: func() {
+				mock.ExpectBegin()
+				mock.ExpectQuery("INSERT INTO workspaces").WithArgs("Test", "test").WillReturnRows(mock.NewRows([]string{"id"}).AddRow(1))
+				mock.ExpectExec("INSERT INTO workspace_members").WillReturnError(errors.New("some error"))
+				mock.ExpectRollback()
+			},
+			want: want{
+				id:  1,
+				err: errors.New("some error"),
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.beforeTest()
+
+			w := &workspace.Schema{Name: "Test", Slug: "test"}
+			err := r.Create(context.TODO(), w, 1)
+			assert.Equal(t, tt.want.err, err)
+			assert.Equal(t, tt.want.id, w.ID)
+			assert.Nil(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestWorkspaceRepository_SQLite(t *testing.T) {
+	db := database.NewSqlxSqlite(t)
+	r := New(db)
+
+	db.MustExec(`INSERT INTO users (name, email, password) VALUES ('Owner', 'owner@test.com', ''), ('Member', 'member@test.com', '')`)
+
+	w := &workspace.Schema{Name: "Test", Slug: "test"}
+	err := r.Create(context.TODO(), w, 1)
+	assert.Nil(t, err)
+	assert.Equal(t, uint64(2), w.ID, "the default workspace comes first")
+
+	for _, role := range []string{workspace.RoleMember, workspace.RoleOwner} {
+		err = r.SaveMember(context.TODO(), &workspace.Member{WorkspaceID: w.ID, UserID: 2, Role: role})
+		assert.Nil(t, err)
+
+		m, err := r.FindMember(context.TODO(), w.ID, 2)
+		assert.Nil(t, err)
+		assert.Equal(t, role, m.Role)
+	}
+
+	ws, err := r.FindMany(context.TODO(), 2)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(ws))
+	assert.Equal(t, "test", ws[0].Slug)
+}
@@ -0,0 +1,21 @@
+package workspace
+
+import "database/sql"
+
+const (
+	RoleOwner  = "owner"
+	RoleMember = "member"
+)
+
+type Schema struct {
+	ID        uint64       `db:"id"`
+	Name      string       `db:"name"`
+	Slug      string       `db:"slug"`
+	UpdatedAt sql.NullTime `db:"updated_at"`
+}
+
+type Member struct {
+	WorkspaceID uint64 `db:"workspace_id"`
+	UserID      uint64 `db:"user_id"`
+	Role        string `db:"role"`
+}
@@ -0,0 +1,110 @@
+package useCase
+
+import (
+	"context"
+	"database/sql"
+	"log/slog"
+
+	"github.com/henriqueassiss/advanced-golang-api/internal/domain/workspace"
+	"github.com/henriqueassiss/advanced-golang-api/internal/domain/workspace/repository"
+	"github.com/henriqueassiss/advanced-golang-api/internal/utils/errorMsg"
+)
+
+type IWorkspace interface {
+	FindMany(ctx context.Context, userID uint64) ([]workspace.Schema, error)
+	Create(ctx context.Context, w *workspace.Schema, ownerID uint64) error
+	SaveMember(ctx context.Context, actorID uint64, m *workspace.Member) error
+	Resolve(ctx context.Context, userID, workspaceID uint64, slug string) (uint64, error)
+	Provision(ctx context.Context, slug string, userID uint64, role string) (uint64, error)
+}
+
+type Workspace struct {
+	repository repository.IWorkspace
+	logger     *slog.Logger
+}
+
+func New(repo repository.IWorkspace, logger *slog.Logger) *Workspace {
+	return &Workspace{
+		repository: repo,
+		logger:     logger,
+	}
+}
+
+func (uc *Workspace) FindMany(ctx context.Context, userID uint64) ([]workspace.Schema, error) {
+	return uc.repository.FindMany(ctx, userID)
+}
+
+func (uc *Workspace) Create(ctx context.Context, w *workspace.Schema, ownerID uint64) error {
+	return uc.repository.Create(ctx, w, ownerID)
+}
+
+func (uc *Workspace) SaveMember(ctx context.Context, actorID uint64, m *workspace.Member) error {
+	actor, err := uc.repository.FindMember(ctx, m.WorkspaceID, actorID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return errorMsg.ErrForbidden
+		}
+
+		return err
+	}
+
+	if actor.Role != workspace.RoleOwner {
+		return errorMsg.ErrForbidden
+	}
+
+	if m.Role != workspace.RoleOwner {
+		m.Role = workspace.RoleMember
+	}
+
+	return uc.repository.SaveMember(ctx, m)
+}
+
+// Resolve turns a workspace ID or slug into the ID of a workspace the user is
+// a member of, or ErrForbidden.
+func (uc *Workspace) Resolve(ctx context.Context, userID, workspaceID uint64, slug string) (uint64, error) {
+	if workspaceID == 0 {
+		w, err := uc.repository.FindBySlug(ctx, slug)
+		if err != nil {
+			if err == sql.ErrNoRows {
+				return 0, errorMsg.ErrForbidden
+			}
+
+			return 0, err
+		}
+
+		workspaceID = w.ID
+	}
+
+	_, err := uc.repository.FindMember(ctx, workspaceID, userID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return 0, errorMsg.ErrForbidden
+		}
+
+		return 0, err
+	}
+
+	return workspaceID, nil
+}
+
+// Provision makes the user a member of the workspace with the given role,
+// overriding any previous one. It is meant for roles granted by an identity
+// provider, which is authoritative, so no actor is checked.
+func (uc *Workspace) Provision(ctx context.Context, slug string, userID uint64, role string) (uint64, error) {
+	if role != workspace.RoleOwner && role != workspace.RoleMember {
+		return 0, errorMsg.ErrInvalidRequestData
+	}
+
+	w, err := uc.repository.FindBySlug(ctx, slug)
+	if err != nil {
+		return 0, err
+	}
+
+	err = uc.repository.SaveMember(ctx, &workspace.Member{
+		WorkspaceID: w.ID,
+		UserID:      userID,
+		Role:        role,
+	})
+
+	return w.ID, err
+}
@@ -0,0 +1,35 @@
+package useCase
+
+import (
+	"context"
+
+	"github.com/henriqueassiss/advanced-golang-api/internal/domain/workspace"
+)
+
+type WorkspaceMock struct {
+	FindManyFunc   func(ctx context.Context, userID uint64) ([]workspace.Schema, error)
+	CreateFunc     func(ctx context.Context, w *workspace.Schema, ownerID uint64) error
+	SaveMemberFunc func(ctx context.Context, actorID uint64, m *workspace.Member) error
+	ResolveFunc    func(ctx context.Context, userID, workspaceID uint64, slug string) (uint64, error)
+	ProvisionFunc  func(ctx context.Context, slug string, userID uint64, role string) (uint64, error)
+}
+
+func (uc *WorkspaceMock) FindMany(ctx context.Context, userID uint64) ([]workspace.Schema, error) {
+	return uc.FindManyFunc(ctx, userID)
+}
+
+func (uc *WorkspaceMock) Create(ctx context.Context, w *workspace.Schema, ownerID uint64) error {
+	return uc.CreateFunc(ctx, w, ownerID)
+}
+
+func (uc *WorkspaceMock) SaveMember(ctx context.Context, actorID uint64, m *workspace.Member) error {
+	return uc.SaveMemberFunc(ctx, actorID, m)
+}
+
+func (uc *WorkspaceMock) Resolve(ctx context.Context, userID, workspaceID uint64, slug string) (uint64, error) {
+	return uc.ResolveFunc(ctx, userID, workspaceID, slug)
+}
+
+func (uc *WorkspaceMock) Provision(ctx context.Context, slug string, userID uint64, role string) (uint64, error) {
+	return uc.ProvisionFunc(ctx, slug, userID, role)
+}
@@ -0,0 +1,182 @@
+package useCase
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	"github.com/henriqueassiss/advanced-golang-api/internal/domain/workspace"
+	"github.com/henriqueassiss/advanced-golang-api/internal/domain/workspace/repository"
+	"github.com/henriqueassiss/advanced-golang-api/internal/utils/errorMsg"
+
+	"github.com/henriqueassiss/advanced-golang-api/third_party/database"
+	"github.com/henriqueassiss/advanced-golang-api/third_party/logger"
+
+	"github.com/stretchr/testify/assert"
+	sqlxmock "github.com/zhashkevych/go-sqlxmock"
+)
+
+func TestWorkspaceUseCase_Resolve(t *testing.T) {
+	logger := logger.New()
+	db, mock := database.NewSqlxMock(t)
+	r := repository.New(db)
+	uc := New(r, logger)
+	defer db.Close()
+
+	type args struct {
+		workspaceID uint64
+		slug        string
+	}
+
+	type want struct {
+		workspaceID uint64
+		err         error
+	}
+
+	type test struct {
+		name string
+		args
+		beforeTest func()
+		want
+	}
+
+	tests := []test{
+		{
+			name: "Success - By id",
+			args: args{
+				workspaceID: 1,
+			},
+			beforeTest: func() {
+				rows := mock.NewRows([]string{"workspace_id", "user_id", "role"}).AddRow(1, 1, workspace.RoleMember)
+				mock.ExpectQuery("FROM workspace_members").WithArgs(1, 1).WillReturnRows(rows)
+			},
+			want: want{
+				workspaceID: 1,
+			},
+		},
+		{
+			name: "Success - By slug",
+			args: args{
+				slug: "test",
+			},
+			beforeTest: func() {
+				mock.ExpectQuery("FROM workspaces w WHERE w.slug =").WithArgs("test").WillReturnRows(mock.NewRows([]string{"id", "slug"}).AddRow(2, "test"))
+
+				rows := mock.NewRows([]string{"workspace_id", "user_id", "role"}).AddRow(2, 1, workspace.RoleMember)
+				mock.ExpectQuery("FROM workspace_members").WithArgs(2, 1).WillReturnRows(rows)
+			},
+			want: want{
+				workspaceID: 2,
+			},
+		},
+		{
+			name: "Fail - Not a member",
+			args: args{
+				workspaceID: 3,
+			},
+			beforeTest: func() {
+				mock.ExpectQuery("FROM workspace_members").WithArgs(3, 1).WillReturnError(sql.ErrNoRows)
+			},
+			want: want{
+				err: errorMsg.ErrForbidden,
+			},
+		},
+		{
+			name: "Fail - Non-existent slug",
+			args: args{
+				slug: "none",
+			},
+			beforeTest: func() {
+				mock.ExpectQuery("FROM workspaces w WHERE w.slug =").WithArgs("none").WillReturnError(sql.ErrNoRows)
+			},
+			want: want{
+				err: errorMsg.ErrForbidden,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.beforeTest()
+
+			got, err := uc.Resolve(context.TODO(), 1, tt.args.workspaceID, tt.args.slug)
+			assert.Equal(t, tt.want.err, err)
+			assert.Equal(t, tt.want.workspaceID, got)
+		})
+	}
+}
+
+func TestWorkspaceUseCase_SaveMember(t *testing.T) {
+	logger := logger.New()
+	db, mock := database.NewSqlxMock(t)
+	r := repository.New(db)
+	uc := New(r, logger)
+	defer db.Close()
+
+	type test struct {
+		name       string
+		role       string
+		beforeTest func()
+		err        error
+	}
+
+	tests := []test{
+		{
+			name: "Success - Owner adds a member",
+			role: "admin",
+			beforeTest: func() {
+				rows := mock.NewRows([]string{"workspace_id", "user_id", "role"}).AddRow(1, 1, workspace.RoleOwner)
+				mock.ExpectQuery("FROM workspace_members").WithArgs(1, 1).WillReturnRows(rows)
+				mock.ExpectExec("INSERT INTO workspace_members").WithArgs(1, 2, workspace.RoleMember).WillReturnResult(sqlxmock.NewResult(0, 1))
+			},
+		},
+		{
+			name: "Fail - Members cannot add members",
+			role: workspace.RoleMember,
+			beforeTest: func() {
+				rows := mock.NewRows([]string{"workspace_id", "user_id", "role"}).AddRow(1, 1, workspace.RoleMember)
+				mock.ExpectQuery("FROM workspace_members").WithArgs(1, 1).WillReturnRows(rows)
+			},
+			err: errorMsg.ErrForbidden,
+		},
+		{
+			name: "Fail - Outsiders cannot add members",
+			role: workspace.RoleMember,
+			beforeTest: func() {
+				mock.ExpectQuery("FROM workspace_members").WithArgs(1, 1).WillReturnError(sql.ErrNoRows)
+			},
+			err: errorMsg.ErrForbidden,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.beforeTest()
+
+			err := uc.SaveMember(context.TODO(), 1, &workspace.Member{WorkspaceID: 1, UserID: 2, Role: tt.role})
+			assert.Equal(t, tt.err, err)
+		})
+	}
+
+	assert.Nil(t, mock.ExpectationsWereMet())
+}
+
+func TestWorkspaceUseCase_Provision(t *testing.T) {
+	logger := logger.New()
+	db, mock := database.NewSqlxMock(t)
+	r := repository.New(db)
+	uc := New(r, logger)
+	defer db.Close()
+
+	rows := mock.NewRows([]string{"id", "name", "slug"}).AddRow(1, "Default", "default")
+	mock.ExpectQuery("FROM workspaces w WHERE w.slug =").WithArgs("default").WillReturnRows(rows)
+	mock.ExpectExec("INSERT INTO workspace_members").WithArgs(1, 2, workspace.RoleOwner).WillReturnResult(sqlxmock.NewResult(0, 1))
+
+	workspaceID, err := uc.Provision(context.TODO(), "default", 2, workspace.RoleOwner)
+	assert.Nil(t, err)
+	assert.Equal(t, uint64(1), workspaceID)
+
+	_, err = uc.Provision(context.TODO(), "default", 2, "admin")
+	assert.Equal(t, errorMsg.ErrInvalidRequestData, err)
+	assert.Nil(t, mock.ExpectationsWereMet())
+}
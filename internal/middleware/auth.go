@@ -0,0 +1,36 @@
+package middleware
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"strings"
+
+	"github.com/henriqueassiss/advanced-golang-api/internal/utils/errorMsg"
+	"github.com/henriqueassiss/advanced-golang-api/internal/utils/identity"
+	"github.com/henriqueassiss/advanced-golang-api/internal/utils/reqRes"
+)
+
+type Authenticator interface {
+	Authenticate(ctx context.Context, token string) (*identity.Identity, error)
+}
+
+func Authenticate(a Authenticator, logger *slog.Logger) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			token, found := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+			if !found || token == "" {
+				reqRes.Error(logger, w, http.StatusUnauthorized, errorMsg.ErrUnauthorized, r.URL.Path)
+				return
+			}
+
+			i, err := a.Authenticate(r.Context(), token)
+			if err != nil {
+				reqRes.Error(logger, w, http.StatusUnauthorized, err, r.URL.Path)
+				return
+			}
+
+			next.ServeHTTP(w, r.WithContext(identity.NewContext(r.Context(), i)))
+		})
+	}
+}
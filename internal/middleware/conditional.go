@@ -0,0 +1,122 @@
+package middleware
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// bufferedWriter holds the response back so its ETag can be computed before
+// anything is sent.
+type bufferedWriter struct {
+	http.ResponseWriter
+	status int
+	body   bytes.Buffer
+}
+
+func (w *bufferedWriter) WriteHeader(status int) {
+	if w.status == 0 {
+		w.status = status
+	}
+}
+
+func (w *bufferedWriter) Write(b []byte) (int, error) {
+	if w.status == 0 {
+		w.status = http.StatusOK
+	}
+
+	return w.body.Write(b)
+}
+
+func etag(body []byte) string {
+	sum := sha256.Sum256(body)
+	return `"` + hex.EncodeToString(sum[:16]) + `"`
+}
+
+// noneMatch reports whether the If-None-Match header matches tag. The
+// comparison is weak, as RFC 9110 asks for If-None-Match.
+func noneMatch(header, tag string) bool {
+	for _, candidate := range strings.Split(header, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "*" || strings.TrimPrefix(candidate, "W/") == tag {
+			return true
+		}
+	}
+
+	return false
+}
+
+// notModifiedSince reports whether a response last modified at lastModified
+// is still the one the client got at the If-Modified-Since date.
+func notModifiedSince(header, lastModified string) bool {
+	since, err := http.ParseTime(header)
+	if err != nil {
+		return false
+	}
+
+	modified, err := http.ParseTime(lastModified)
+	if err != nil {
+		return false
+	}
+
+	return !modified.Truncate(time.Second).After(since)
+}
+
+// Conditional gives successful GET responses a strong ETag and the
+// cacheControl policy, and answers If-None-Match and If-Modified-Since with
+// 304 Not Modified when the client already has the response. If-Modified-Since
+// is only considered without If-None-Match and when the handler sent
+// Last-Modified. Responses depend on the caller, so they vary on the
+// Authorization and X-Workspace-ID headers.
+func Conditional(cacheControl string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Method != http.MethodGet {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			bw := &bufferedWriter{ResponseWriter: w}
+			next.ServeHTTP(bw, r)
+
+			if bw.status == 0 {
+				bw.status = http.StatusOK
+			}
+
+			if bw.status != http.StatusOK {
+				w.WriteHeader(bw.status)
+				_, _ = w.Write(bw.body.Bytes())
+				return
+			}
+
+			tag := etag(bw.body.Bytes())
+			header := w.Header()
+			header.Set("ETag", tag)
+			header.Add("Vary", "Authorization")
+			header.Add("Vary", WorkspaceHeader)
+			if cacheControl != "" {
+				header.Set("Cache-Control", cacheControl)
+			}
+
+			notModified := false
+			if inm := r.Header.Get("If-None-Match"); inm != "" {
+				notModified = noneMatch(inm, tag)
+			} else if ims := r.Header.Get("If-Modified-Since"); ims != "" && header.Get("Last-Modified") != "" {
+				notModified = notModifiedSince(ims, header.Get("Last-Modified"))
+			}
+
+			if notModified {
+				header.Del("Content-Type")
+				header.Del("Content-Length")
+				w.WriteHeader(http.StatusNotModified)
+				return
+			}
+
+			w.WriteHeader(http.StatusOK)
+			_, _ = w.Write(bw.body.Bytes())
+		})
+	}
+}
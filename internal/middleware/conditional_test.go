@@ -0,0 +1,128 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConditional(t *testing.T) {
+	type test struct {
+		name         string
+		method       string
+		status       int
+		lastModified string
+		headers      map[string]string
+		wantStatus   int
+		wantETag     bool
+	}
+
+	tag := etag([]byte(`{"id":1}`))
+
+	tests := []test{
+		{
+			name:       "Fresh request",
+			method:     http.MethodGet,
+			status:     http.StatusOK,
+			wantStatus: http.StatusOK,
+			wantETag:   true,
+		},
+		{
+			name:       "Matching If-None-Match",
+			method:     http.MethodGet,
+			status:     http.StatusOK,
+			headers:    map[string]string{"If-None-Match": `"other", W/` + tag},
+			wantStatus: http.StatusNotModified,
+			wantETag:   true,
+		},
+		{
+			name:       "Stale If-None-Match",
+			method:     http.MethodGet,
+			status:     http.StatusOK,
+			headers:    map[string]string{"If-None-Match": `"other"`},
+			wantStatus: http.StatusOK,
+			wantETag:   true,
+		},
+		{
+			name:         "If-Modified-Since after the last change",
+			method:       http.MethodGet,
+			status:       http.StatusOK,
+			lastModified: "Tue, 02 Jan 2024 03:04:05 GMT",
+			headers:      map[string]string{"If-Modified-Since": "Tue, 02 Jan 2024 03:04:05 GMT"},
+			wantStatus:   http.StatusNotModified,
+			wantETag:     true,
+		},
+		{
+			name:         "If-Modified-Since before the last change",
+			method:       http.MethodGet,
+			status:       http.StatusOK,
+			lastModified: "Tue, 02 Jan 2024 03:04:05 GMT",
+			headers:      map[string]string{"If-Modified-Since": "Tue, 02 Jan 2024 03:04:04 GMT"},
+			wantStatus:   http.StatusOK,
+			wantETag:     true,
+		},
+		{
+			name:         "If-None-Match takes precedence",
+			method:       http.MethodGet,
+			status:       http.StatusOK,
+			lastModified: "Tue, 02 Jan 2024 03:04:05 GMT",
+			headers: map[string]string{
+				"If-None-Match":     `"other"`,
+				"If-Modified-Since": "Tue, 02 Jan 2024 03:04:05 GMT",
+			},
+			wantStatus: http.StatusOK,
+			wantETag:   true,
+		},
+		{
+			name:       "Errors are passed through",
+			method:     http.MethodGet,
+			status:     http.StatusBadRequest,
+			headers:    map[string]string{"If-None-Match": "*"},
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "Other methods are passed through",
+			method:     http.MethodPut,
+			status:     http.StatusOK,
+			headers:    map[string]string{"If-None-Match": "*"},
+			wantStatus: http.StatusOK,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := Conditional("private, no-cache")(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if tt.lastModified != "" {
+					w.Header().Set("Last-Modified", tt.lastModified)
+				}
+				w.WriteHeader(tt.status)
+				_, _ = w.Write([]byte(`{"id":1}`))
+			}))
+
+			r := httptest.NewRequest(tt.method, "/", nil)
+			for k, v := range tt.headers {
+				r.Header.Set(k, v)
+			}
+
+			w := httptest.NewRecorder()
+			h.ServeHTTP(w, r)
+
+			assert.Equal(t, tt.wantStatus, w.Code)
+			if !tt.wantETag {
+				assert.Empty(t, w.Header().Get("ETag"))
+				assert.Equal(t, `{"id":1}`, w.Body.String())
+				return
+			}
+
+			assert.Equal(t, tag, w.Header().Get("ETag"))
+			assert.Equal(t, "private, no-cache", w.Header().Get("Cache-Control"))
+			if tt.wantStatus == http.StatusNotModified {
+				assert.Equal(t, 0, w.Body.Len())
+			} else {
+				assert.Equal(t, `{"id":1}`, w.Body.String())
+			}
+		})
+	}
+}
@@ -0,0 +1,245 @@
+package middleware
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/henriqueassiss/advanced-golang-api/internal/utils/errorMsg"
+	"github.com/henriqueassiss/advanced-golang-api/internal/utils/identity"
+	"github.com/henriqueassiss/advanced-golang-api/internal/utils/reqRes"
+	"github.com/henriqueassiss/advanced-golang-api/internal/utils/tenant"
+	"github.com/henriqueassiss/advanced-golang-api/third_party/cache"
+)
+
+const (
+	IdempotencyKeyHeader = "Idempotency-Key"
+	ReplayedHeader       = "Idempotent-Replayed"
+
+	maxIdempotencyKeyLength = 255
+)
+
+// idempotencyLockTTL bounds how long a request that never finished, say
+// because its replica died, blocks retries of its key. The lock is renewed
+// while the request is handled, so slower handlers keep it.
+var idempotencyLockTTL = time.Minute
+
+// idempotentResponse is what is stored under an Idempotency-Key: the hash of
+// the request, then, once it is handled, the response to replay.
+type idempotentResponse struct {
+	Hash   string      `json:"hash"`
+	Done   bool        `json:"done"`
+	Status int         `json:"status,omitempty"`
+	Header http.Header `json:"header,omitempty"`
+	Body   []byte      `json:"body,omitempty"`
+}
+
+func idempotencyKey(r *http.Request, key string) string {
+	var userID, workspaceID uint64
+	if i, ok := identity.FromContext(r.Context()); ok {
+		userID = i.UserID
+	}
+
+	if id, ok := tenant.FromContext(r.Context()); ok {
+		workspaceID = id
+	}
+
+	return fmt.Sprintf("idempotency:%d:%d:%s", userID, workspaceID, key)
+}
+
+func requestHash(r *http.Request, body []byte) string {
+	h := sha256.New()
+	h.Write([]byte(r.Method + " " + r.URL.Path + "\n"))
+	h.Write(body)
+
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// changedHeader returns the headers the handler set, leaving out the ones
+// other middlewares set before it, which are not part of the response to
+// replay.
+func changedHeader(before, after http.Header) http.Header {
+	changed := http.Header{}
+	for k, v := range after {
+		if fmt.Sprint(before[k]) != fmt.Sprint(v) {
+			changed[k] = v
+		}
+	}
+
+	return changed
+}
+
+// Idempotent makes POST requests carrying an Idempotency-Key safe to retry.
+// The first request with a key is handled and its response stored for ttl;
+// retries with the same key and body get that response again, a different
+// body with the key is refused with 422 and a retry arriving while the first
+// request is still being handled gets 409. Server errors are not stored, so
+// the request can be retried, and requests go through unprotected when the
+// store fails, as they did before keys were honoured. Keys are scoped to the
+// user and workspace, so it must run after Authenticate and Tenant.
+func Idempotent(store cache.Cache, ttl time.Duration, logger *slog.Logger) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			header := r.Header.Get(IdempotencyKeyHeader)
+			if r.Method != http.MethodPost || header == "" {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			if len(header) > maxIdempotencyKeyLength {
+				reqRes.Error(logger, w, http.StatusBadRequest, errorMsg.ErrInvalidRequestData, header)
+				return
+			}
+
+			body, err := io.ReadAll(r.Body)
+			if err != nil {
+				reqRes.Error(logger, w, http.StatusBadRequest, err, header)
+				return
+			}
+
+			r.Body = io.NopCloser(bytes.NewReader(body))
+
+			key := idempotencyKey(r, header)
+			hash := requestHash(r, body)
+
+			lock, err := json.Marshal(idempotentResponse{Hash: hash})
+			if err != nil {
+				reqRes.Error(logger, w, http.StatusInternalServerError, err, key)
+				return
+			}
+
+			ok, err := store.Add(r.Context(), key, lock, idempotencyLockTTL)
+			if err != nil {
+				logger.Error(err.Error(), "key", key)
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			if !ok {
+				replay(store, w, r, key, hash, logger)
+				return
+			}
+
+			// The outcome is stored even if the client goes away, or a retry
+			// would find the lock held or run the request again.
+			ctx := context.WithoutCancel(r.Context())
+
+			before := w.Header().Clone()
+			bw := &bufferedWriter{ResponseWriter: w}
+			release := holdLock(ctx, store, key, lock, logger)
+			next.ServeHTTP(bw, r)
+			release()
+
+			if bw.status == 0 {
+				bw.status = http.StatusOK
+			}
+
+			if bw.status >= http.StatusInternalServerError {
+				err = store.Delete(ctx, key)
+			} else {
+				var data []byte
+				data, err = json.Marshal(idempotentResponse{
+					Hash:   hash,
+					Done:   true,
+					Status: bw.status,
+					Header: changedHeader(before, w.Header()),
+					Body:   bw.body.Bytes(),
+				})
+				if err == nil {
+					_, err = store.Swap(ctx, key, lock, data, ttl)
+				}
+			}
+
+			if err != nil {
+				logger.Error(err.Error(), "key", key)
+			}
+
+			w.WriteHeader(bw.status)
+			_, _ = w.Write(bw.body.Bytes())
+		})
+	}
+}
+
+// holdLock renews the lock on key until the returned func is called, so it
+// does not expire while a slow handler runs and let a retry run it again.
+func holdLock(ctx context.Context, store cache.Cache, key string, lock []byte, logger *slog.Logger) func() {
+	stop := make(chan struct{})
+	stopped := make(chan struct{})
+
+	go func() {
+		defer close(stopped)
+
+		ticker := time.NewTicker(idempotencyLockTTL / 3)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-stop:
+				return
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				ok, err := store.Swap(ctx, key, lock, lock, idempotencyLockTTL)
+				if err != nil {
+					logger.Error(err.Error(), "key", key)
+				}
+				if !ok {
+					return
+				}
+			}
+		}
+	}()
+
+	return func() {
+		close(stop)
+		<-stopped
+	}
+}
+
+func replay(store cache.Cache, w http.ResponseWriter, r *http.Request, key, hash string, logger *slog.Logger) {
+	data, err := store.Get(r.Context(), key)
+	if err == cache.ErrNotFound {
+		// The first request failed and released the key in the meantime.
+		w.Header().Set("Retry-After", "1")
+		reqRes.Error(logger, w, http.StatusConflict, errorMsg.ErrRequestInProgress, key)
+		return
+	}
+
+	if err != nil {
+		reqRes.Error(logger, w, http.StatusInternalServerError, err, key)
+		return
+	}
+
+	var stored idempotentResponse
+	err = json.Unmarshal(data, &stored)
+	if err != nil {
+		reqRes.Error(logger, w, http.StatusInternalServerError, err, key)
+		return
+	}
+
+	if stored.Hash != hash {
+		reqRes.Error(logger, w, http.StatusUnprocessableEntity, errorMsg.ErrIdempotencyKeyReused, key)
+		return
+	}
+
+	if !stored.Done {
+		w.Header().Set("Retry-After", "1")
+		reqRes.Error(logger, w, http.StatusConflict, errorMsg.ErrRequestInProgress, key)
+		return
+	}
+
+	for k, v := range stored.Header {
+		w.Header()[k] = v
+	}
+
+	w.Header().Set(ReplayedHeader, "true")
+	w.WriteHeader(stored.Status)
+	_, _ = w.Write(stored.Body)
+}
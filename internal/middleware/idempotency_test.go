@@ -0,0 +1,159 @@
+package middleware
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/henriqueassiss/advanced-golang-api/internal/utils/identity"
+	"github.com/henriqueassiss/advanced-golang-api/third_party/cache"
+	"github.com/henriqueassiss/advanced-golang-api/third_party/logger"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIdempotent(t *testing.T) {
+	var calls atomic.Int64
+	status := http.StatusCreated
+
+	h := Idempotent(cache.NewMock(t), time.Hour, logger.New())(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		w.Header().Set("Location", "/v1/task/1")
+		w.WriteHeader(status)
+		_, _ = w.Write([]byte(`{"id":1}`))
+	}))
+
+	request := func(key, body string, userID uint64) *httptest.ResponseRecorder {
+		r := httptest.NewRequest(http.MethodPost, "/v1/task", bytes.NewBufferString(body))
+		r = r.WithContext(identity.NewContext(r.Context(), &identity.Identity{UserID: userID}))
+		if key != "" {
+			r.Header.Set(IdempotencyKeyHeader, key)
+		}
+
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, r)
+		return w
+	}
+
+	w := request("a", `{"title":"Test"}`, 1)
+	assert.Equal(t, http.StatusCreated, w.Code)
+	assert.Empty(t, w.Header().Get(ReplayedHeader))
+
+	w = request("a", `{"title":"Test"}`, 1)
+	assert.Equal(t, http.StatusCreated, w.Code)
+	assert.Equal(t, "true", w.Header().Get(ReplayedHeader))
+	assert.Equal(t, "/v1/task/1", w.Header().Get("Location"))
+	assert.Equal(t, `{"id":1}`, w.Body.String())
+	assert.Equal(t, int64(1), calls.Load(), "retries are replayed")
+
+	w = request("a", `{"title":"Other"}`, 1)
+	assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+
+	request("a", `{"title":"Test"}`, 2)
+	assert.Equal(t, int64(2), calls.Load(), "keys are scoped to the user")
+
+	request("", `{"title":"Test"}`, 1)
+	request("", `{"title":"Test"}`, 1)
+	assert.Equal(t, int64(4), calls.Load(), "requests without a key are not deduplicated")
+
+	status = http.StatusInternalServerError
+	request("b", `{"title":"Test"}`, 1)
+	status = http.StatusCreated
+	w = request("b", `{"title":"Test"}`, 1)
+	assert.Equal(t, http.StatusCreated, w.Code, "server errors can be retried")
+	assert.Equal(t, int64(6), calls.Load())
+}
+
+func TestIdempotent_InFlight(t *testing.T) {
+	started := make(chan struct{})
+	release := make(chan struct{})
+
+	h := Idempotent(cache.NewMemory(0, 0), time.Hour, logger.New())(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		close(started)
+		<-release
+		w.WriteHeader(http.StatusCreated)
+	}))
+
+	request := func() *httptest.ResponseRecorder {
+		r := httptest.NewRequest(http.MethodPost, "/v1/task", bytes.NewBufferString(`{}`))
+		r.Header.Set(IdempotencyKeyHeader, "a")
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, r)
+		return w
+	}
+
+	first := make(chan *httptest.ResponseRecorder)
+	go func() { first <- request() }()
+	<-started
+
+	w := request()
+	assert.Equal(t, http.StatusConflict, w.Code)
+	assert.Equal(t, "1", w.Header().Get("Retry-After"))
+
+	close(release)
+	assert.Equal(t, http.StatusCreated, (<-first).Code)
+	assert.Equal(t, http.StatusCreated, request().Code)
+}
+
+func TestIdempotent_SlowHandler(t *testing.T) {
+	ttl := idempotencyLockTTL
+	idempotencyLockTTL = 30 * time.Millisecond
+	t.Cleanup(func() { idempotencyLockTTL = ttl })
+
+	var calls atomic.Int64
+	h := Idempotent(cache.NewMemory(0, 0), time.Hour, logger.New())(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		time.Sleep(5 * idempotencyLockTTL)
+		w.WriteHeader(http.StatusCreated)
+	}))
+
+	request := func() *httptest.ResponseRecorder {
+		r := httptest.NewRequest(http.MethodPost, "/v1/task", bytes.NewBufferString(`{}`))
+		r.Header.Set(IdempotencyKeyHeader, "a")
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, r)
+		return w
+	}
+
+	first := make(chan *httptest.ResponseRecorder)
+	go func() { first <- request() }()
+
+	time.Sleep(3 * idempotencyLockTTL)
+	assert.Equal(t, http.StatusConflict, request().Code, "the lock outlives its ttl while the handler runs")
+
+	assert.Equal(t, http.StatusCreated, (<-first).Code)
+	w := request()
+	assert.Equal(t, http.StatusCreated, w.Code)
+	assert.Equal(t, "true", w.Header().Get(ReplayedHeader))
+	assert.Equal(t, int64(1), calls.Load())
+}
+
+func TestIdempotent_Disconnect(t *testing.T) {
+	ctx, disconnect := context.WithCancel(context.TODO())
+
+	var calls atomic.Int64
+	h := Idempotent(cache.NewMock(t), time.Hour, logger.New())(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		disconnect()
+		w.WriteHeader(http.StatusCreated)
+	}))
+
+	request := func(ctx context.Context) *httptest.ResponseRecorder {
+		r := httptest.NewRequest(http.MethodPost, "/v1/task", bytes.NewBufferString(`{}`)).WithContext(ctx)
+		r.Header.Set(IdempotencyKeyHeader, "a")
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, r)
+		return w
+	}
+
+	request(ctx)
+
+	w := request(context.TODO())
+	assert.Equal(t, http.StatusCreated, w.Code, "responses are stored after the client went away")
+	assert.Equal(t, "true", w.Header().Get(ReplayedHeader))
+	assert.Equal(t, int64(1), calls.Load())
+}
@@ -0,0 +1,81 @@
+package middleware
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"log/slog"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/henriqueassiss/advanced-golang-api/config"
+	"github.com/henriqueassiss/advanced-golang-api/internal/utils/errorMsg"
+	"github.com/henriqueassiss/advanced-golang-api/internal/utils/identity"
+	"github.com/henriqueassiss/advanced-golang-api/internal/utils/reqRes"
+	"github.com/henriqueassiss/advanced-golang-api/third_party/ratelimit"
+)
+
+const APIKeyHeader = "X-API-Key"
+
+// client names who a request is counted against. API keys are hashed so they
+// are never stored.
+func client(r *http.Request, by string) string {
+	switch by {
+	case config.LimitByUser:
+		if i, ok := identity.FromContext(r.Context()); ok {
+			return fmt.Sprintf("user:%d", i.UserID)
+		}
+	case config.LimitByAPIKey:
+		if key := r.Header.Get(APIKeyHeader); key != "" {
+			sum := sha256.Sum256([]byte(key))
+			return "apikey:" + hex.EncodeToString(sum[:])
+		}
+	}
+
+	return "ip:" + reqRes.GetRequestIP(r)
+}
+
+func seconds(d time.Duration) string {
+	return strconv.Itoa(int(math.Ceil(d.Seconds())))
+}
+
+// RateLimit allows limit.Requests per limit.Window to each client of the
+// group, answering 429 once they are spent. Limiting by user must run after
+// Authenticate. When the limiter fails requests are let through, since an
+// outage of the limiter should not take the API down with it.
+func RateLimit(limiter ratelimit.Limiter, group string, limit config.Limit, logger *slog.Logger) func(http.Handler) http.Handler {
+	policy := fmt.Sprintf("%d;w=%s", limit.Requests, seconds(limit.Window))
+
+	return func(next http.Handler) http.Handler {
+		if limit.Requests == 0 {
+			return next
+		}
+
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			key := fmt.Sprintf("ratelimit:%s:%s", group, client(r, limit.By))
+
+			res, err := limiter.Allow(r.Context(), key, limit.Requests, limit.Window)
+			if err != nil {
+				logger.Error(err.Error(), "key", key)
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			header := w.Header()
+			header.Set("RateLimit-Policy", policy)
+			header.Set("RateLimit-Limit", strconv.Itoa(res.Limit))
+			header.Set("RateLimit-Remaining", strconv.Itoa(res.Remaining))
+			header.Set("RateLimit-Reset", seconds(res.Reset))
+
+			if !res.Allowed {
+				header.Set("Retry-After", seconds(res.Reset))
+				reqRes.Error(logger, w, http.StatusTooManyRequests, errorMsg.ErrTooManyRequests, key)
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
@@ -0,0 +1,92 @@
+package middleware
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/henriqueassiss/advanced-golang-api/config"
+	"github.com/henriqueassiss/advanced-golang-api/internal/utils/identity"
+	"github.com/henriqueassiss/advanced-golang-api/internal/utils/reqRes"
+	"github.com/henriqueassiss/advanced-golang-api/third_party/logger"
+	"github.com/henriqueassiss/advanced-golang-api/third_party/ratelimit"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type failingLimiter struct{}
+
+func (failingLimiter) Allow(ctx context.Context, key string, limit int, window time.Duration) (ratelimit.Result, error) {
+	return ratelimit.Result{}, errors.New("unavailable")
+}
+
+func TestRateLimit(t *testing.T) {
+	limit := config.Limit{By: config.LimitByUser, Requests: 2, Window: time.Minute}
+	h := RateLimit(ratelimit.NewMemory(), "tasks", limit, logger.New())(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	request := func(userID uint64) *httptest.ResponseRecorder {
+		r := httptest.NewRequest(http.MethodGet, "/", nil)
+		r = r.WithContext(identity.NewContext(r.Context(), &identity.Identity{UserID: userID}))
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, r)
+		return w
+	}
+
+	w := request(1)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "2;w=60", w.Header().Get("RateLimit-Policy"))
+	assert.Equal(t, "2", w.Header().Get("RateLimit-Limit"))
+	assert.Equal(t, "1", w.Header().Get("RateLimit-Remaining"))
+	assert.Equal(t, "60", w.Header().Get("RateLimit-Reset"))
+
+	assert.Equal(t, http.StatusOK, request(1).Code)
+
+	w = request(1)
+	assert.Equal(t, http.StatusTooManyRequests, w.Code)
+	assert.Equal(t, "0", w.Header().Get("RateLimit-Remaining"))
+	assert.Equal(t, "60", w.Header().Get("Retry-After"))
+
+	var got reqRes.GenericResponse[any]
+	err := json.NewDecoder(w.Body).Decode(&got)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert.Equal(t, reqRes.GenericResponse[any]{Success: false, Status: http.StatusTooManyRequests}, got)
+
+	assert.Equal(t, http.StatusOK, request(2).Code, "users are limited on their own")
+}
+
+func TestRateLimit_Client(t *testing.T) {
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+	r.RemoteAddr = "10.0.0.1:1234"
+
+	assert.Equal(t, "ip:10.0.0.1", client(r, config.LimitByUser), "anonymous users are counted by IP")
+	assert.Equal(t, "ip:10.0.0.1", client(r, config.LimitByAPIKey), "requests without a key are counted by IP")
+
+	r.Header.Set(APIKeyHeader, "secret")
+	assert.NotContains(t, client(r, config.LimitByAPIKey), "secret")
+	assert.Equal(t, "ip:10.0.0.1", client(r, config.LimitByIP))
+
+	r = r.WithContext(identity.NewContext(r.Context(), &identity.Identity{UserID: 7}))
+	assert.Equal(t, "user:7", client(r, config.LimitByUser))
+}
+
+func TestRateLimit_LimiterUnavailable(t *testing.T) {
+	limit := config.Limit{By: config.LimitByIP, Requests: 1, Window: time.Minute}
+	h := RateLimit(failingLimiter{}, "tasks", limit, logger.New())(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	for i := 0; i < 2; i++ {
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/", nil))
+		assert.Equal(t, http.StatusOK, w.Code)
+	}
+}
@@ -0,0 +1,50 @@
+package middleware
+
+import (
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/henriqueassiss/advanced-golang-api/config"
+	"github.com/henriqueassiss/advanced-golang-api/internal/utils/persistence"
+	"github.com/henriqueassiss/advanced-golang-api/third_party/cache"
+)
+
+func safeMethod(method string) bool {
+	return method == http.MethodGet || method == http.MethodHead || method == http.MethodOptions
+}
+
+// ReadYourWrites pins a client to the primary database for window after it
+// sends a request that may write, so its reads do not miss what it wrote
+// while replicas catch up. The pin is set before the write, and kept in
+// store so every instance of the API honours it. Clients are users once
+// Authenticate ran and addresses otherwise. When store fails requests read
+// as usual.
+func ReadYourWrites(store cache.Cache, window time.Duration, logger *slog.Logger) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			ctx := r.Context()
+			key := "readyourwrites:" + client(r, config.LimitByUser)
+
+			if !safeMethod(r.Method) {
+				err := store.Set(ctx, key, []byte{1}, window)
+				if err != nil {
+					logger.Error(err.Error(), "key", key)
+				}
+
+				next.ServeHTTP(w, r.WithContext(persistence.Pin(ctx)))
+				return
+			}
+
+			_, err := store.Get(ctx, key)
+			switch {
+			case err == nil:
+				r = r.WithContext(persistence.Pin(ctx))
+			case err != cache.ErrNotFound:
+				logger.Error(err.Error(), "key", key)
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
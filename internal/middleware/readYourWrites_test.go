@@ -0,0 +1,41 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/henriqueassiss/advanced-golang-api/internal/utils/identity"
+	"github.com/henriqueassiss/advanced-golang-api/internal/utils/persistence"
+	"github.com/henriqueassiss/advanced-golang-api/third_party/cache"
+	"github.com/henriqueassiss/advanced-golang-api/third_party/logger"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReadYourWrites(t *testing.T) {
+	store := cache.NewMock(t)
+	var pinned bool
+
+	h := ReadYourWrites(store, time.Minute, logger.New())(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		pinned = persistence.Pinned(r.Context())
+	}))
+
+	request := func(method string, userID uint64) bool {
+		r := httptest.NewRequest(method, "/v1/task", nil)
+		r = r.WithContext(identity.NewContext(r.Context(), &identity.Identity{UserID: userID}))
+		h.ServeHTTP(httptest.NewRecorder(), r)
+		return pinned
+	}
+
+	assert.False(t, request(http.MethodGet, 1), "clients that did not write read from replicas")
+	assert.True(t, request(http.MethodPost, 1), "writes run on the primary")
+	assert.True(t, request(http.MethodGet, 1), "clients that wrote read from the primary")
+	assert.False(t, request(http.MethodGet, 2), "clients are pinned on their own")
+
+	ttl, err := store.TTL(context.TODO(), "readyourwrites:user:1")
+	assert.Nil(t, err)
+	assert.InDelta(t, time.Minute, ttl, float64(time.Second), "pins last for the window")
+}
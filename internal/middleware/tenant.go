@@ -0,0 +1,85 @@
+package middleware
+
+import (
+	"context"
+	"log/slog"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/henriqueassiss/advanced-golang-api/internal/utils/errorMsg"
+	"github.com/henriqueassiss/advanced-golang-api/internal/utils/identity"
+	"github.com/henriqueassiss/advanced-golang-api/internal/utils/reqRes"
+	"github.com/henriqueassiss/advanced-golang-api/internal/utils/tenant"
+)
+
+const WorkspaceHeader = "X-Workspace-ID"
+
+type TenantResolver interface {
+	Resolve(ctx context.Context, userID, workspaceID uint64, slug string) (uint64, error)
+}
+
+func subdomain(host, baseDomain string) string {
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		host = h
+	}
+
+	slug, found := strings.CutSuffix(host, "."+baseDomain)
+	if !found || strings.Contains(slug, ".") {
+		return ""
+	}
+
+	return slug
+}
+
+// Tenant resolves the workspace of the request from the X-Workspace-ID
+// header, the subdomain under baseDomain or the access token claim, in that
+// order. It must run after Authenticate.
+func Tenant(resolver TenantResolver, baseDomain string, logger *slog.Logger) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			i, ok := identity.FromContext(r.Context())
+			if !ok {
+				reqRes.Error(logger, w, http.StatusUnauthorized, errorMsg.ErrUnauthorized, r.URL.Path)
+				return
+			}
+
+			var workspaceID uint64
+			var slug string
+			if header := r.Header.Get(WorkspaceHeader); header != "" {
+				id, err := strconv.ParseUint(header, 10, 64)
+				if err != nil || id == 0 {
+					reqRes.Error(logger, w, http.StatusBadRequest, errorMsg.ErrInvalidRequestData, header)
+					return
+				}
+
+				workspaceID = id
+			} else if baseDomain != "" {
+				slug = subdomain(r.Host, baseDomain)
+			}
+
+			if workspaceID == 0 && slug == "" {
+				workspaceID = i.WorkspaceID
+			}
+
+			if workspaceID == 0 && slug == "" {
+				reqRes.Error(logger, w, http.StatusBadRequest, errorMsg.ErrTenantRequired, r.URL.Path)
+				return
+			}
+
+			workspaceID, err := resolver.Resolve(r.Context(), i.UserID, workspaceID, slug)
+			if err != nil {
+				if err == errorMsg.ErrForbidden {
+					reqRes.Error(logger, w, http.StatusForbidden, err, i.UserID)
+				} else {
+					reqRes.Error(logger, w, http.StatusInternalServerError, err, i.UserID)
+				}
+
+				return
+			}
+
+			next.ServeHTTP(w, r.WithContext(tenant.NewContext(r.Context(), workspaceID)))
+		})
+	}
+}
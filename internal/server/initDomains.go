@@ -2,20 +2,45 @@ package server
 
 import (
 	"context"
+	"database/sql"
+	"encoding/json"
 	"log"
+	"net/http"
+	"time"
 
+	sessionHandler "github.com/henriqueassiss/advanced-golang-api/internal/domain/session/handler"
+	sessionRepository "github.com/henriqueassiss/advanced-golang-api/internal/domain/session/repository"
+	sessionUseCase "github.com/henriqueassiss/advanced-golang-api/internal/domain/session/useCase"
+	ssoHandler "github.com/henriqueassiss/advanced-golang-api/internal/domain/sso/handler"
+	ssoRepository "github.com/henriqueassiss/advanced-golang-api/internal/domain/sso/repository"
+	ssoUseCase "github.com/henriqueassiss/advanced-golang-api/internal/domain/sso/useCase"
+	"github.com/henriqueassiss/advanced-golang-api/internal/domain/task"
 	taskHandler "github.com/henriqueassiss/advanced-golang-api/internal/domain/task/handler"
 	taskMock "github.com/henriqueassiss/advanced-golang-api/internal/domain/task/mock"
 	taskRepository "github.com/henriqueassiss/advanced-golang-api/internal/domain/task/repository"
 	taskUseCase "github.com/henriqueassiss/advanced-golang-api/internal/domain/task/useCase"
+	userHandler "github.com/henriqueassiss/advanced-golang-api/internal/domain/user/handler"
+	userRepository "github.com/henriqueassiss/advanced-golang-api/internal/domain/user/repository"
+	userUseCase "github.com/henriqueassiss/advanced-golang-api/internal/domain/user/useCase"
+	workspaceHandler "github.com/henriqueassiss/advanced-golang-api/internal/domain/workspace/handler"
+	workspaceRepository "github.com/henriqueassiss/advanced-golang-api/internal/domain/workspace/repository"
+	workspaceUseCase "github.com/henriqueassiss/advanced-golang-api/internal/domain/workspace/useCase"
+	"github.com/henriqueassiss/advanced-golang-api/internal/middleware"
 	"github.com/henriqueassiss/advanced-golang-api/internal/utils/errorMsg"
+	"github.com/henriqueassiss/advanced-golang-api/internal/utils/persistence"
+	"github.com/henriqueassiss/advanced-golang-api/third_party/oidc"
+	"github.com/henriqueassiss/advanced-golang-api/third_party/token"
+
+	db "github.com/henriqueassiss/advanced-golang-api/third_party/database"
 	"github.com/jwalton/gchalk"
 )
 
 func (s *Server) InitDomains() {
 	log.Println(gchalk.Yellow("Domain: starting"))
 
-	s.initAuthentication()
+	authenticate := s.initAuthentication()
+	tenant := s.initWorkspace(authenticate)
+	s.initTask(authenticate, tenant)
 
 	log.Println(gchalk.Blue("Domain: done"))
 
@@ -35,8 +60,105 @@ func (s *Server) InitMocks() {
 	log.Println(gchalk.Blue("Mock: done"))
 }
 
-func (s *Server) initAuthentication() {
+func (s *Server) initAuthentication() func(http.Handler) http.Handler {
+	newUserRepo := userRepository.New(s.sqlx)
+	s.readFromReplicas(newUserRepo)
+	s.cacheStatements(newUserRepo)
+	newUserUseCase := userUseCase.New(newUserRepo, s.logger)
+	userHandler.RegisterHTTPEndPoints(newUserUseCase, s.logger, s.router, s.rateLimit("users", s.cfg.RateLimit.Users), s.readYourWrites())
+
+	newSessionRepo := sessionRepository.New(s.store, s.cfg.Auth.SessionTTL)
+	newTokenManager := token.New(s.cfg.Api.Secret, s.cfg.Auth.AccessTokenTTL)
+	newSessionUseCase := sessionUseCase.New(newSessionRepo, newUserUseCase, newTokenManager, s.logger)
+	sessionHandler.RegisterHTTPEndPoints(newSessionUseCase, s.logger, s.router, s.rateLimit("sessions", s.cfg.RateLimit.Sessions), s.readYourWrites())
+
+	if s.cfg.OIDC.Issuer != "" {
+		s.initSSO(newUserUseCase, newSessionUseCase)
+	}
+
+	return middleware.Authenticate(newSessionUseCase, s.logger)
+}
+
+func (s *Server) initSSO(user userUseCase.IUser, session sessionUseCase.ISession) {
+	newProvider := oidc.New(oidc.Config{
+		Issuer:       s.cfg.OIDC.Issuer,
+		ClientID:     s.cfg.OIDC.ClientID,
+		ClientSecret: s.cfg.OIDC.ClientSecret,
+		RedirectURL:  s.cfg.OIDC.RedirectURL,
+		Scopes:       s.cfg.OIDC.Scopes,
+		JWKSCacheTTL: s.cfg.OIDC.JWKSCacheTTL,
+	}, &http.Client{Timeout: 10 * time.Second})
+
+	newWorkspaceUseCase := workspaceUseCase.New(workspaceRepository.New(s.sqlx), s.logger)
+	newSSORepo := ssoRepository.New(s.store, s.cfg.OIDC.LoginTTL)
+	newSSOUseCase := ssoUseCase.New(newSSORepo, newProvider, user, newWorkspaceUseCase, session, s.cfg.OIDC, s.logger)
+	ssoHandler.RegisterHTTPEndPoints(newSSOUseCase, s.logger, s.router, s.rateLimit("sso", s.cfg.RateLimit.SSO), s.readYourWrites())
+}
+
+func (s *Server) initWorkspace(authenticate func(http.Handler) http.Handler) func(http.Handler) http.Handler {
+	newWorkspaceRepo := workspaceRepository.New(s.sqlx)
+	newWorkspaceUseCase := workspaceUseCase.New(newWorkspaceRepo, s.logger)
+	workspaceHandler.RegisterHTTPEndPoints(newWorkspaceUseCase, s.logger, s.router, authenticate, s.rateLimit("workspaces", s.cfg.RateLimit.Workspaces), s.readYourWrites())
+
+	return middleware.Tenant(newWorkspaceUseCase, s.cfg.App.BaseDomain, s.logger)
+}
+
+func (s *Server) initTask(authenticate, tenant func(http.Handler) http.Handler) {
 	newTaskRepo := taskRepository.New(s.sqlx)
-	newTaskUseCase := taskUseCase.New(newTaskRepo, s.logger, s.cache)
-	taskHandler.RegisterHTTPEndPoints(newTaskUseCase, s.logger, s.router)
+	s.readFromReplicas(newTaskRepo)
+	s.cacheStatements(newTaskRepo)
+	newTxManager := persistence.NewTxManager(s.sqlx, sql.LevelDefault, s.cfg.Database.TxRetries)
+	newTaskUseCase := taskUseCase.New(newTaskRepo, newTxManager, s.logger, s.cache, s.cfg.Cache.TaskTTL, s.cfg.Cache.TaskStaleTTL)
+
+	// A shared cache is invalidated by the replica that writes, but each
+	// in-memory cache has to hear of writes made elsewhere. Only Postgres
+	// announces them.
+	if s.redis == nil && db.DialectOf(s.sqlx) == db.Postgres {
+		s.background.Add(1)
+		go func() {
+			defer s.background.Done()
+			s.listenTaskChanges(newTaskUseCase)
+		}()
+	}
+	taskHandler.RegisterHTTPEndPoints(newTaskUseCase, s.logger, s.router, authenticate, s.rateLimit("tasks", s.cfg.RateLimit.Tasks), tenant, s.readYourWrites(), middleware.Idempotent(s.store, s.cfg.Cache.IdempotencyTTL, s.logger))
+}
+
+// readFromReplicas makes repo read from the replicas, if there are any.
+func (s *Server) readFromReplicas(repo interface{ ReadFrom(*persistence.Replicas) }) {
+	if s.replicas != nil {
+		repo.ReadFrom(s.replicas)
+	}
+}
+
+// cacheStatements makes repo prepare its queries once, if statements are
+// cached.
+func (s *Server) cacheStatements(repo interface {
+	CacheStatements(*persistence.Statements)
+}) {
+	if s.statements != nil {
+		repo.CacheStatements(s.statements)
+	}
+}
+
+func (s *Server) listenTaskChanges(uc *taskUseCase.Task) {
+	dsn, err := db.DSN(s.cfg.Database)
+	if err != nil {
+		s.logger.Error(err.Error())
+		return
+	}
+
+	ctx := s.ctx
+	listener := db.NewListener(dsn, "task_changes", s.logger)
+	listener.Listen(ctx, func(payload string) {
+		var c task.Change
+		err := json.Unmarshal([]byte(payload), &c)
+		if err != nil {
+			s.logger.Error(err.Error(), "payload", payload)
+			return
+		}
+
+		uc.Evict(ctx, c)
+	}, func() {
+		uc.EvictAll(ctx)
+	})
 }
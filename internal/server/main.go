@@ -8,13 +8,16 @@ import (
 	"net/http"
 	"os"
 	"os/signal"
+	"sync"
 	"syscall"
 	"time"
 
 	"github.com/henriqueassiss/advanced-golang-api/config"
 	"github.com/henriqueassiss/advanced-golang-api/internal/middleware"
+	"github.com/henriqueassiss/advanced-golang-api/internal/utils/persistence"
 	"github.com/henriqueassiss/advanced-golang-api/third_party/cache"
 	"github.com/henriqueassiss/advanced-golang-api/third_party/logger"
+	"github.com/henriqueassiss/advanced-golang-api/third_party/ratelimit"
 
 	db "github.com/henriqueassiss/advanced-golang-api/third_party/database"
 
@@ -31,13 +34,30 @@ type Server struct {
 	cfg     *config.Config
 	logger  *slog.Logger
 
-	cache *redis.Client
-	sqlx  *sqlx.DB
+	cache cache.Cache
+	// store keeps sessions, single sign-on logins, idempotency keys and
+	// read-your-writes pins. Over Redis it is cache; in memory it is an LRU
+	// of its own, so task reads cannot evict them.
+	store    cache.Cache
+	redis    redis.UniversalClient
+	limiter  ratelimit.Limiter
+	sqlx     *sqlx.DB
+	replicas *persistence.Replicas
+	// instrumentation watches the statements run on sqlx and replicas.
+	instrumentation *db.Instrumentation
+	// statements caches the statements repositories prepare, when enabled.
+	statements *persistence.Statements
 
 	cors   *cors.Cors
 	router *chi.Mux
 
 	httpServer *http.Server
+
+	// ctx lives as long as the server: stop cancels it on shutdown, which
+	// then waits for the goroutines in background to return.
+	ctx        context.Context
+	stop       context.CancelFunc
+	background sync.WaitGroup
 }
 
 type Options func(opts *Server) error
@@ -64,9 +84,13 @@ func WithVersion(version string) Options {
 }
 
 func defaultServer() *Server {
+	ctx, stop := context.WithCancel(context.Background())
+
 	return &Server{
 		cfg:    config.New(false, false),
 		router: chi.NewRouter(),
+		ctx:    ctx,
+		stop:   stop,
 	}
 }
 
@@ -74,6 +98,7 @@ func (s *Server) Init() {
 	s.newLogger()
 	s.setCors()
 	s.newCache()
+	s.newRateLimiter()
 	s.newDatabase()
 	s.newRouter()
 	s.setGlobalMiddleware()
@@ -110,16 +135,68 @@ func (s *Server) setCors() {
 func (s *Server) newCache() {
 	log.Println(gchalk.Yellow("Cache: starting"))
 
-	if s.cfg.Cache.Address == "" {
-		s.logger.Error("Please, fill in redis credentials in .env file or set in environment variable")
-		GracefulShutdown(context.Background(), s)
+	cfg := s.cfg.Cache
+	if cfg.Driver != "memory" && len(cfg.Address) == 0 {
+		s.logger.Warn("No redis address set, falling back to in-memory caches: tasks, sessions and idempotency keys are not shared between replicas")
+		cfg.Driver = "memory"
 	}
 
-	s.cache = cache.New(s.cfg.Cache)
+	switch cfg.Driver {
+	case "memory":
+		s.cache = cache.NewMemory(cfg.MaxEntries, cfg.MaxBytes)
+		s.store = cache.NewMemory(cfg.MaxEntries, cfg.MaxBytes)
+	case "redis":
+		client, err := cache.New(cfg)
+		if err != nil {
+			s.logger.Error(err.Error())
+			GracefulShutdown(context.Background(), s)
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), cfg.DialTimeout)
+		err = client.Ping(ctx).Err()
+		cancel()
+		if err != nil {
+			s.logger.Error("Could not reach redis: "+err.Error(), "mode", cfg.Mode, "address", cfg.Address)
+			GracefulShutdown(context.Background(), s)
+		}
+
+		s.redis = client
+		s.cache = cache.NewRedis(s.redis)
+		s.store = s.cache
+	default:
+		s.logger.Error("Unknown cache driver", "driver", cfg.Driver)
+		GracefulShutdown(context.Background(), s)
+	}
 
 	log.Println(gchalk.Blue("Cache: done"))
 }
 
+func (s *Server) newRateLimiter() {
+	log.Println(gchalk.Yellow("Rate limiter: starting"))
+
+	switch {
+	case !s.cfg.RateLimit.Enabled:
+		s.logger.Warn("Rate limiting is disabled")
+	case s.redis != nil:
+		s.limiter = ratelimit.NewRedis(s.redis)
+	default:
+		s.logger.Warn("No redis to share rate limits, each replica enforces them on its own")
+		s.limiter = ratelimit.NewMemory()
+	}
+
+	log.Println(gchalk.Blue("Rate limiter: done"))
+}
+
+// rateLimit limits a route group, or does nothing when rate limiting is
+// disabled.
+func (s *Server) rateLimit(group string, limit config.Limit) func(http.Handler) http.Handler {
+	if s.limiter == nil {
+		return func(next http.Handler) http.Handler { return next }
+	}
+
+	return middleware.RateLimit(s.limiter, group, limit, s.logger)
+}
+
 func (s *Server) newDatabase() {
 	log.Println(gchalk.Yellow("Database: starting"))
 
@@ -128,7 +205,18 @@ func (s *Server) newDatabase() {
 		GracefulShutdown(context.Background(), s)
 	}
 
-	db, err := db.NewSqlx(s.cfg.Database)
+	s.instrumentation = &db.Instrumentation{
+		Logger:    s.logger,
+		SlowQuery: s.cfg.Database.SlowQueryThreshold,
+		Timeout:   s.cfg.Database.QueryTimeout,
+		Explain:   s.cfg.Database.ExplainSlowQueries && s.cfg.App.Environment == "development",
+	}
+
+	if s.cfg.Database.ErrorStatsInterval > 0 {
+		go s.instrumentation.Report(context.Background(), s.cfg.Database.ErrorStatsInterval)
+	}
+
+	db, err := db.NewSqlx(s.cfg.Database, s.instrumentation)
 	if err != nil {
 		s.logger.Error(err.Error())
 		GracefulShutdown(context.Background(), s)
@@ -139,9 +227,52 @@ func (s *Server) newDatabase() {
 	s.sqlx.SetMaxIdleConns(s.cfg.Database.MaxIdleConnections)
 	s.sqlx.SetConnMaxLifetime(s.cfg.Database.ConnectionsMaxLifeTime)
 
+	if len(s.cfg.Database.Replicas) > 0 {
+		s.newReplicas()
+	}
+
+	if s.cfg.Database.StatementCacheSize > 0 {
+		s.newStatements()
+	}
+
 	log.Println(gchalk.Blue("Database: done"))
 }
 
+func (s *Server) newReplicas() {
+	replicas, err := db.NewSqlxReplicas(s.cfg.Database, s.instrumentation)
+	if err != nil {
+		s.logger.Error(err.Error())
+		GracefulShutdown(context.Background(), s)
+	}
+
+	for _, replica := range replicas {
+		replica.SetMaxOpenConns(s.cfg.Database.MaxConnectionPool)
+		replica.SetMaxIdleConns(s.cfg.Database.MaxIdleConnections)
+		replica.SetConnMaxLifetime(s.cfg.Database.ConnectionsMaxLifeTime)
+	}
+
+	s.replicas = persistence.NewReplicas(s.sqlx, replicas...)
+	s.replicas.Check(context.Background(), s.logger)
+	go s.replicas.Watch(context.Background(), s.cfg.Database.ReplicaCheckInterval, s.logger)
+}
+
+func (s *Server) newStatements() {
+	s.statements = persistence.NewStatements(s.cfg.Database.StatementCacheSize)
+	if s.cfg.Database.StatementStatsInterval > 0 {
+		go s.statements.Report(context.Background(), s.cfg.Database.StatementStatsInterval, s.logger)
+	}
+}
+
+// readYourWrites pins clients that write to the primary, or does nothing
+// when there are no replicas to read from.
+func (s *Server) readYourWrites() func(http.Handler) http.Handler {
+	if s.replicas == nil {
+		return func(next http.Handler) http.Handler { return next }
+	}
+
+	return middleware.ReadYourWrites(s.store, s.cfg.Database.ReadYourWritesWindow, s.logger)
+}
+
 func (s *Server) newRouter() {
 	log.Println(gchalk.Yellow("Router: starting"))
 
@@ -217,5 +348,19 @@ func GracefulShutdown(ctx context.Context, s *Server) error {
 		s.logger.Error(err.Error())
 	}
 
+	s.stop()
+
+	stopped := make(chan struct{})
+	go func() {
+		s.background.Wait()
+		close(stopped)
+	}()
+
+	select {
+	case <-stopped:
+	case <-ctx.Done():
+		s.logger.Error("Background work did not stop in time")
+	}
+
 	return nil
 }
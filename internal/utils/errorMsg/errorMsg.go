@@ -3,6 +3,19 @@ package errorMsg
 import "errors"
 
 var (
-	ErrTableIsPopulated   = errors.New("run-time: table is already populated")
-	ErrInvalidRequestData = errors.New("run-time: invalid request data")
+	ErrTableIsPopulated     = errors.New("run-time: table is already populated")
+	ErrInvalidRequestData   = errors.New("run-time: invalid request data")
+	ErrUnauthorized         = errors.New("run-time: unauthorized")
+	ErrInvalidCredentials   = errors.New("run-time: invalid credentials")
+	ErrSessionNotFound      = errors.New("run-time: session not found")
+	ErrRefreshTokenReused   = errors.New("run-time: refresh token reused")
+	ErrForbidden            = errors.New("run-time: forbidden")
+	ErrTenantRequired       = errors.New("run-time: workspace is required")
+	ErrNotWorkspaceMember   = errors.New("run-time: user is not a member of the workspace")
+	ErrEmailNotVerified     = errors.New("run-time: email is not verified by the identity provider")
+	ErrLoginExpired         = errors.New("run-time: login expired or already completed")
+	ErrTooManyRequests      = errors.New("run-time: too many requests")
+	ErrRequestInProgress    = errors.New("run-time: a request with this idempotency key is in progress")
+	ErrIdempotencyKeyReused = errors.New("run-time: idempotency key reused with a different request")
+	ErrNothingToUpdate      = errors.New("run-time: nothing to update")
 )
@@ -0,0 +1,20 @@
+package identity
+
+import "context"
+
+type contextKey struct{}
+
+type Identity struct {
+	UserID      uint64
+	SessionID   string
+	WorkspaceID uint64
+}
+
+func NewContext(ctx context.Context, i *Identity) context.Context {
+	return context.WithValue(ctx, contextKey{}, i)
+}
+
+func FromContext(ctx context.Context) (*Identity, bool) {
+	i, ok := ctx.Value(contextKey{}).(*Identity)
+	return i, ok && i != nil
+}
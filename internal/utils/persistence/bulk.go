@@ -0,0 +1,258 @@
+package persistence
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/henriqueassiss/advanced-golang-api/third_party/database"
+	"github.com/jackc/pgx/v5"
+	"github.com/jmoiron/sqlx"
+)
+
+const (
+	defaultChunk = 5000
+	// maxParams keeps the multi-row INSERTs under the placeholder limit of
+	// every supported database, SQLite builds before 3.32 having the lowest.
+	maxParams = 999
+)
+
+var errNoCopy = errors.New("persistence: the connection cannot copy")
+
+// Bulk describes rows to load into a table.
+type Bulk struct {
+	Table   string
+	Columns []string
+	// Conflict names the columns of a unique key of Table. Rows clashing on
+	// it with stored rows are skipped, or overwrite their Update columns.
+	// Without it, a clash fails the load.
+	Conflict []string
+	Update   []string
+	// Chunk is how many rows are committed at once, 5000 by default.
+	Chunk int
+	// Progress, when set, is called after each chunk is committed with how
+	// many rows of the source were loaded so far, skipped ones included.
+	Progress func(loaded int64)
+}
+
+// Source yields the rows to load, their values in the order of Columns,
+// and a nil row once there are no more.
+type Source func() ([]any, error)
+
+// Rows yields rows in order.
+func Rows(rows [][]any) Source {
+	i := 0
+	return func() ([]any, error) {
+		if i == len(rows) {
+			return nil, nil
+		}
+
+		i++
+		return rows[i-1], nil
+	}
+}
+
+type loader struct {
+	Bulk
+	src             Source
+	instrumentation *database.Instrumentation
+	loaded          int64
+	written         int64
+}
+
+// beginner starts the transactions of COPY chunks; a *pgx.Conn.
+type beginner interface {
+	Begin(ctx context.Context) (pgx.Tx, error)
+}
+
+// Load writes the rows of src to b.Table and returns how many it wrote.
+// Over pgx it copies them with the COPY protocol, through a staging table
+// when there is a conflict to handle; elsewhere it inserts them many at a
+// time. Each chunk commits in a transaction of its own, not in the one ctx
+// may carry, so a failure keeps the chunks loaded before it.
+func Load(ctx context.Context, db *sqlx.DB, b Bulk, src Source) (int64, error) {
+	if b.Chunk <= 0 {
+		b.Chunk = defaultChunk
+	}
+
+	l := &loader{Bulk: b, src: src}
+	err := l.copy(ctx, db)
+	if err == errNoCopy {
+		err = l.insert(ctx, db)
+	}
+
+	return l.written, err
+}
+
+// chunk reads the next chunk of rows from the source.
+func (l *loader) chunk() ([][]any, error) {
+	var rows [][]any
+	for len(rows) < l.Chunk {
+		row, err := l.src()
+		if err != nil || row == nil {
+			return rows, err
+		}
+
+		if len(row) != len(l.Columns) {
+			return nil, fmt.Errorf("persistence: row %d has %d values for %d columns", l.loaded+int64(len(rows))+1, len(row), len(l.Columns))
+		}
+
+		rows = append(rows, row)
+	}
+
+	return rows, nil
+}
+
+func (l *loader) done(rows [][]any, written int64) {
+	l.loaded += int64(len(rows))
+	l.written += written
+
+	if l.Progress != nil {
+		l.Progress(l.loaded)
+	}
+}
+
+// run loads the source chunk by chunk through load.
+func (l *loader) run(load func(rows [][]any) (int64, error)) error {
+	for {
+		rows, err := l.chunk()
+		if err != nil || len(rows) == 0 {
+			return err
+		}
+
+		written, err := load(rows)
+		if err != nil {
+			return err
+		}
+
+		l.done(rows, written)
+	}
+}
+
+// copy loads the source with COPY, or fails with errNoCopy when db is not
+// opened through pgx.
+func (l *loader) copy(ctx context.Context, db *sqlx.DB) error {
+	conn, err := db.Conn(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	return conn.Raw(func(driverConn any) error {
+		pgxConn, ok := database.PgxConn(driverConn)
+		if !ok {
+			return errNoCopy
+		}
+
+		// COPY runs past database/sql, so it is watched by hand.
+		l.instrumentation = database.InstrumentationOf(driverConn)
+
+		return l.run(func(rows [][]any) (int64, error) {
+			return l.copyChunk(ctx, pgxConn, rows)
+		})
+	})
+}
+
+func (l *loader) copyChunk(ctx context.Context, conn beginner, rows [][]any) (written int64, err error) {
+	d := database.Postgres
+	ctx, done := l.instrumentation.Track(ctx, fmt.Sprintf("COPY %s (%s) FROM STDIN", d.Quote(l.Table), quote(d, l.Columns)))
+	defer func() { done(err) }()
+
+	tx, err := conn.Begin(ctx)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback(ctx)
+
+	if len(l.Conflict) == 0 {
+		written, err = tx.CopyFrom(ctx, pgx.Identifier{l.Table}, l.Columns, pgx.CopyFromRows(rows))
+	} else {
+		written, err = l.stage(ctx, tx, rows)
+	}
+	if err != nil {
+		return 0, err
+	}
+
+	return written, tx.Commit(ctx)
+}
+
+// stage copies rows to a temporary table dropped on commit and moves them
+// to the table from there, handling their conflicts as an INSERT does. Of
+// the rows sharing a key, the last one copied is kept.
+func (l *loader) stage(ctx context.Context, tx pgx.Tx, rows [][]any) (int64, error) {
+	d := database.Postgres
+	table, staging := d.Quote(l.Table), d.Quote("staging_"+l.Table)
+	columns, conflict := quote(d, l.Columns), quote(d, l.Conflict)
+
+	_, err := tx.Exec(ctx, fmt.Sprintf("CREATE TEMPORARY TABLE %s ON COMMIT DROP AS SELECT %s FROM %s WITH NO DATA", staging, columns, table))
+	if err != nil {
+		return 0, err
+	}
+
+	_, err = tx.CopyFrom(ctx, pgx.Identifier{"staging_" + l.Table}, l.Columns, pgx.CopyFromRows(rows))
+	if err != nil {
+		return 0, err
+	}
+
+	tag, err := tx.Exec(ctx, fmt.Sprintf("INSERT INTO %s (%s) SELECT DISTINCT ON (%s) %s FROM %s ORDER BY %s, ctid DESC %s",
+		table, columns, conflict, columns, staging, conflict, d.Upsert(l.Conflict, l.Update)))
+
+	return tag.RowsAffected(), err
+}
+
+// insert loads the source with INSERTs of as many rows as the placeholder
+// limit allows.
+func (l *loader) insert(ctx context.Context, db *sqlx.DB) error {
+	d := database.DialectOf(db)
+
+	upsert := ""
+	if len(l.Conflict) != 0 {
+		upsert = " " + d.Upsert(l.Conflict, l.Update)
+	}
+
+	placeholders := "(" + strings.TrimSuffix(strings.Repeat("?, ", len(l.Columns)), ", ") + ")"
+	batch := max(maxParams/len(l.Columns), 1)
+
+	return l.run(func(rows [][]any) (int64, error) {
+		tx, err := db.BeginTxx(ctx, nil)
+		if err != nil {
+			return 0, err
+		}
+		defer tx.Rollback()
+
+		var written int64
+		for start := 0; start < len(rows); start += batch {
+			part := rows[start:min(start+batch, len(rows))]
+
+			values := make([]string, len(part))
+			args := make([]any, 0, len(part)*len(l.Columns))
+			for i, row := range part {
+				values[i] = placeholders
+				args = append(args, row...)
+			}
+
+			query := fmt.Sprintf("INSERT INTO %s (%s) VALUES %s%s", d.Quote(l.Table), quote(d, l.Columns), strings.Join(values, ", "), upsert)
+
+			result, err := tx.ExecContext(ctx, d.Rebind(query), args...)
+			if err != nil {
+				return 0, err
+			}
+
+			affected, _ := result.RowsAffected()
+			written += affected
+		}
+
+		return written, tx.Commit()
+	})
+}
+
+// quote quotes idents and joins them into a list.
+func quote(d database.Dialect, idents []string) string {
+	qs := make([]string, len(idents))
+	for i, ident := range idents {
+		qs[i] = d.Quote(ident)
+	}
+
+	return strings.Join(qs, ", ")
+}
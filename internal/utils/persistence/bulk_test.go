@@ -0,0 +1,172 @@
+package persistence
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/henriqueassiss/advanced-golang-api/third_party/database"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLoad(t *testing.T) {
+	ctx := context.TODO()
+
+	t.Run("Rows are loaded in chunks", func(t *testing.T) {
+		db := openSqlite(t)
+
+		rows := make([][]any, 1200)
+		for i := range rows {
+			rows[i] = []any{i + 1, fmt.Sprint("Note ", i+1)}
+		}
+
+		var progress []int64
+		written, err := Load(ctx, db, Bulk{
+			Table:    "notes",
+			Columns:  []string{"key", "body"},
+			Chunk:    500,
+			Progress: func(loaded int64) { progress = append(progress, loaded) },
+		}, Rows(rows))
+		assert.Nil(t, err)
+		assert.Equal(t, int64(1200), written)
+		assert.Equal(t, []int64{500, 1000, 1200}, progress)
+
+		var count int
+		assert.Nil(t, db.Get(&count, "SELECT count(*) FROM notes"))
+		assert.Equal(t, 1200, count, "chunks over the placeholder limit are split")
+	})
+
+	t.Run("Conflicts are skipped or updated", func(t *testing.T) {
+		db := openSqlite(t)
+		_, err := db.Exec("INSERT INTO notes (key, body) VALUES (1, 'Stored'), (2, 'Stored')")
+		assert.Nil(t, err)
+
+		b := Bulk{Table: "notes", Columns: []string{"key", "body"}, Conflict: []string{"key"}}
+		written, err := Load(ctx, db, b, Rows([][]any{{1, "Loaded"}, {3, "Loaded"}}))
+		assert.Nil(t, err)
+		assert.Equal(t, int64(1), written, "rows clashing with stored ones are skipped")
+
+		b.Update = []string{"body"}
+		written, err = Load(ctx, db, b, Rows([][]any{{2, "Loaded"}}))
+		assert.Nil(t, err)
+		assert.Equal(t, int64(1), written)
+
+		var bodies []string
+		assert.Nil(t, db.Select(&bodies, "SELECT body FROM notes ORDER BY key"))
+		assert.Equal(t, []string{"Stored", "Loaded", "Loaded"}, bodies)
+	})
+
+	t.Run("Failures keep the chunks loaded before", func(t *testing.T) {
+		db := openSqlite(t)
+
+		written, err := Load(ctx, db, Bulk{Table: "notes", Columns: []string{"key", "body"}, Chunk: 2},
+			Rows([][]any{{1, "First"}, {2, "Second"}, {3, "Third"}, {1, "Clash"}}))
+		assert.NotNil(t, err)
+		assert.Equal(t, int64(2), written)
+
+		var count int
+		assert.Nil(t, db.Get(&count, "SELECT count(*) FROM notes"))
+		assert.Equal(t, 2, count)
+
+		_, err = Load(ctx, db, Bulk{Table: "notes", Columns: []string{"key", "body"}}, Rows([][]any{{4}}))
+		assert.EqualError(t, err, "persistence: row 1 has 1 values for 2 columns")
+	})
+}
+
+// fakeTx records what a COPY chunk sends to Postgres.
+type fakeTx struct {
+	pgx.Tx
+	execs     []string
+	copies    map[string][][]any
+	committed bool
+}
+
+func (tx *fakeTx) Begin(ctx context.Context) (pgx.Tx, error) {
+	tx.copies = map[string][][]any{}
+	return tx, nil
+}
+
+func (tx *fakeTx) Exec(ctx context.Context, sql string, args ...any) (pgconn.CommandTag, error) {
+	tx.execs = append(tx.execs, sql)
+	return pgconn.NewCommandTag("INSERT 0 2"), nil
+}
+
+func (tx *fakeTx) CopyFrom(ctx context.Context, table pgx.Identifier, columns []string, src pgx.CopyFromSource) (int64, error) {
+	name := table.Sanitize()
+	for src.Next() {
+		values, err := src.Values()
+		if err != nil {
+			return 0, err
+		}
+
+		tx.copies[name] = append(tx.copies[name], values)
+	}
+
+	return int64(len(tx.copies[name])), src.Err()
+}
+
+func (tx *fakeTx) Commit(ctx context.Context) error {
+	tx.committed = true
+	return nil
+}
+
+func (tx *fakeTx) Rollback(ctx context.Context) error {
+	return nil
+}
+
+func TestLoader_CopyChunk(t *testing.T) {
+	ctx := context.TODO()
+	rows := [][]any{{1, "First"}, {1, "Again"}, {2, "Second"}}
+
+	t.Run("Rows without conflicts are copied to the table", func(t *testing.T) {
+		tx := &fakeTx{}
+		l := &loader{Bulk: Bulk{Table: "notes", Columns: []string{"key", "body"}}}
+
+		written, err := l.copyChunk(ctx, tx, rows)
+		assert.Nil(t, err)
+		assert.Equal(t, int64(3), written)
+		assert.Equal(t, map[string][][]any{`"notes"`: rows}, tx.copies)
+		assert.Empty(t, tx.execs)
+		assert.True(t, tx.committed)
+	})
+
+	staged := func(t *testing.T, update []string, onConflict string) {
+		tx := &fakeTx{}
+		l := &loader{Bulk: Bulk{Table: "notes", Columns: []string{"key", "body"}, Conflict: []string{"key"}, Update: update}}
+
+		written, err := l.copyChunk(ctx, tx, rows)
+		assert.Nil(t, err)
+		assert.Equal(t, int64(2), written, "rows written are the ones moved over")
+		assert.Equal(t, map[string][][]any{`"staging_notes"`: rows}, tx.copies, "every row is staged, duplicates included")
+		assert.Equal(t, []string{
+			`CREATE TEMPORARY TABLE "staging_notes" ON COMMIT DROP AS SELECT "key", "body" FROM "notes" WITH NO DATA`,
+			`INSERT INTO "notes" ("key", "body") SELECT DISTINCT ON ("key") "key", "body" FROM "staging_notes" ORDER BY "key", ctid DESC ` + onConflict,
+		}, tx.execs, "of the rows sharing a key, the last staged is moved over")
+		assert.True(t, tx.committed)
+	}
+
+	t.Run("Conflicting rows are skipped", func(t *testing.T) {
+		staged(t, nil, `ON CONFLICT ("key") DO NOTHING`)
+	})
+
+	t.Run("Conflicting rows are updated", func(t *testing.T) {
+		staged(t, []string{"body"}, `ON CONFLICT ("key") DO UPDATE SET "body" = EXCLUDED."body"`)
+	})
+
+	t.Run("Chunks are instrumented", func(t *testing.T) {
+		var logs bytes.Buffer
+		l := &loader{
+			Bulk:            Bulk{Table: "notes", Columns: []string{"key", "body"}},
+			instrumentation: &database.Instrumentation{Logger: slog.New(slog.NewTextHandler(&logs, nil)), SlowQuery: time.Nanosecond},
+		}
+
+		_, err := l.copyChunk(ctx, &fakeTx{}, rows)
+		assert.Nil(t, err)
+		assert.Contains(t, logs.String(), `msg="Slow query" query="COPY \"notes\" (\"key\", \"body\") FROM STDIN"`)
+	})
+}
@@ -0,0 +1,104 @@
+package persistence
+
+import (
+	"context"
+	"log/slog"
+	"sync/atomic"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+)
+
+type pinKey struct{}
+
+// Pin makes reads through ctx go to the primary, so a client that just wrote
+// sees its writes before the replicas catch up.
+func Pin(ctx context.Context) context.Context {
+	return context.WithValue(ctx, pinKey{}, true)
+}
+
+// Pinned reports whether reads through ctx go to the primary.
+func Pinned(ctx context.Context) bool {
+	pinned, _ := ctx.Value(pinKey{}).(bool)
+	return pinned
+}
+
+type replica struct {
+	db      *sqlx.DB
+	healthy atomic.Bool
+}
+
+// Replicas spreads reads over the read replicas of a primary.
+type Replicas struct {
+	primary  *sqlx.DB
+	replicas []*replica
+	next     atomic.Uint64
+	timeout  time.Duration
+}
+
+// NewReplicas reads from replicas, taken in turn, once Check finds them
+// healthy.
+func NewReplicas(primary *sqlx.DB, replicas ...*sqlx.DB) *Replicas {
+	rs := &Replicas{primary: primary, timeout: 2 * time.Second}
+	for _, db := range replicas {
+		rs.replicas = append(rs.replicas, &replica{db: db})
+	}
+
+	return rs
+}
+
+// Reader returns where to read through ctx: the transaction it carries, the
+// primary when it is pinned, or else the next healthy replica. The primary
+// is read from when no replica is healthy.
+func (rs *Replicas) Reader(ctx context.Context) Executor {
+	if _, ok := ctx.Value(contextKey{}).(*transaction); ok || Pinned(ctx) {
+		return Conn(ctx, rs.primary)
+	}
+
+	n := uint64(len(rs.replicas))
+	start := rs.next.Add(1)
+	for i := uint64(0); i < n; i++ {
+		r := rs.replicas[(start+i)%n]
+		if r.healthy.Load() {
+			return r.db
+		}
+	}
+
+	return rs.primary
+}
+
+// Check pings every replica and marks it healthy when it answers, logging
+// the ones that change.
+func (rs *Replicas) Check(ctx context.Context, logger *slog.Logger) {
+	for i, r := range rs.replicas {
+		pingCtx, cancel := context.WithTimeout(ctx, rs.timeout)
+		err := r.db.PingContext(pingCtx)
+		cancel()
+
+		healthy := err == nil
+		if r.healthy.Swap(healthy) == healthy {
+			continue
+		}
+
+		if healthy {
+			logger.Info("Read replica is up", "replica", i)
+		} else {
+			logger.Warn("Read replica is down: "+err.Error(), "replica", i)
+		}
+	}
+}
+
+// Watch checks the replicas every interval until ctx is done.
+func (rs *Replicas) Watch(ctx context.Context, interval time.Duration, logger *slog.Logger) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			rs.Check(ctx, logger)
+		}
+	}
+}
@@ -0,0 +1,91 @@
+package persistence
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	"github.com/henriqueassiss/advanced-golang-api/internal/utils/schema"
+	"github.com/henriqueassiss/advanced-golang-api/third_party/logger"
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/assert"
+)
+
+func openSqlite(t *testing.T) *sqlx.DB {
+	db, err := sqlx.Open("sqlite3", ":memory:")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Every connection to :memory: opens a database of its own.
+	db.SetMaxOpenConns(1)
+	t.Cleanup(func() { db.Close() })
+
+	_, err = db.Exec("CREATE TABLE notes (key INTEGER PRIMARY KEY, body TEXT)")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return db
+}
+
+func TestReplicas_Reader(t *testing.T) {
+	primary, first, second := openSqlite(t), openSqlite(t), openSqlite(t)
+	rs := NewReplicas(primary, first, second)
+	ctx := context.TODO()
+
+	assert.Equal(t, primary, rs.Reader(ctx), "replicas are read from once checked")
+
+	rs.Check(ctx, logger.New())
+	reads := []Executor{rs.Reader(ctx), rs.Reader(ctx), rs.Reader(ctx)}
+	assert.Contains(t, []Executor{first, second}, reads[0])
+	assert.NotEqual(t, reads[0], reads[1], "replicas are taken in turn")
+	assert.Equal(t, reads[0], reads[2])
+
+	assert.Equal(t, primary, rs.Reader(Pin(ctx)), "pinned clients read from the primary")
+
+	err := NewTxManager(primary, sql.LevelDefault, 0).Run(ctx, func(ctx context.Context) error {
+		assert.Equal(t, Conn(ctx, primary), rs.Reader(ctx), "reads in a transaction join it")
+		return nil
+	})
+	assert.Nil(t, err)
+
+	second.Close()
+	rs.Check(ctx, logger.New())
+	assert.Equal(t, first, rs.Reader(ctx), "replicas down are skipped")
+	assert.Equal(t, first, rs.Reader(ctx))
+
+	first.Close()
+	rs.Check(ctx, logger.New())
+	assert.Equal(t, primary, rs.Reader(ctx), "the primary is read from when every replica is down")
+}
+
+func TestRepository_ReadFrom(t *testing.T) {
+	primary, replica := openSqlite(t), openSqlite(t)
+	_, err := replica.Exec("INSERT INTO notes (body) VALUES ('Replicated')")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rs := NewReplicas(primary, replica)
+	rs.Check(context.TODO(), logger.New())
+
+	r := New[note](primary, Table{Name: "notes", Alias: "n"})
+	r.ReadFrom(rs)
+
+	got, err := r.FindOne(context.TODO(), schema.QueryParams{})
+	assert.Nil(t, err)
+	assert.Equal(t, &note{Key: 1, Body: "Replicated"}, got, "rows are read from the replica")
+
+	many, err := r.FindMany(context.TODO(), schema.QueryParams{})
+	assert.Nil(t, err)
+	assert.Len(t, many, 1)
+
+	many, err = r.FindMany(Pin(context.TODO()), schema.QueryParams{})
+	assert.Nil(t, err)
+	assert.Empty(t, many, "pinned reads go to the primary")
+
+	exists, err := r.Exists(context.TODO(), schema.QueryParams{})
+	assert.Nil(t, err)
+	assert.False(t, exists, "everything else runs on the primary")
+}
@@ -0,0 +1,423 @@
+package persistence
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"reflect"
+	"slices"
+	"strings"
+
+	"github.com/henriqueassiss/advanced-golang-api/internal/utils/errorMsg"
+	"github.com/henriqueassiss/advanced-golang-api/internal/utils/schema"
+	"github.com/henriqueassiss/advanced-golang-api/internal/utils/tenant"
+	"github.com/henriqueassiss/advanced-golang-api/third_party/database"
+	"github.com/jmoiron/sqlx"
+)
+
+// Table describes the table a Repository reads and writes.
+type Table struct {
+	// Name and Alias name the table in queries. The params given to FindOne,
+	// FindMany, Count and Exists refer to its columns through Alias.
+	Name  string
+	Alias string
+	// Tenant is the column holding the workspace of a row. When set, every
+	// query is scoped to the workspace carried by ctx and fails with
+	// ErrTenantRequired when there is none.
+	Tenant string
+	// Touched is set to CURRENT_TIMESTAMP by every update.
+	Touched string
+	// Immutable columns are written on create and never updated.
+	Immutable []string
+}
+
+// Repository implements the queries every table needs from the db tags of T
+// and the description of its table, so domain repositories embed it and only
+// add their own queries. T is identified by its pk column, id by default.
+// Queries are written for the dialect of db.
+type Repository[T any] struct {
+	db         *sqlx.DB
+	dialect    database.Dialect
+	replicas   *Replicas
+	statements *Statements
+	table      Table
+	pk         string
+
+	sel    string
+	insert string
+	update string
+	touch  string
+	delete string
+	byPK   string
+}
+
+func New[T any](db *sqlx.DB, table Table) *Repository[T] {
+	pk := schema.PrimaryKey(reflect.TypeFor[T]())
+	if pk == "" {
+		pk = "id"
+	}
+
+	filter := pk + " = ?"
+	if table.Tenant != "" {
+		filter += " AND " + table.Tenant + " = ?"
+	}
+
+	touch := ""
+	if table.Touched != "" {
+		touch = table.Touched + " = CURRENT_TIMESTAMP"
+	}
+
+	dialect := database.DialectOf(db)
+
+	return &Repository[T]{
+		db:      db,
+		dialect: dialect,
+		table:   table,
+		pk:      pk,
+		sel:     fmt.Sprintf("SELECT ? FROM %s %s", table.Name, table.Alias),
+		insert:  fmt.Sprintf("INSERT INTO %s (%%s) VALUES (%%s)", table.Name),
+		update:  fmt.Sprintf("UPDATE %s SET %%s WHERE %s", table.Name, filter),
+		touch:   touch,
+		delete:  dialect.Rebind(fmt.Sprintf("DELETE FROM %s WHERE %s", table.Name, filter)),
+		byPK:    dialect.Rebind(fmt.Sprintf("SELECT * FROM %s WHERE %s = ?", table.Name, pk)),
+	}
+}
+
+// find scopes params to the workspace in ctx and builds the query selecting
+// sel, or every column when params selects none.
+func (r *Repository[T]) find(ctx context.Context, params schema.QueryParams, sel string) (string, []any, error) {
+	if params.Select == "" {
+		params.Select = sel
+	}
+
+	if r.table.Tenant != "" {
+		var err error
+		params.Where, params.Args, err = tenant.Where(ctx, r.table.Alias+"."+r.table.Tenant, params.Where, params.Args)
+		if err != nil {
+			return "", nil, err
+		}
+	}
+
+	query, args := schema.PrepareFindQuery(r.sel, params)
+	return r.dialect.Rebind(query), args, nil
+}
+
+// filter returns the arguments of the pk and tenant filter of updates and
+// deletes.
+func (r *Repository[T]) filter(ctx context.Context, id uint64) ([]any, error) {
+	if r.table.Tenant == "" {
+		return []any{id}, nil
+	}
+
+	workspaceID, ok := tenant.FromContext(ctx)
+	if !ok {
+		return nil, errorMsg.ErrTenantRequired
+	}
+
+	return []any{id, workspaceID}, nil
+}
+
+func (r *Repository[T]) FindOne(ctx context.Context, params schema.QueryParams) (*T, error) {
+	query, args, err := r.find(ctx, params, r.table.Alias+".*")
+	if err != nil {
+		return nil, err
+	}
+
+	var v T
+	err = r.get(ctx, r.reader(ctx), &v, query, args...)
+
+	return &v, err
+}
+
+func (r *Repository[T]) FindMany(ctx context.Context, params schema.QueryParams) ([]T, error) {
+	query, args, err := r.find(ctx, params, r.table.Alias+".*")
+	if err != nil {
+		return nil, err
+	}
+
+	var vs []T
+	err = r.selectInto(ctx, r.reader(ctx), &vs, query, args...)
+
+	return vs, err
+}
+
+// Select runs the find query of params into dest, a pointer to a slice of
+// any type, for queries that do not read rows of T, such as aggregates;
+// params.Select is required. Like FindMany, it reads from the replicas.
+func (r *Repository[T]) Select(ctx context.Context, dest any, params schema.QueryParams) error {
+	query, args, err := r.find(ctx, params, "")
+	if err != nil {
+		return err
+	}
+
+	return r.selectInto(ctx, r.reader(ctx), dest, query, args...)
+}
+
+// Count returns how many rows match params. Its Select, OrderBy, Offset and
+// Limit are ignored.
+func (r *Repository[T]) Count(ctx context.Context, params schema.QueryParams) (uint64, error) {
+	params.Select, params.OrderBy, params.SortOrder, params.Offset, params.Limit = "", "", "", 0, 0
+
+	query, args, err := r.find(ctx, params, "count(*)")
+	if err != nil {
+		return 0, err
+	}
+
+	var count uint64
+	err = r.get(ctx, r.conn(ctx), &count, query, args...)
+
+	return count, err
+}
+
+// Exists reports whether any row matches params.
+func (r *Repository[T]) Exists(ctx context.Context, params schema.QueryParams) (bool, error) {
+	params.Select, params.OrderBy, params.SortOrder, params.Offset, params.Limit = "", "", "", 0, 0
+
+	query, args, err := r.find(ctx, params, "1")
+	if err != nil {
+		return false, err
+	}
+
+	var exists bool
+	err = r.get(ctx, r.conn(ctx), &exists, "SELECT EXISTS ("+query+")", args...)
+
+	return exists, err
+}
+
+// Create inserts v in the workspace carried by ctx, over any v names, and
+// returns the row as stored, with its ID and the defaults the database
+// filled in. The ID is also set on v.
+func (r *Repository[T]) Create(ctx context.Context, v *T) (*T, error) {
+	err := r.stamp(ctx, v)
+	if err != nil {
+		return nil, err
+	}
+
+	fields, values, args := schema.ParseFieldsToInsertQuery(v)
+	args = r.dialect.Bind(args)
+	query := fmt.Sprintf(r.insert, fields, values)
+
+	var created T
+	if r.dialect.Returning() {
+		err = r.get(ctx, r.conn(ctx), &created, r.dialect.Rebind(query+" RETURNING *"), args...)
+	} else {
+		created, err = r.insertAndRead(ctx, query, args)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if id, ok := schema.Field(&created, r.pk); ok {
+		if f, ok := schema.Field(v, r.pk); ok {
+			f.SetUint(id.Uint())
+		}
+	}
+
+	return &created, nil
+}
+
+// insertAndRead runs query and reads the inserted row back by its ID.
+func (r *Repository[T]) insertAndRead(ctx context.Context, query string, args []any) (T, error) {
+	var created T
+
+	id, err := Insert(ctx, r.db, r.pk, query, args...)
+	if err != nil {
+		return created, err
+	}
+
+	err = r.get(ctx, r.conn(ctx), &created, r.byPK, id)
+
+	return created, err
+}
+
+// Upsert inserts v in the workspace carried by ctx or, when a row already
+// holds the values v has for the conflict columns, overwrites the other
+// columns v sets but the immutable ones, as an update would.
+func (r *Repository[T]) Upsert(ctx context.Context, v *T, conflict ...string) error {
+	err := r.stamp(ctx, v)
+	if err != nil {
+		return err
+	}
+
+	fields, values, args := schema.ParseFieldsToInsertQuery(v)
+	args = r.dialect.Bind(args)
+
+	ignore := append([]string{r.pk, r.table.Tenant}, r.table.Immutable...)
+	ignore = append(ignore, conflict...)
+
+	var update []string
+	for _, field := range strings.Split(fields, ", ") {
+		if !slices.Contains(ignore, field) {
+			update = append(update, field)
+		}
+	}
+
+	var touch []string
+	if r.touch != "" {
+		touch = append(touch, r.touch)
+	}
+
+	query := fmt.Sprintf(r.insert, fields, values) + " " + r.dialect.Upsert(conflict, update, touch...)
+	_, err = r.exec(ctx, r.dialect.Rebind(query), args...)
+
+	return err
+}
+
+// stamp sets the tenant column of v to the workspace carried by ctx.
+func (r *Repository[T]) stamp(ctx context.Context, v *T) error {
+	if r.table.Tenant == "" {
+		return nil
+	}
+
+	workspaceID, ok := tenant.FromContext(ctx)
+	if !ok {
+		return errorMsg.ErrTenantRequired
+	}
+
+	if f, ok := schema.Field(v, r.table.Tenant); ok {
+		f.SetUint(workspaceID)
+	}
+
+	return nil
+}
+
+// Update writes the columns v sets to the row it identifies, leaving its
+// tenant and immutable columns alone. It fails with sql.ErrNoRows when there
+// is no such row in the workspace carried by ctx.
+func (r *Repository[T]) Update(ctx context.Context, v *T) error {
+	f, ok := schema.Field(v, r.pk)
+	if !ok {
+		return errorMsg.ErrNothingToUpdate
+	}
+
+	filter, err := r.filter(ctx, f.Uint())
+	if err != nil {
+		return err
+	}
+
+	ignore := append([]string{r.table.Tenant}, r.table.Immutable...)
+	set, args := schema.ParseFieldsToUpdateQuery(v, ignore...)
+	args = r.dialect.Bind(args)
+
+	switch {
+	case set == "" && r.touch == "":
+		return errorMsg.ErrNothingToUpdate
+	case set == "":
+		set = r.touch
+	case r.touch != "":
+		set += ", " + r.touch
+	}
+
+	query := r.dialect.Rebind(fmt.Sprintf(r.update, set))
+
+	result, err := r.exec(ctx, query, append(args, filter...)...)
+	if err != nil {
+		return err
+	}
+
+	affected, err := result.RowsAffected()
+	if err == nil && affected == 0 {
+		err = sql.ErrNoRows
+	}
+
+	return err
+}
+
+func (r *Repository[T]) Delete(ctx context.Context, id uint64) error {
+	filter, err := r.filter(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	_, err = r.exec(ctx, r.delete, filter...)
+
+	return err
+}
+
+// Insert runs query, an INSERT with ? placeholders, and returns the pk of the
+// row it inserted: from RETURNING when the dialect of db takes it and from
+// LastInsertId otherwise.
+func Insert(ctx context.Context, db *sqlx.DB, pk, query string, args ...any) (uint64, error) {
+	dialect := database.DialectOf(db)
+
+	if dialect.Returning() {
+		var id uint64
+		err := Conn(ctx, db).QueryRowxContext(ctx, dialect.Rebind(query+" RETURNING "+pk), args...).Scan(&id)
+
+		return id, err
+	}
+
+	result, err := Conn(ctx, db).ExecContext(ctx, dialect.Rebind(query), args...)
+	if err != nil {
+		return 0, err
+	}
+
+	id, err := result.LastInsertId()
+
+	return uint64(id), err
+}
+
+// ReadFrom makes FindOne and FindMany read from replicas, which must be
+// replicas of the database of r.
+func (r *Repository[T]) ReadFrom(replicas *Replicas) {
+	r.replicas = replicas
+}
+
+// CacheStatements makes r run its queries as statements prepared once and
+// kept in statements.
+func (r *Repository[T]) CacheStatements(statements *Statements) {
+	r.statements = statements
+}
+
+func (r *Repository[T]) conn(ctx context.Context) Executor {
+	return Conn(ctx, r.db)
+}
+
+func (r *Repository[T]) reader(ctx context.Context) Executor {
+	if r.replicas == nil {
+		return r.conn(ctx)
+	}
+
+	return r.replicas.Reader(ctx)
+}
+
+// get runs query through exec into dest, a pointer to a single row, with a
+// cached statement when r caches them.
+func (r *Repository[T]) get(ctx context.Context, exec Executor, dest any, query string, args ...any) error {
+	if r.statements == nil {
+		return exec.GetContext(ctx, dest, query, args...)
+	}
+
+	return r.statements.run(ctx, exec, query, func(stmt *sqlx.Stmt) error {
+		return stmt.GetContext(ctx, dest, args...)
+	})
+}
+
+// selectInto runs query through exec into dest, a pointer to a slice, with a
+// cached statement when r caches them.
+func (r *Repository[T]) selectInto(ctx context.Context, exec Executor, dest any, query string, args ...any) error {
+	if r.statements == nil {
+		return exec.SelectContext(ctx, dest, query, args...)
+	}
+
+	return r.statements.run(ctx, exec, query, func(stmt *sqlx.Stmt) error {
+		return stmt.SelectContext(ctx, dest, args...)
+	})
+}
+
+// exec runs query on the primary, with a cached statement when r caches
+// them.
+func (r *Repository[T]) exec(ctx context.Context, query string, args ...any) (sql.Result, error) {
+	if r.statements == nil {
+		return r.conn(ctx).ExecContext(ctx, query, args...)
+	}
+
+	var result sql.Result
+	err := r.statements.run(ctx, r.conn(ctx), query, func(stmt *sqlx.Stmt) error {
+		var err error
+		result, err = stmt.ExecContext(ctx, args...)
+		return err
+	})
+
+	return result, err
+}
@@ -0,0 +1,203 @@
+package persistence
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/henriqueassiss/advanced-golang-api/internal/utils/errorMsg"
+	"github.com/henriqueassiss/advanced-golang-api/internal/utils/schema"
+	"github.com/henriqueassiss/advanced-golang-api/internal/utils/tenant"
+	"github.com/henriqueassiss/advanced-golang-api/third_party/database"
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/assert"
+
+	sqlxmock "github.com/zhashkevych/go-sqlxmock"
+)
+
+type note struct {
+	Key         uint64       `db:"key,pk,omitempty"`
+	Body        string       `db:"body,omitempty"`
+	AuthorID    uint64       `db:"author_id,omitempty"`
+	WorkspaceID uint64       `db:"workspace_id,omitempty"`
+	UpdatedAt   sql.NullTime `db:"updated_at,readonly"`
+}
+
+var notes = Table{
+	Name:      "notes",
+	Alias:     "n",
+	Tenant:    "workspace_id",
+	Touched:   "updated_at",
+	Immutable: []string{"author_id"},
+}
+
+func TestRepository_Find(t *testing.T) {
+	db, mock := database.NewSqlxMock(t)
+	r := New[note](db, notes)
+	defer db.Close()
+
+	ctx := tenant.NewContext(context.TODO(), 2)
+	params := schema.QueryParams{Where: "n.body = ?", Args: []any{"Test"}, OrderBy: "n.key", Limit: 10}
+
+	mock.ExpectQuery(`^SELECT n.\* FROM notes n WHERE \(n.body = \$1\) AND n.workspace_id = \$2 ORDER BY n.key LIMIT \$3$`).
+		WithArgs("Test", 2, 10).
+		WillReturnRows(mock.NewRows([]string{"key", "body"}).AddRow(1, "Test"))
+
+	got, err := r.FindOne(ctx, params)
+	assert.Nil(t, err)
+	assert.Equal(t, &note{Key: 1, Body: "Test"}, got)
+
+	mock.ExpectQuery(`^SELECT n.\* FROM notes n WHERE \(n.body = \$1\) AND n.workspace_id = \$2 ORDER BY n.key LIMIT \$3$`).
+		WithArgs("Test", 2, 10).
+		WillReturnRows(mock.NewRows([]string{"key", "body"}).AddRow(1, "Test").AddRow(2, "Test"))
+
+	many, err := r.FindMany(ctx, params)
+	assert.Nil(t, err)
+	assert.Equal(t, []note{{Key: 1, Body: "Test"}, {Key: 2, Body: "Test"}}, many)
+
+	mock.ExpectQuery(`^SELECT count\(\*\) FROM notes n WHERE \(n.body = \$1\) AND n.workspace_id = \$2$`).
+		WithArgs("Test", 2).
+		WillReturnRows(mock.NewRows([]string{"count"}).AddRow(2))
+
+	count, err := r.Count(ctx, params)
+	assert.Nil(t, err)
+	assert.Equal(t, uint64(2), count)
+
+	mock.ExpectQuery(`^SELECT EXISTS \(SELECT 1 FROM notes n WHERE \(n.body = \$1\) AND n.workspace_id = \$2\)$`).
+		WithArgs("Test", 2).
+		WillReturnRows(mock.NewRows([]string{"exists"}).AddRow(true))
+
+	exists, err := r.Exists(ctx, params)
+	assert.Nil(t, err)
+	assert.True(t, exists)
+
+	assert.Nil(t, mock.ExpectationsWereMet())
+}
+
+func TestRepository_Write(t *testing.T) {
+	db, mock := database.NewSqlxMock(t)
+	r := New[note](db, notes)
+	defer db.Close()
+
+	ctx := tenant.NewContext(context.TODO(), 2)
+
+	t.Run("Create - Stamps the workspace and returns the stored row", func(t *testing.T) {
+		updatedAt := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+		mock.ExpectQuery(`^INSERT INTO notes \(body, author_id, workspace_id\) VALUES \(\$1, \$2, \$3\) RETURNING \*$`).
+			WithArgs("Test", 3, 2).
+			WillReturnRows(mock.NewRows([]string{"key", "body", "author_id", "workspace_id", "updated_at"}).AddRow(7, "Test", 3, 2, updatedAt))
+
+		n := &note{Body: "Test", AuthorID: 3, WorkspaceID: 1}
+		created, err := r.Create(ctx, n)
+		assert.Nil(t, err)
+		assert.Equal(t, &note{Key: 7, Body: "Test", AuthorID: 3, WorkspaceID: 2, UpdatedAt: sql.NullTime{Time: updatedAt, Valid: true}}, created)
+		assert.Equal(t, &note{Key: 7, Body: "Test", AuthorID: 3, WorkspaceID: 2}, n)
+	})
+
+	t.Run("Update - Leaves the tenant and immutable columns alone", func(t *testing.T) {
+		mock.ExpectExec(`^UPDATE notes SET body = \$1, updated_at = CURRENT_TIMESTAMP WHERE key = \$2 AND workspace_id = \$3$`).
+			WithArgs("Test", 7, 2).
+			WillReturnResult(sqlxmock.NewResult(0, 1))
+
+		err := r.Update(ctx, &note{Key: 7, Body: "Test", AuthorID: 4, WorkspaceID: 1})
+		assert.Nil(t, err)
+	})
+
+	t.Run("Update - Missing rows are not found", func(t *testing.T) {
+		mock.ExpectExec(`^UPDATE notes SET updated_at = CURRENT_TIMESTAMP WHERE key = \$1 AND workspace_id = \$2$`).
+			WithArgs(8, 2).
+			WillReturnResult(sqlxmock.NewResult(0, 0))
+
+		err := r.Update(ctx, &note{Key: 8})
+		assert.Equal(t, sql.ErrNoRows, err)
+	})
+
+	t.Run("Delete", func(t *testing.T) {
+		mock.ExpectExec(`^DELETE FROM notes WHERE key = \$1 AND workspace_id = \$2$`).
+			WithArgs(7, 2).
+			WillReturnResult(sqlxmock.NewResult(0, 1))
+
+		err := r.Delete(ctx, 7)
+		assert.Nil(t, err)
+	})
+
+	t.Run("Missing workspace - Nothing is queried", func(t *testing.T) {
+		_, err := r.FindOne(context.TODO(), schema.QueryParams{})
+		assert.Equal(t, errorMsg.ErrTenantRequired, err)
+
+		_, err = r.Count(context.TODO(), schema.QueryParams{})
+		assert.Equal(t, errorMsg.ErrTenantRequired, err)
+
+		_, err = r.Create(context.TODO(), &note{Body: "Test"})
+		assert.Equal(t, errorMsg.ErrTenantRequired, err)
+
+		err = r.Update(context.TODO(), &note{Key: 7, Body: "Test"})
+		assert.Equal(t, errorMsg.ErrTenantRequired, err)
+
+		err = r.Delete(context.TODO(), 7)
+		assert.Equal(t, errorMsg.ErrTenantRequired, err)
+	})
+
+	assert.Nil(t, mock.ExpectationsWereMet())
+}
+
+func TestRepository_Unscoped(t *testing.T) {
+	db, mock := database.NewSqlxMock(t)
+	r := New[note](db, Table{Name: "notes", Alias: "n"})
+	defer db.Close()
+
+	mock.ExpectExec(`^DELETE FROM notes WHERE key = \$1$`).
+		WithArgs(7).
+		WillReturnResult(sqlxmock.NewResult(0, 1))
+
+	err := r.Delete(context.TODO(), 7)
+	assert.Nil(t, err)
+
+	err = r.Update(context.TODO(), &note{Key: 7})
+	assert.Equal(t, errorMsg.ErrNothingToUpdate, err)
+
+	assert.Nil(t, mock.ExpectationsWereMet())
+}
+
+func TestRepository_Upsert(t *testing.T) {
+	db, mock := database.NewSqlxMock(t)
+	r := New[note](db, notes)
+	defer db.Close()
+
+	mock.ExpectExec(`^INSERT INTO notes \(key, body, author_id, workspace_id\) VALUES \(\$1, \$2, \$3, \$4\) ON CONFLICT \("key"\) DO UPDATE SET "body" = EXCLUDED."body", updated_at = CURRENT_TIMESTAMP$`).
+		WithArgs(7, "Test", 3, 2).
+		WillReturnResult(sqlxmock.NewResult(0, 1))
+
+	err := r.Upsert(tenant.NewContext(context.TODO(), 2), &note{Key: 7, Body: "Test", AuthorID: 3}, "key")
+	assert.Nil(t, err)
+
+	assert.Nil(t, mock.ExpectationsWereMet())
+}
+
+// MySQL inserts without RETURNING, so the row is read back by its ID.
+func TestRepository_CreateWithoutReturning(t *testing.T) {
+	mockDB, mock, err := sqlxmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	db := sqlx.NewDb(mockDB, "mysql")
+	r := New[note](db, notes)
+	defer db.Close()
+
+	mock.ExpectExec("^INSERT INTO notes \\(body, workspace_id\\) VALUES \\(\\?, \\?\\)$").
+		WithArgs("Test", 2).
+		WillReturnResult(sqlxmock.NewResult(7, 1))
+	mock.ExpectQuery("^SELECT \\* FROM notes WHERE key = \\?$").
+		WithArgs(7).
+		WillReturnRows(mock.NewRows([]string{"key", "body", "workspace_id"}).AddRow(7, "Test", 2))
+
+	n := &note{Body: "Test"}
+	created, err := r.Create(tenant.NewContext(context.TODO(), 2), n)
+	assert.Nil(t, err)
+	assert.Equal(t, &note{Key: 7, Body: "Test", WorkspaceID: 2}, created)
+	assert.Equal(t, uint64(7), n.Key)
+
+	assert.Nil(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,289 @@
+package persistence
+
+import (
+	"container/list"
+	"context"
+	"errors"
+	"log/slog"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+)
+
+type statementKey struct {
+	db    *sqlx.DB
+	query string
+}
+
+// statement is a cached statement and how many queries are running it, so
+// the one evicted is only closed once they are done.
+type statement struct {
+	key     statementKey
+	stmt    *sqlx.Stmt
+	users   int
+	evicted bool
+}
+
+// StatementStats are the counters of a Statements cache.
+type StatementStats struct {
+	Hits       uint64
+	Misses     uint64
+	Evictions  uint64
+	Reprepares uint64
+	Size       int
+}
+
+// HitRate is the share of queries that found their statement prepared.
+func (s StatementStats) HitRate() float64 {
+	if s.Hits+s.Misses == 0 {
+		return 0
+	}
+
+	return float64(s.Hits) / float64(s.Hits+s.Misses)
+}
+
+// Statements caches prepared statements by database and query text, keeping
+// the size most recently used. It can be shared by repositories over the
+// primary and its replicas.
+//
+// database/sql prepares a statement again on every connection it runs on,
+// so connections opened after a reset get it too. Statements the server
+// dropped on a connection still open, or planned against a schema that has
+// since changed, fail instead: these are evicted and prepared again.
+type Statements struct {
+	size int
+
+	mu      sync.Mutex
+	order   *list.List
+	entries map[statementKey]*list.Element
+
+	hits       atomic.Uint64
+	misses     atomic.Uint64
+	evictions  atomic.Uint64
+	reprepares atomic.Uint64
+}
+
+func NewStatements(size int) *Statements {
+	return &Statements{
+		size:    size,
+		order:   list.New(),
+		entries: map[statementKey]*list.Element{},
+	}
+}
+
+// Stats returns the counters of the cache.
+func (s *Statements) Stats() StatementStats {
+	s.mu.Lock()
+	size := s.order.Len()
+	s.mu.Unlock()
+
+	return StatementStats{
+		Hits:       s.hits.Load(),
+		Misses:     s.misses.Load(),
+		Evictions:  s.evictions.Load(),
+		Reprepares: s.reprepares.Load(),
+		Size:       size,
+	}
+}
+
+// Report logs the counters of the cache every interval until ctx is done.
+func (s *Statements) Report(ctx context.Context, interval time.Duration, logger *slog.Logger) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			stats := s.Stats()
+			logger.Info("Statement cache", "hits", stats.Hits, "misses", stats.Misses, "hitRate", stats.HitRate(), "evictions", stats.Evictions, "reprepares", stats.Reprepares, "size", stats.Size)
+		}
+	}
+}
+
+// Close closes every cached statement.
+func (s *Statements) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var errs []error
+	for s.order.Len() > 0 {
+		errs = append(errs, s.evict(s.order.Back()))
+	}
+
+	return errors.Join(errs...)
+}
+
+// acquire returns the statement of query on db, preparing it on a miss.
+// Callers release it once done.
+func (s *Statements) acquire(ctx context.Context, db *sqlx.DB, query string) (*statement, error) {
+	key := statementKey{db, query}
+	if st := s.cached(key); st != nil {
+		s.hits.Add(1)
+		return st, nil
+	}
+
+	s.misses.Add(1)
+	stmt, err := db.PreparexContext(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	// Another query may have prepared it meanwhile.
+	if e, ok := s.entries[key]; ok {
+		_ = stmt.Close()
+		s.order.MoveToFront(e)
+		st := e.Value.(*statement)
+		st.users++
+
+		return st, nil
+	}
+
+	st := &statement{key: key, stmt: stmt, users: 1}
+	s.entries[key] = s.order.PushFront(st)
+	for s.order.Len() > s.size {
+		_ = s.evict(s.order.Back())
+		s.evictions.Add(1)
+	}
+
+	return st, nil
+}
+
+// cached returns the statement of key, if there is one, for the caller to
+// release once done.
+func (s *Statements) cached(key statementKey) *statement {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	e, ok := s.entries[key]
+	if !ok {
+		return nil
+	}
+
+	s.order.MoveToFront(e)
+	st := e.Value.(*statement)
+	st.users++
+
+	return st
+}
+
+func (s *Statements) release(st *statement) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	st.users--
+	if st.evicted && st.users == 0 {
+		_ = st.stmt.Close()
+	}
+}
+
+// forget evicts st, unless it was already replaced, so the next query
+// prepares its statement again.
+func (s *Statements) forget(st *statement) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if e, ok := s.entries[st.key]; ok && e.Value == st {
+		_ = s.evict(e)
+	}
+}
+
+// evict removes e from the cache and closes its statement, or leaves that to
+// the last query running it. s.mu must be held.
+func (s *Statements) evict(e *list.Element) error {
+	st := s.order.Remove(e).(*statement)
+	delete(s.entries, st.key)
+	st.evicted = true
+
+	if st.users == 0 {
+		return st.stmt.Close()
+	}
+
+	return nil
+}
+
+// stale reports whether err tells a prepared statement is gone from its
+// connection or was planned against a schema that has since changed.
+func stale(err error) bool {
+	var state interface{ SQLState() string }
+	if !errors.As(err, &state) {
+		return false
+	}
+
+	return state.SQLState() == "26000" || state.SQLState() == "0A000" && strings.Contains(err.Error(), "cached plan")
+}
+
+// run calls fn with the statement of query, prepared on the database exec
+// reads from. In a transaction, fn gets the cached statement bound to it,
+// or one prepared on the transaction alone on a miss: preparing on the
+// database takes another connection, which a full pool may never give. A
+// stale statement is prepared again and, out of a transaction, fn retried.
+func (s *Statements) run(ctx context.Context, exec Executor, query string, fn func(stmt *sqlx.Stmt) error) error {
+	if db, ok := exec.(*sqlx.DB); ok {
+		return s.runOn(ctx, db, query, fn)
+	}
+
+	t, ok := ctx.Value(contextKey{}).(*transaction)
+	if !ok || exec != Executor(t.tx) {
+		return errors.New("persistence: statements only run on a database or the transaction in ctx")
+	}
+
+	var stmt *sqlx.Stmt
+	st := s.cached(statementKey{t.db, query})
+	if st != nil {
+		s.hits.Add(1)
+		stmt = t.tx.StmtxContext(ctx, st.stmt)
+	} else {
+		s.misses.Add(1)
+
+		var err error
+		stmt, err = t.tx.PreparexContext(ctx, query)
+		if err != nil {
+			return err
+		}
+	}
+
+	err := fn(stmt)
+	_ = stmt.Close()
+	if st == nil {
+		return err
+	}
+
+	s.release(st)
+
+	// A failed statement aborts the transaction it ran in, so it is not
+	// retried.
+	if stale(err) {
+		s.forget(st)
+		s.reprepares.Add(1)
+	}
+
+	return err
+}
+
+// runOn calls fn with the statement of query prepared on db.
+func (s *Statements) runOn(ctx context.Context, db *sqlx.DB, query string, fn func(stmt *sqlx.Stmt) error) error {
+	for attempt := 0; ; attempt++ {
+		st, err := s.acquire(ctx, db, query)
+		if err != nil {
+			return err
+		}
+
+		err = fn(st.stmt)
+		s.release(st)
+
+		if err == nil || !stale(err) || attempt > 0 {
+			return err
+		}
+
+		s.forget(st)
+		s.reprepares.Add(1)
+	}
+}
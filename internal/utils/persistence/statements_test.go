@@ -0,0 +1,163 @@
+package persistence
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"testing"
+
+	"github.com/henriqueassiss/advanced-golang-api/internal/utils/schema"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRepository_CacheStatements(t *testing.T) {
+	db := openSqlite(t)
+	statements := NewStatements(8)
+	t.Cleanup(func() { statements.Close() })
+
+	r := New[note](db, Table{Name: "notes", Alias: "n"})
+	r.CacheStatements(statements)
+	ctx := context.TODO()
+
+	_, err := r.Create(ctx, &note{Body: "First"})
+	assert.Nil(t, err)
+
+	for i := 0; i < 3; i++ {
+		got, err := r.FindOne(ctx, schema.QueryParams{Where: "n.key = ?", Args: []any{1}})
+		assert.Nil(t, err)
+		assert.Equal(t, "First", got.Body)
+	}
+
+	stats := statements.Stats()
+	assert.Equal(t, uint64(2), stats.Hits, "statements are prepared once per query")
+	assert.Equal(t, uint64(2), stats.Misses)
+	assert.Equal(t, 2, stats.Size)
+	assert.Equal(t, 0.5, stats.HitRate())
+
+	err = NewTxManager(db, sql.LevelDefault, 0).Run(ctx, func(ctx context.Context) error {
+		err := r.Update(ctx, &note{Key: 1, Body: "Updated"})
+		if err != nil {
+			return err
+		}
+
+		got, err := r.FindOne(ctx, schema.QueryParams{Where: "n.key = ?", Args: []any{1}})
+		assert.Equal(t, "Updated", got.Body, "statements run in the transaction of ctx")
+
+		return err
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, uint64(3), statements.Stats().Hits)
+}
+
+func TestStatements_Bounded(t *testing.T) {
+	db := openSqlite(t)
+	statements := NewStatements(2)
+	ctx := context.TODO()
+
+	held, err := statements.acquire(ctx, db, "SELECT count(*) FROM notes")
+	assert.Nil(t, err)
+
+	for i := 0; i < 3; i++ {
+		var n int
+		err := statements.run(ctx, db, fmt.Sprintf("SELECT %d FROM notes", i), func(stmt *sqlx.Stmt) error {
+			return stmt.GetContext(ctx, &n)
+		})
+		assert.ErrorIs(t, err, sql.ErrNoRows)
+	}
+
+	stats := statements.Stats()
+	assert.Equal(t, 2, stats.Size)
+	assert.Equal(t, uint64(2), stats.Evictions)
+
+	var count int
+	assert.Nil(t, held.stmt.GetContext(ctx, &count), "statements evicted in use are closed once released")
+	statements.release(held)
+	assert.NotNil(t, held.stmt.GetContext(ctx, &count))
+}
+
+func TestStatements_Reprepare(t *testing.T) {
+	db := openSqlite(t)
+	statements := NewStatements(2)
+	ctx := context.TODO()
+
+	var prepared []*sqlx.Stmt
+	err := statements.run(ctx, db, "SELECT 1", func(stmt *sqlx.Stmt) error {
+		prepared = append(prepared, stmt)
+		if len(prepared) == 1 {
+			return &pgconn.PgError{Code: "26000", Message: `prepared statement "stmtcache_1" does not exist`}
+		}
+
+		return nil
+	})
+	assert.Nil(t, err)
+	assert.Len(t, prepared, 2, "stale statements are retried")
+	assert.NotEqual(t, prepared[0], prepared[1], "stale statements are prepared again")
+	assert.Equal(t, uint64(1), statements.Stats().Reprepares)
+
+	err = NewTxManager(db, sql.LevelDefault, 0).Run(ctx, func(ctx context.Context) error {
+		runs := 0
+		err := statements.run(ctx, Conn(ctx, db), "SELECT 1", func(stmt *sqlx.Stmt) error {
+			runs++
+			return &pgconn.PgError{Code: "0A000", Message: "cached plan must not change result type"}
+		})
+		assert.Equal(t, 1, runs, "statements are not retried in a transaction")
+
+		return err
+	})
+	assert.NotNil(t, err)
+}
+
+// The unprepared runs send FindOne as the repository did before it cached
+// statements.
+func BenchmarkRepository_FindOne(b *testing.B) {
+	db, err := sqlx.Open("sqlite3", "file:bench?mode=memory&cache=shared")
+	if err != nil {
+		b.Fatal(err)
+	}
+	defer db.Close()
+
+	_, err = db.Exec("CREATE TABLE notes (key INTEGER PRIMARY KEY, body TEXT, author_id INTEGER, workspace_id INTEGER, updated_at DATETIME)")
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	for i := 0; i < 1000; i++ {
+		_, err = db.Exec("INSERT INTO notes (body, author_id, workspace_id) VALUES (?, 1, 1)", fmt.Sprint("Note ", i))
+		if err != nil {
+			b.Fatal(err)
+		}
+	}
+
+	ctx := context.TODO()
+	params := schema.QueryParams{Where: "n.key = ?", Args: []any{500}}
+
+	b.Run("Unprepared", func(b *testing.B) {
+		r := New[note](db, Table{Name: "notes", Alias: "n"})
+
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			_, err := r.FindOne(ctx, params)
+			if err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+
+	b.Run("Prepared", func(b *testing.B) {
+		statements := NewStatements(8)
+		defer statements.Close()
+
+		r := New[note](db, Table{Name: "notes", Alias: "n"})
+		r.CacheStatements(statements)
+
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			_, err := r.FindOne(ctx, params)
+			if err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
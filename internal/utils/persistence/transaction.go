@@ -0,0 +1,151 @@
+package persistence
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"math/rand"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// Executor runs queries, on the database or on a transaction.
+type Executor interface {
+	sqlx.ExtContext
+	GetContext(ctx context.Context, dest any, query string, args ...any) error
+	SelectContext(ctx context.Context, dest any, query string, args ...any) error
+	QueryRowxContext(ctx context.Context, query string, args ...any) *sqlx.Row
+}
+
+// Transactor runs fn atomically.
+type Transactor interface {
+	Run(ctx context.Context, fn func(ctx context.Context) error) error
+}
+
+type contextKey struct{}
+
+// transaction is what Run stores in the context: the transaction, the
+// database it runs on and how many Runs deep fn is.
+type transaction struct {
+	tx    *sqlx.Tx
+	db    *sqlx.DB
+	depth int
+}
+
+// Conn returns the transaction carried by ctx, or db when there is none.
+// Repositories query through it so they join the transaction of the use case
+// calling them.
+func Conn(ctx context.Context, db *sqlx.DB) Executor {
+	if t, ok := ctx.Value(contextKey{}).(*transaction); ok {
+		return t.tx
+	}
+
+	return db
+}
+
+// retryable reports whether err aborted a transaction that may succeed when
+// run again: a serialization failure or a deadlock.
+func retryable(err error) bool {
+	var state interface{ SQLState() string }
+	if !errors.As(err, &state) {
+		return false
+	}
+
+	return state.SQLState() == "40001" || state.SQLState() == "40P01"
+}
+
+type TxManager struct {
+	db        *sqlx.DB
+	isolation sql.IsolationLevel
+	retries   int
+	sleep     func(time.Duration)
+}
+
+// NewTxManager starts transactions at isolation and runs them up to retries
+// more times when they fail with a serialization failure or a deadlock.
+func NewTxManager(db *sqlx.DB, isolation sql.IsolationLevel, retries int) *TxManager {
+	return &TxManager{
+		db:        db,
+		isolation: isolation,
+		retries:   retries,
+		sleep:     time.Sleep,
+	}
+}
+
+// Run calls fn with a context carrying a transaction, which is committed when
+// fn succeeds and rolled back otherwise. Called again inside fn, it runs in a
+// savepoint of the same transaction instead, so a failure rolls back that
+// part alone and leaves the caller to decide. Only the outermost Run is
+// retried, as a failed statement aborts the whole transaction; fn must be
+// safe to run again.
+func (m *TxManager) Run(ctx context.Context, fn func(ctx context.Context) error) error {
+	if t, ok := ctx.Value(contextKey{}).(*transaction); ok {
+		return m.savepoint(ctx, t, fn)
+	}
+
+	var err error
+	for attempt := 0; ; attempt++ {
+		err = m.run(ctx, fn)
+		if err == nil || !retryable(err) || attempt >= m.retries || ctx.Err() != nil {
+			return err
+		}
+
+		// Jittered, so the transactions that conflicted do not meet again.
+		m.sleep(time.Duration(attempt+1) * time.Duration(10+rand.Intn(10)) * time.Millisecond)
+	}
+}
+
+func (m *TxManager) run(ctx context.Context, fn func(ctx context.Context) error) (err error) {
+	tx, err := m.db.BeginTxx(ctx, &sql.TxOptions{Isolation: m.isolation})
+	if err != nil {
+		return err
+	}
+
+	defer func() {
+		if p := recover(); p != nil {
+			_ = tx.Rollback()
+			panic(p)
+		}
+	}()
+
+	err = fn(context.WithValue(ctx, contextKey{}, &transaction{tx: tx, db: m.db}))
+	if err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func (m *TxManager) savepoint(ctx context.Context, t *transaction, fn func(ctx context.Context) error) (err error) {
+	nested := &transaction{tx: t.tx, db: t.db, depth: t.depth + 1}
+	name := fmt.Sprintf("sp_%d", nested.depth)
+
+	_, err = t.tx.ExecContext(ctx, "SAVEPOINT "+name)
+	if err != nil {
+		return err
+	}
+
+	defer func() {
+		if p := recover(); p != nil {
+			_, _ = t.tx.ExecContext(ctx, "ROLLBACK TO SAVEPOINT "+name)
+			panic(p)
+		}
+	}()
+
+	err = fn(context.WithValue(ctx, contextKey{}, nested))
+	if err != nil {
+		_, rollbackErr := t.tx.ExecContext(ctx, "ROLLBACK TO SAVEPOINT "+name)
+		if rollbackErr != nil {
+			return errors.Join(err, rollbackErr)
+		}
+
+		return err
+	}
+
+	_, err = t.tx.ExecContext(ctx, "RELEASE SAVEPOINT "+name)
+
+	return err
+}
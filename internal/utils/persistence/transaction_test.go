@@ -0,0 +1,149 @@
+package persistence
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/henriqueassiss/advanced-golang-api/third_party/database"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/assert"
+
+	sqlxmock "github.com/zhashkevych/go-sqlxmock"
+)
+
+func newTxManager(db *sqlx.DB, retries int) *TxManager {
+	m := NewTxManager(db, sql.LevelDefault, retries)
+	m.sleep = func(time.Duration) {}
+
+	return m
+}
+
+func TestTxManager_Run(t *testing.T) {
+	db, mock := database.NewSqlxMock(t)
+	m := newTxManager(db, 0)
+	defer db.Close()
+
+	t.Run("Commits when fn succeeds", func(t *testing.T) {
+		mock.ExpectBegin()
+		mock.ExpectExec("DELETE FROM notes").WillReturnResult(sqlxmock.NewResult(0, 1))
+		mock.ExpectCommit()
+
+		err := m.Run(context.TODO(), func(ctx context.Context) error {
+			assert.NotEqual(t, db, Conn(ctx, db), "queries run on the transaction")
+
+			_, err := Conn(ctx, db).ExecContext(ctx, "DELETE FROM notes")
+			return err
+		})
+		assert.Nil(t, err)
+		assert.Nil(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Rolls back when fn fails", func(t *testing.T) {
+		mock.ExpectBegin()
+		mock.ExpectRollback()
+
+		err := m.Run(context.TODO(), func(ctx context.Context) error {
+			return sql.ErrNoRows
+		})
+		assert.Equal(t, sql.ErrNoRows, err)
+		assert.Nil(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Nested runs use savepoints", func(t *testing.T) {
+		mock.ExpectBegin()
+		mock.ExpectExec("SAVEPOINT sp_1").WillReturnResult(sqlxmock.NewResult(0, 0))
+		mock.ExpectExec("SAVEPOINT sp_2").WillReturnResult(sqlxmock.NewResult(0, 0))
+		mock.ExpectExec("RELEASE SAVEPOINT sp_2").WillReturnResult(sqlxmock.NewResult(0, 0))
+		mock.ExpectExec("ROLLBACK TO SAVEPOINT sp_1").WillReturnResult(sqlxmock.NewResult(0, 0))
+		mock.ExpectExec("SAVEPOINT sp_1").WillReturnResult(sqlxmock.NewResult(0, 0))
+		mock.ExpectExec("RELEASE SAVEPOINT sp_1").WillReturnResult(sqlxmock.NewResult(0, 0))
+		mock.ExpectCommit()
+
+		err := m.Run(context.TODO(), func(ctx context.Context) error {
+			err := m.Run(ctx, func(ctx context.Context) error {
+				err := m.Run(ctx, func(ctx context.Context) error { return nil })
+				assert.Nil(t, err)
+
+				return sql.ErrNoRows
+			})
+			assert.Equal(t, sql.ErrNoRows, err, "the caller decides what a failed savepoint means")
+
+			return m.Run(ctx, func(ctx context.Context) error { return nil })
+		})
+		assert.Nil(t, err)
+		assert.Nil(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Rolls back when fn panics", func(t *testing.T) {
+		mock.ExpectBegin()
+		mock.ExpectRollback()
+
+		assert.Panics(t, func() {
+			_ = m.Run(context.TODO(), func(ctx context.Context) error {
+				panic("boom")
+			})
+		})
+		assert.Nil(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestTxManager_Retry(t *testing.T) {
+	db, mock := database.NewSqlxMock(t)
+	m := newTxManager(db, 2)
+	defer db.Close()
+
+	serialization := &pgconn.PgError{Code: "40001"}
+
+	t.Run("Serialization failures are retried", func(t *testing.T) {
+		mock.ExpectBegin()
+		mock.ExpectExec("UPDATE notes").WillReturnError(serialization)
+		mock.ExpectRollback()
+		mock.ExpectBegin()
+		mock.ExpectExec("UPDATE notes").WillReturnResult(sqlxmock.NewResult(0, 1))
+		mock.ExpectCommit()
+
+		runs := 0
+		err := m.Run(context.TODO(), func(ctx context.Context) error {
+			runs++
+			_, err := Conn(ctx, db).ExecContext(ctx, "UPDATE notes")
+			return err
+		})
+		assert.Nil(t, err)
+		assert.Equal(t, 2, runs)
+		assert.Nil(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Retries are bounded", func(t *testing.T) {
+		runs := 0
+		for i := 0; i < 3; i++ {
+			mock.ExpectBegin()
+			mock.ExpectRollback()
+		}
+
+		err := m.Run(context.TODO(), func(ctx context.Context) error {
+			runs++
+			return serialization
+		})
+		assert.Equal(t, serialization, err)
+		assert.Equal(t, 3, runs)
+		assert.Nil(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Other errors are not retried", func(t *testing.T) {
+		mock.ExpectBegin()
+		mock.ExpectRollback()
+
+		runs := 0
+		err := m.Run(context.TODO(), func(ctx context.Context) error {
+			runs++
+			return errors.New("constraint violated")
+		})
+		assert.NotNil(t, err)
+		assert.Equal(t, 1, runs)
+		assert.Nil(t, mock.ExpectationsWereMet())
+	})
+}
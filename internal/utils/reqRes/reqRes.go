@@ -5,8 +5,10 @@ import (
 	"errors"
 	"fmt"
 	"log/slog"
+	"net"
 	"net/http"
 	"strconv"
+	"time"
 
 	"github.com/go-chi/chi/v5"
 	"github.com/mssola/useragent"
@@ -18,6 +20,12 @@ type GenericResponse[T any] struct {
 	Data    T    `json:"data"`
 }
 
+// Modified is implemented by payloads that know when they last changed, so
+// Json can send Last-Modified for conditional requests.
+type Modified interface {
+	LastModified() time.Time
+}
+
 func respond(w http.ResponseWriter, statusCode int, isSuccess bool, payload any) {
 	res := GenericResponse[any]{
 		Success: isSuccess,
@@ -31,12 +39,33 @@ func respond(w http.ResponseWriter, statusCode int, isSuccess bool, payload any)
 }
 
 func Json(w http.ResponseWriter, statusCode int, payload any) {
+	if m, ok := payload.(Modified); ok && !m.LastModified().IsZero() {
+		w.Header().Set("Last-Modified", m.LastModified().UTC().Format(http.TimeFormat))
+	}
+
 	respond(w, statusCode, true, payload)
 }
 
+// Created answers 201 with payload, pointing Location at where it can be
+// read from.
+func Created(w http.ResponseWriter, location string, payload any) {
+	w.Header().Set("Location", location)
+	Json(w, http.StatusCreated, payload)
+}
+
+// NoContent answers 204, which carries no body.
+func NoContent(w http.ResponseWriter) {
+	w.WriteHeader(http.StatusNoContent)
+}
+
 func Error(logger *slog.Logger, w http.ResponseWriter, statusCode int, err error, errData any) {
 	respond(w, statusCode, false, nil)
-	logger.Error(err.Error(), errData)
+
+	if err == nil {
+		err = errors.New(http.StatusText(statusCode))
+	}
+
+	logger.Error(err.Error(), "data", errData)
 }
 
 func GetRequestDevice(reqUserAgent string) (device string) {
@@ -68,6 +97,15 @@ func GetRequestDevice(reqUserAgent string) (device string) {
 	return device
 }
 
+func GetRequestIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+
+	return host
+}
+
 func UInt64Param(r *http.Request, param string, acceptZero bool) (uint64, error) {
 	val, err := strconv.ParseInt(chi.URLParam(r, param), 10, 64)
 	if err != nil {
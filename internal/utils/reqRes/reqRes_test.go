@@ -6,6 +6,7 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
 	"github.com/go-chi/chi/v5"
 )
@@ -49,3 +50,27 @@ func TestUInt64Query(t *testing.T) {
 		t.Errorf("got: value = %d and err = %v | expected: value = %d and err = %v", value, err, expectedValue, expectedErr)
 	}
 }
+
+type modified struct {
+	At time.Time `json:"at"`
+}
+
+func (m modified) LastModified() time.Time {
+	return m.At
+}
+
+func TestJson_LastModified(t *testing.T) {
+	w := httptest.NewRecorder()
+	Json(w, http.StatusOK, modified{At: time.Date(2024, 1, 2, 3, 4, 5, 0, time.FixedZone("", -3*60*60))})
+
+	if got := w.Header().Get("Last-Modified"); got != "Tue, 02 Jan 2024 06:04:05 GMT" {
+		t.Errorf("got: %q | expected: %q", got, "Tue, 02 Jan 2024 06:04:05 GMT")
+	}
+
+	w = httptest.NewRecorder()
+	Json(w, http.StatusOK, modified{})
+
+	if got := w.Header().Get("Last-Modified"); got != "" {
+		t.Errorf("got: %q | expected no Last-Modified for a zero time", got)
+	}
+}
@@ -0,0 +1,111 @@
+package schema
+
+import (
+	"bytes"
+	"database/sql"
+	"database/sql/driver"
+	"encoding/json"
+)
+
+// Optional is a value that may be unset, null or hold a value, so a write
+// can tell a column it must leave alone from one it must clear. The insert
+// and update builders skip unset columns and write null ones as NULL.
+// Decoding JSON sets it only when its key is present, and scanning a row
+// always sets it.
+type Optional[T any] struct {
+	value T
+	set   bool
+	valid bool
+}
+
+// Some returns an Optional holding v.
+func Some[T any](v T) Optional[T] {
+	return Optional[T]{value: v, set: true, valid: true}
+}
+
+// Null returns an Optional set to null.
+func Null[T any]() Optional[T] {
+	return Optional[T]{set: true}
+}
+
+// IsSet reports whether o was given a value or null.
+func (o Optional[T]) IsSet() bool {
+	return o.set
+}
+
+// IsNull reports whether o was set to null.
+func (o Optional[T]) IsNull() bool {
+	return o.set && !o.valid
+}
+
+// Get returns the value of o and whether it holds one.
+func (o Optional[T]) Get() (T, bool) {
+	return o.value, o.valid
+}
+
+// Or returns the value of o, or fallback when it holds none.
+func (o Optional[T]) Or(fallback T) T {
+	if !o.valid {
+		return fallback
+	}
+
+	return o.value
+}
+
+// MarshalJSON writes the value of o, or null when it holds none.
+func (o Optional[T]) MarshalJSON() ([]byte, error) {
+	if !o.valid {
+		return []byte("null"), nil
+	}
+
+	return json.Marshal(o.value)
+}
+
+func (o *Optional[T]) UnmarshalJSON(data []byte) error {
+	if bytes.Equal(bytes.TrimSpace(data), []byte("null")) {
+		*o = Null[T]()
+		return nil
+	}
+
+	var v T
+	err := json.Unmarshal(data, &v)
+	if err != nil {
+		return err
+	}
+
+	*o = Some(v)
+	return nil
+}
+
+func (o *Optional[T]) Scan(src any) error {
+	var n sql.Null[T]
+	err := n.Scan(src)
+	if err != nil {
+		return err
+	}
+
+	*o = Optional[T]{value: n.V, set: true, valid: n.Valid}
+	return nil
+}
+
+func (o Optional[T]) Value() (driver.Value, error) {
+	if !o.valid {
+		return nil, nil
+	}
+
+	return sql.Null[T]{V: o.value, Valid: true}.Value()
+}
+
+// optional lets the builders read an Optional whatever its type argument.
+type optional interface {
+	IsSet() bool
+	bound() any
+}
+
+func (o Optional[T]) bound() any {
+	if !o.valid {
+		return nil
+	}
+
+	return o.value
+}
@@ -0,0 +1,90 @@
+package schema
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+type optionalTest struct {
+	ID     uint64           `db:"id,pk,omitempty"`
+	Name   Optional[string] `db:"name"`
+	Active Optional[bool]   `db:"active"`
+	Score  Optional[int64]  `db:"score"`
+}
+
+func TestOptional_JSON(t *testing.T) {
+	var o optionalTest
+	err := json.Unmarshal([]byte(`{"name": null, "active": false}`), &o)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !o.Name.IsNull() {
+		t.Errorf("got: name = %v | expected: name = null", o.Name)
+	}
+
+	if v, ok := o.Active.Get(); !ok || v {
+		t.Errorf("got: active = %v | expected: active = false", o.Active)
+	}
+
+	if o.Score.IsSet() {
+		t.Errorf("got: score = %v | expected: score unset", o.Score)
+	}
+
+	expectedValue := `{"ID":0,"Name":null,"Active":false,"Score":null}`
+	value, err := json.Marshal(o)
+	if err != nil || string(value) != expectedValue {
+		t.Errorf("got: value = %s and err = %v | expected: value = %s", value, err, expectedValue)
+	}
+
+	err = json.Unmarshal([]byte(`{"score": "high"}`), &o)
+	if err == nil {
+		t.Errorf("got: err = nil | expected an error for a value of the wrong type")
+	}
+}
+
+func TestOptional_Scan(t *testing.T) {
+	var name Optional[string]
+	err := name.Scan(nil)
+	if err != nil || !name.IsNull() {
+		t.Errorf("got: name = %v and err = %v | expected: name = null", name, err)
+	}
+
+	err = name.Scan([]byte("John"))
+	if err != nil || name != Some("John") {
+		t.Errorf("got: name = %v and err = %v | expected: name = John", name, err)
+	}
+
+	var score Optional[int64]
+	err = score.Scan(int64(0))
+	if err != nil || score != Some(int64(0)) {
+		t.Errorf("got: score = %v and err = %v | expected: score = 0", score, err)
+	}
+
+	value, err := Null[string]().Value()
+	if err != nil || value != nil {
+		t.Errorf("got: value = %v and err = %v | expected: value = nil", value, err)
+	}
+
+	value, err = Some("John").Value()
+	if err != nil || value != "John" {
+		t.Errorf("got: value = %v and err = %v | expected: value = John", value, err)
+	}
+}
+
+func TestOptional_Builders(t *testing.T) {
+	o := optionalTest{ID: 1, Name: Null[string](), Active: Some(false)}
+
+	expectedFields, expectedValues, expectedArgs := "id, name, active", "?, ?, ?", []any{uint64(1), nil, false}
+	fields, values, args := ParseFieldsToInsertQuery(o)
+	if fields != expectedFields || values != expectedValues || !reflect.DeepEqual(args, expectedArgs) {
+		t.Errorf("got: fields = %v, values = %v and args = %v | expected: fields = %v, values = %v and args = %v", fields, values, args, expectedFields, expectedValues, expectedArgs)
+	}
+
+	expectedValue, expectedArgs := "name = ?, active = ?", []any{nil, false}
+	value, args := ParseFieldsToUpdateQuery(o)
+	if value != expectedValue || !reflect.DeepEqual(args, expectedArgs) {
+		t.Errorf("got: value = %v and args = %v | expected: value = %v and args = %v", value, args, expectedValue, expectedArgs)
+	}
+}
@@ -1,112 +1,195 @@
 package schema
 
 import (
-	"database/sql"
 	"fmt"
 	"reflect"
 	"regexp"
+	"slices"
 	"strings"
-	"time"
+	"sync"
+)
 
-	"github.com/lib/pq"
+var (
+	matchFirstCap = regexp.MustCompile("(.)([A-Z][a-z]+)")
+	matchAllCap   = regexp.MustCompile("([a-z0-9])([A-Z])")
 )
 
 func toSnakeCase(str string) string {
-	matchFirstCap := regexp.MustCompile("(.)([A-Z][a-z]+)")
-	matchAllCap := regexp.MustCompile("([a-z0-9])([A-Z])")
-
 	snake := matchFirstCap.ReplaceAllString(str, "${1}_${2}")
 	snake = matchAllCap.ReplaceAllString(snake, "${1}_${2}")
 	return strings.ToLower(snake)
 }
 
-func formatValueForInsert(value interface{}) string {
-	switch v := value.(type) {
-	case string:
-		return fmt.Sprintf("$$%s$$", v)
-	case time.Time:
-		return fmt.Sprintf("'%s'", v.Format("2006-01-02 15:04:05"))
-	case *float64:
-		return fmt.Sprintf("%f", *v)
-	case *uint8:
-		return fmt.Sprintf("%d", *v)
-	case []string:
-		for i, _v := range v {
-			v[i] = fmt.Sprintf(`"%s"`, _v)
-			v[i] = strings.Replace(v[i], "'", "''", -1)
+// column is a struct field as the db tag maps it.
+type column struct {
+	index     int
+	name      string
+	pk        bool
+	readonly  bool
+	omitempty bool
+	optional  bool
+}
+
+var optionalType = reflect.TypeOf((*optional)(nil)).Elem()
+
+// columns caches the columns of each struct type, so a type is only
+// inspected once.
+var columns sync.Map
+
+// parseColumn reads the db tag of field, `db:"name,readonly,omitempty,pk"`.
+// Untagged fields are named after the field in snake case and left out when
+// zero. Readonly columns are maintained by the database and never written,
+// pk columns are never updated, and omitempty columns are left out when zero
+// or nil. Optional columns are left out when unset, whatever their options.
+func parseColumn(index int, field reflect.StructField) (column, bool) {
+	tag, ok := field.Tag.Lookup("db")
+	if !ok {
+		return column{index: index, name: toSnakeCase(field.Name), omitempty: true, optional: field.Type.Implements(optionalType)}, true
+	}
+
+	options := strings.Split(tag, ",")
+	if options[0] == "-" {
+		return column{}, false
+	}
+
+	c := column{index: index, name: options[0], optional: field.Type.Implements(optionalType)}
+	if c.name == "" {
+		c.name = toSnakeCase(field.Name)
+	}
+
+	for _, option := range options[1:] {
+		switch option {
+		case "pk":
+			c.pk = true
+		case "readonly":
+			c.readonly = true
+		case "omitempty":
+			c.omitempty = true
 		}
+	}
+
+	return c, true
+}
 
-		stringValue := strings.Join(v, ", ")
+func inspect(t reflect.Type) []column {
+	var cs []column
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
 
-		return fmt.Sprintf("'{%s}'", stringValue)
-	case pq.StringArray:
-		for i, _v := range v {
-			v[i] = fmt.Sprintf(`"%s"`, _v)
-			v[i] = strings.Replace(v[i], "'", "''", -1)
+		if c, ok := parseColumn(i, field); ok {
+			cs = append(cs, c)
 		}
+	}
+
+	return cs
+}
 
-		stringValue := strings.Join(v, ", ")
+func columnsOf(t reflect.Type) []column {
+	if cs, ok := columns.Load(t); ok {
+		return cs.([]column)
+	}
+
+	cs, _ := columns.LoadOrStore(t, inspect(t))
+	return cs.([]column)
+}
 
-		return fmt.Sprintf("'{%s}'", stringValue)
-	case sql.NullTime:
-		return fmt.Sprintf("'%s'", v.Time.Format("2006-01-02 15:04:05"))
-	case sql.NullString:
-		return fmt.Sprintf("$$%s$$", v.String)
-	default:
-		return fmt.Sprintf("%v", v)
+// PrimaryKey returns the column of the pk field of the struct type t, or an
+// empty string when it has none.
+func PrimaryKey(t reflect.Type) string {
+	for _, c := range columnsOf(t) {
+		if c.pk {
+			return c.name
+		}
 	}
+
+	return ""
 }
 
-func parseFieldsToString(schema any, ignore ...string) ([]string, []string) {
+// Field returns the field of schema mapped to column. It can only be set
+// when schema is a pointer.
+func Field(schema any, column string) (reflect.Value, bool) {
 	value := reflect.ValueOf(schema)
 	if value.Kind() == reflect.Ptr {
 		value = value.Elem()
 	}
 
-	var schemaFields []string
-	var schemaValues []string
-	for i := 0; i < value.NumField(); i++ {
+	for _, c := range columnsOf(value.Type()) {
+		if c.name == column {
+			return value.Field(c.index), true
+		}
+	}
+
+	return reflect.Value{}, false
+}
 
-		field := value.Field(i)
-		fieldName := toSnakeCase(value.Type().Field(i).Name)
-		shouldContinue := true
+// parseFields returns the writable columns of schema and their values, in
+// the same order, leaving out readonly columns, pk columns when update is
+// set and the columns named in ignore.
+func parseFields(schema any, update bool, ignore ...string) ([]string, []any) {
+	value := reflect.ValueOf(schema)
+	if value.Kind() == reflect.Ptr {
+		value = value.Elem()
+	}
 
-		if fieldName == "created_at" || fieldName == "updated_at" {
-			shouldContinue = false
+	cs := columnsOf(value.Type())
+	schemaFields := make([]string, 0, len(cs))
+	schemaValues := make([]any, 0, len(cs))
+	for _, c := range cs {
+		if c.readonly || (update && c.pk) || slices.Contains(ignore, c.name) {
+			continue
 		}
 
-		for _, _ignore := range ignore {
-			if _ignore == fieldName {
-				shouldContinue = false
+		field := value.Field(c.index)
+		if c.optional {
+			o := field.Interface().(optional)
+			if o.IsSet() {
+				schemaFields = append(schemaFields, c.name)
+				schemaValues = append(schemaValues, o.bound())
 			}
+
+			continue
 		}
 
-		if !shouldContinue {
+		if c.omitempty && field.IsZero() {
 			continue
 		}
 
-		if field.Kind() == reflect.Ptr && !field.IsNil() {
-			schemaFields = append(schemaFields, fieldName)
-			schemaValues = append(schemaValues, formatValueForInsert(field.Elem().Interface()))
-		} else if field.Kind() != reflect.Ptr && !reflect.DeepEqual(field.Interface(), reflect.Zero(field.Type()).Interface()) {
-			schemaFields = append(schemaFields, fieldName)
-			schemaValues = append(schemaValues, formatValueForInsert(field.Interface()))
+		if field.Kind() == reflect.Ptr {
+			if field.IsNil() {
+				schemaFields = append(schemaFields, c.name)
+				schemaValues = append(schemaValues, nil)
+				continue
+			}
+
+			field = field.Elem()
 		}
 
+		schemaFields = append(schemaFields, c.name)
+		schemaValues = append(schemaValues, field.Interface())
 	}
 
 	return schemaFields, schemaValues
 }
 
-func ParseFieldsToInsertQuery(schema any, ignore ...string) (string, string) {
-	fields, values := parseFieldsToString(schema, ignore...)
+func placeholders(n int) string {
+	return strings.TrimSuffix(strings.Repeat("?, ", n), ", ")
+}
+
+// ParseFieldsToInsertQuery returns the writable columns of schema, a ?
+// placeholder for each and their values, in the same order.
+func ParseFieldsToInsertQuery(schema any, ignore ...string) (string, string, []any) {
+	fields, values := parseFields(schema, false, ignore...)
 
-	return strings.Join(fields, ", "), strings.Join(values, ", ")
+	return strings.Join(fields, ", "), placeholders(len(fields)), values
 }
 
-func ParseArrayFieldsToInsertQuery(schemas any, ignore ...string) (string, string) {
+func ParseArrayFieldsToInsertQuery(schemas any, ignore ...string) (string, string, []any) {
 	var fieldsStr string
 	var valuesStr string
+	var args []any
 
 	sliceValue := reflect.ValueOf(schemas)
 	if sliceValue.Kind() == reflect.Ptr {
@@ -116,35 +199,43 @@ func ParseArrayFieldsToInsertQuery(schemas any, ignore ...string) (string, strin
 	if sliceValue.Kind() == reflect.Array || sliceValue.Kind() == reflect.Slice {
 		for i := 0; i < sliceValue.Len(); i++ {
 			schema := sliceValue.Index(i).Interface()
-			fields, values := parseFieldsToString(schema, ignore...)
+			fields, values := parseFields(schema, false, ignore...)
+			args = append(args, values...)
 
 			if i == 0 {
 				fieldsStr = strings.Join(fields, ", ")
-				valuesStr += fmt.Sprintf("(%s)", strings.Join(values, ", "))
+				valuesStr += fmt.Sprintf("(%s)", placeholders(len(fields)))
 			} else {
-				valuesStr += fmt.Sprintf(", (%s)", strings.Join(values, ", "))
+				valuesStr += fmt.Sprintf(", (%s)", placeholders(len(fields)))
 			}
 		}
 	}
 
-	return fieldsStr, valuesStr
+	return fieldsStr, valuesStr, args
 }
 
-func ParseFieldsToUpdateQuery(schema any, ignore ...string) string {
-	fields, values := parseFieldsToString(schema, ignore...)
+// ParseFieldsToUpdateQuery returns a "column = ?" assignment for each
+// writable column of schema but its primary key and their values, in the
+// same order.
+func ParseFieldsToUpdateQuery(schema any, ignore ...string) (string, []any) {
+	fields, values := parseFields(schema, true, ignore...)
 
-	var query []string
-	for i := range fields {
-		query = append(query, fmt.Sprintf("%s = %s", fields[i], values[i]))
+	query := make([]string, 0, len(fields))
+	for _, field := range fields {
+		query = append(query, field+" = ?")
 	}
 
-	return strings.Join(query, ", ")
+	return strings.Join(query, ", "), values
 }
 
+// QueryParams shapes a find query. Where takes ? placeholders bound, in
+// order, to Args; every other field is written into the query as is and must
+// never carry user input.
 type QueryParams struct {
 	Select    string
 	Join      []string
 	Where     string
+	Args      []any
 	OrderBy   string
 	SortOrder string
 	GroupBy   string
@@ -152,8 +243,12 @@ type QueryParams struct {
 	Limit     uint64
 }
 
-func PrepareFindQuery(query string, params QueryParams) string {
+// PrepareFindQuery fills the ? of query with params.Select and appends the
+// rest of params, returning the query, still with ? placeholders, and its
+// arguments.
+func PrepareFindQuery(query string, params QueryParams) (string, []any) {
 	query = strings.Replace(query, "?", params.Select, 1)
+	args := append([]any(nil), params.Args...)
 
 	if len(params.Join) != 0 {
 		join := strings.Join(params.Join, " ")
@@ -164,6 +259,10 @@ func PrepareFindQuery(query string, params QueryParams) string {
 		query = fmt.Sprintf("%s WHERE %s", query, params.Where)
 	}
 
+	if params.GroupBy != "" {
+		query = fmt.Sprintf("%s GROUP BY %s", query, params.GroupBy)
+	}
+
 	if params.OrderBy != "" {
 		query = fmt.Sprintf("%s ORDER BY %s", query, params.OrderBy)
 	}
@@ -172,17 +271,16 @@ func PrepareFindQuery(query string, params QueryParams) string {
 		query = fmt.Sprintf("%s %s", query, params.SortOrder)
 	}
 
-	if params.GroupBy != "" {
-		query = fmt.Sprintf("%s GROUP BY %s", query, params.GroupBy)
+	if params.Limit != 0 {
+		query = fmt.Sprintf("%s LIMIT ?", query)
+		args = append(args, params.Limit)
 	}
 
+	// SQLite and MySQL only take an offset after a limit.
 	if params.Offset != 0 {
-		query = fmt.Sprintf("%s OFFSET %d", query, params.Offset)
-	}
-
-	if params.Limit != 0 {
-		query = fmt.Sprintf("%s LIMIT %d", query, params.Limit)
+		query = fmt.Sprintf("%s OFFSET ?", query)
+		args = append(args, params.Offset)
 	}
 
-	return query
+	return query, args
 }
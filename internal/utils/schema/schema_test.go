@@ -3,15 +3,16 @@ package schema
 import (
 	"fmt"
 	"reflect"
+	"strings"
 	"testing"
 	"time"
 )
 
 type schemaTest struct {
-	ID        uint64
-	Name      string
-	CreatedAt time.Time
-	UpdatedAt time.Time
+	ID        uint64    `db:"id,pk,omitempty"`
+	Name      string    `db:"name,omitempty"`
+	CreatedAt time.Time `db:"created_at,readonly"`
+	UpdatedAt time.Time `db:"updated_at,readonly"`
 }
 
 var validSchema = schemaTest{
@@ -30,82 +31,165 @@ func TestToSnakeCase(t *testing.T) {
 	}
 }
 
-func TestFormatValueForInsert(t *testing.T) {
-	strValue := "Hello, how are you?"
-	timeValue := time.Date(2000, 1, 1, 0, 0, 0, 0, time.Local)
-	floatValue := 100.1
-	uint8Value := uint8(100)
-	strSliceValue := []string{"Hello", "how are you?"}
-
-	expectedValue := "$$Hello, how are you?$$"
-	value := formatValueForInsert(strValue)
-	if value != expectedValue {
-		t.Errorf("got: value = %s | expected: value = %s", value, expectedValue)
-	}
-
-	expectedValue = "'2000-01-01 00:00:00'"
-	value = formatValueForInsert(timeValue)
-	if value != expectedValue {
-		t.Errorf("got: value = %s | expected: value = %s", value, expectedValue)
+func TestParseFields(t *testing.T) {
+	expectedFields, expectedValues := []string{"id", "name"}, []any{uint64(100), "John"}
+	fields, values := parseFields(validSchema, false)
+	if !reflect.DeepEqual(fields, expectedFields) || !reflect.DeepEqual(values, expectedValues) {
+		t.Errorf("got: fields = %v and values = %v | expected: fields = %v and values = %v", fields, values, expectedFields, expectedValues)
 	}
 
-	expectedValue = fmt.Sprintf("%f", floatValue)
-	value = formatValueForInsert(&floatValue)
-	if value != expectedValue {
-		t.Errorf("got: value = %s | expected: value = %s", value, expectedValue)
+	expectedFields, expectedValues = []string{"name"}, []any{"John"}
+	fields, values = parseFields(validSchema, false, "id")
+	if !reflect.DeepEqual(fields, expectedFields) || !reflect.DeepEqual(values, expectedValues) {
+		t.Errorf("got: fields = %v and values = %v | expected: fields = %v and values = %v", fields, values, expectedFields, expectedValues)
 	}
+}
 
-	expectedValue = fmt.Sprintf("%d", uint8Value)
-	value = formatValueForInsert(&uint8Value)
-	if value != expectedValue {
-		t.Errorf("got: value = %s | expected: value = %s", value, expectedValue)
+func TestParseFields_Tags(t *testing.T) {
+	type tagged struct {
+		ID        uint64  `db:"id,pk"`
+		FullName  string  `db:"name"`
+		Nickname  string  `db:",omitempty"`
+		ManagerID *uint64 `db:"manager_id"`
+		Version   int     `db:"version,readonly"`
+		Cache     string  `db:"-"`
+		LastSeen  string
+		secret    string
 	}
 
-	expectedValue = `'{"Hello", "how are you?"}'`
-	value = formatValueForInsert(strSliceValue)
-	if value != expectedValue {
-		t.Errorf("got: value = %s | expected: value = %s", value, expectedValue)
-	}
-}
+	s := tagged{ID: 1, Version: 2, Cache: "cached", secret: "secret"}
 
-func TestParseFieldsToString(t *testing.T) {
-	expectedFields, expectedValues := []string{"id", "name"}, []string{"100", "$$John$$"}
-	fields, values := parseFieldsToString(validSchema)
+	expectedFields, expectedValues := []string{"id", "name", "manager_id"}, []any{uint64(1), "", nil}
+	fields, values := parseFields(s, false)
 	if !reflect.DeepEqual(fields, expectedFields) || !reflect.DeepEqual(values, expectedValues) {
 		t.Errorf("got: fields = %v and values = %v | expected: fields = %v and values = %v", fields, values, expectedFields, expectedValues)
 	}
 
-	expectedFields, expectedValues = []string{"name"}, []string{"$$John$$"}
-	fields, values = parseFieldsToString(validSchema, "id")
+	managerID := uint64(3)
+	s = tagged{FullName: "John", Nickname: "Jo", ManagerID: &managerID, LastSeen: "today"}
+
+	expectedFields, expectedValues = []string{"name", "nickname", "manager_id", "last_seen"}, []any{"John", "Jo", uint64(3), "today"}
+	fields, values = parseFields(&s, true)
 	if !reflect.DeepEqual(fields, expectedFields) || !reflect.DeepEqual(values, expectedValues) {
 		t.Errorf("got: fields = %v and values = %v | expected: fields = %v and values = %v", fields, values, expectedFields, expectedValues)
 	}
 }
 
+func TestColumnsOf(t *testing.T) {
+	typ := reflect.TypeOf(schemaTest{})
+
+	first, second := columnsOf(typ), columnsOf(typ)
+	if len(first) != 4 || &first[0] != &second[0] {
+		t.Errorf("got: columns = %v and %v | expected the same 4 cached columns", first, second)
+	}
+}
+
 func TestParseFieldsToInsertQuery(t *testing.T) {
-	expectedFields, expectedValues := "id, name", "100, $$John$$"
-	fields, values := ParseFieldsToInsertQuery(validSchema)
-	if !reflect.DeepEqual(fields, expectedFields) || !reflect.DeepEqual(values, expectedValues) {
-		t.Errorf("got: fields = %v and values = %v | expected: fields = %v and values = %v", fields, values, expectedFields, expectedValues)
+	expectedFields, expectedValues, expectedArgs := "id, name", "?, ?", []any{uint64(100), "John"}
+	fields, values, args := ParseFieldsToInsertQuery(validSchema)
+	if fields != expectedFields || values != expectedValues || !reflect.DeepEqual(args, expectedArgs) {
+		t.Errorf("got: fields = %v, values = %v and args = %v | expected: fields = %v, values = %v and args = %v", fields, values, args, expectedFields, expectedValues, expectedArgs)
 	}
 
-	expectedFields, expectedValues = "name", "$$John$$"
-	fields, values = ParseFieldsToInsertQuery(validSchema, "id")
-	if !reflect.DeepEqual(fields, expectedFields) || !reflect.DeepEqual(values, expectedValues) {
-		t.Errorf("got: fields = %v and values = %v | expected: fields = %v and values = %v", fields, values, expectedFields, expectedValues)
+	expectedFields, expectedValues, expectedArgs = "name", "?", []any{"John"}
+	fields, values, args = ParseFieldsToInsertQuery(validSchema, "id")
+	if fields != expectedFields || values != expectedValues || !reflect.DeepEqual(args, expectedArgs) {
+		t.Errorf("got: fields = %v, values = %v and args = %v | expected: fields = %v, values = %v and args = %v", fields, values, args, expectedFields, expectedValues, expectedArgs)
+	}
+}
+
+func TestParseArrayFieldsToInsertQuery(t *testing.T) {
+	schemas := []schemaTest{{Name: "John"}, {Name: "Jane"}}
+
+	expectedFields, expectedValues, expectedArgs := "name", "(?), (?)", []any{"John", "Jane"}
+	fields, values, args := ParseArrayFieldsToInsertQuery(schemas)
+	if fields != expectedFields || values != expectedValues || !reflect.DeepEqual(args, expectedArgs) {
+		t.Errorf("got: fields = %v, values = %v and args = %v | expected: fields = %v, values = %v and args = %v", fields, values, args, expectedFields, expectedValues, expectedArgs)
 	}
 }
 
 func TestParseFieldsToIUpdateQuery(t *testing.T) {
-	expectedValue := "id = 100, name = $$John$$"
-	value := ParseFieldsToUpdateQuery(validSchema)
-	if !reflect.DeepEqual(value, expectedValue) {
-		t.Errorf("got: value = %v | expected: value = %v", value, expectedValue)
+	expectedValue, expectedArgs := "name = ?", []any{"John"}
+	value, args := ParseFieldsToUpdateQuery(validSchema)
+	if value != expectedValue || !reflect.DeepEqual(args, expectedArgs) {
+		t.Errorf("got: value = %v and args = %v | expected: value = %v and args = %v", value, args, expectedValue, expectedArgs)
+	}
+}
+
+func TestParseFields_Injection(t *testing.T) {
+	payloads := []string{
+		"$$); DROP TABLE tasks; --",
+		"' OR 1=1 --",
 	}
 
-	expectedValue = "name = $$John$$"
-	value = ParseFieldsToUpdateQuery(validSchema, "id")
-	if !reflect.DeepEqual(value, expectedValue) {
-		t.Errorf("got: value = %v | expected: value = %v", value, expectedValue)
+	for _, payload := range payloads {
+		s := schemaTest{Name: payload}
+
+		fields, values, args := ParseFieldsToInsertQuery(s)
+		query := fmt.Sprintf("INSERT INTO t (%s) VALUES (%s)", fields, values)
+		if strings.Contains(query, payload) || !reflect.DeepEqual(args, []any{payload}) {
+			t.Errorf("got: query = %v and args = %v | expected the payload to be bound verbatim", query, args)
+		}
+
+		set, args := ParseFieldsToUpdateQuery(s)
+		if strings.Contains(set, payload) || !reflect.DeepEqual(args, []any{payload}) {
+			t.Errorf("got: set = %v and args = %v | expected the payload to be bound verbatim", set, args)
+		}
 	}
 }
+
+func TestPrepareFindQuery(t *testing.T) {
+	expectedQuery, expectedArgs := "SELECT t.* FROM t WHERE t.id = ? AND t.name = ? ORDER BY t.id DESC LIMIT ? OFFSET ?", []any{1, "John", uint64(10), uint64(20)}
+	query, args := PrepareFindQuery("SELECT ? FROM t", QueryParams{
+		Select:    "t.*",
+		Where:     "t.id = ? AND t.name = ?",
+		Args:      []any{1, "John"},
+		OrderBy:   "t.id",
+		SortOrder: "DESC",
+		Offset:    20,
+		Limit:     10,
+	})
+	if query != expectedQuery || !reflect.DeepEqual(args, expectedArgs) {
+		t.Errorf("got: query = %v and args = %v | expected: query = %v and args = %v", query, args, expectedQuery, expectedArgs)
+	}
+}
+
+// The uncached runs inspect the type on every call, as every call did before
+// column metadata was cached.
+func BenchmarkParseFieldsToInsertQuery(b *testing.B) {
+	typ := reflect.TypeOf(validSchema)
+
+	b.Run("Cached", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			ParseFieldsToInsertQuery(validSchema)
+		}
+	})
+
+	b.Run("Uncached", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			columns.Delete(typ)
+			ParseFieldsToInsertQuery(validSchema)
+		}
+	})
+}
+
+func BenchmarkParseFieldsToUpdateQuery(b *testing.B) {
+	typ := reflect.TypeOf(validSchema)
+
+	b.Run("Cached", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			ParseFieldsToUpdateQuery(validSchema)
+		}
+	})
+
+	b.Run("Uncached", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			columns.Delete(typ)
+			ParseFieldsToUpdateQuery(validSchema)
+		}
+	})
+}
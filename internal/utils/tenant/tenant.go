@@ -0,0 +1,38 @@
+package tenant
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/henriqueassiss/advanced-golang-api/internal/utils/errorMsg"
+)
+
+type contextKey struct{}
+
+func NewContext(ctx context.Context, workspaceID uint64) context.Context {
+	return context.WithValue(ctx, contextKey{}, workspaceID)
+}
+
+func FromContext(ctx context.Context) (uint64, bool) {
+	workspaceID, ok := ctx.Value(contextKey{}).(uint64)
+	return workspaceID, ok && workspaceID != 0
+}
+
+// Where appends the workspace filter of ctx to a WHERE clause with ?
+// placeholders and to its arguments. It fails when ctx carries no workspace so
+// that a missing tenant never means "every tenant".
+func Where(ctx context.Context, column, where string, args []any) (string, []any, error) {
+	workspaceID, ok := FromContext(ctx)
+	if !ok {
+		return "", nil, errorMsg.ErrTenantRequired
+	}
+
+	args = append(append([]any(nil), args...), workspaceID)
+
+	filter := fmt.Sprintf("%s = ?", column)
+	if where == "" {
+		return filter, args, nil
+	}
+
+	return fmt.Sprintf("(%s) AND %s", where, filter), args, nil
+}
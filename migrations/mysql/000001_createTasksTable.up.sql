@@ -0,0 +1,6 @@
+CREATE TABLE IF NOT EXISTS tasks(
+	id          BIGINT UNSIGNED AUTO_INCREMENT PRIMARY KEY,
+	title       TEXT NOT NULL,
+	description TEXT,
+	updated_at  TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
@@ -0,0 +1,7 @@
+CREATE TABLE IF NOT EXISTS users(
+	id          BIGINT UNSIGNED AUTO_INCREMENT PRIMARY KEY,
+	name        TEXT NOT NULL,
+	email       VARCHAR(255) NOT NULL UNIQUE,
+	password    TEXT NOT NULL,
+	updated_at  TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
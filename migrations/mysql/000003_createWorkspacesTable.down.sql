@@ -0,0 +1,6 @@
+ALTER TABLE tasks DROP FOREIGN KEY tasks_workspace_id_fk;
+DROP INDEX tasks_workspace_id_idx ON tasks;
+ALTER TABLE tasks DROP COLUMN workspace_id;
+
+DROP TABLE IF EXISTS workspace_members;
+DROP TABLE IF EXISTS workspaces;
@@ -0,0 +1,26 @@
+CREATE TABLE IF NOT EXISTS workspaces(
+	id          BIGINT UNSIGNED AUTO_INCREMENT PRIMARY KEY,
+	name        TEXT NOT NULL,
+	slug        VARCHAR(255) NOT NULL UNIQUE,
+	updated_at  TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE IF NOT EXISTS workspace_members(
+	workspace_id  BIGINT UNSIGNED NOT NULL,
+	user_id       BIGINT UNSIGNED NOT NULL,
+	role          VARCHAR(32) NOT NULL DEFAULT 'member',
+	PRIMARY KEY (workspace_id, user_id),
+	FOREIGN KEY (workspace_id) REFERENCES workspaces(id) ON DELETE CASCADE,
+	FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+);
+
+INSERT IGNORE INTO workspaces (name, slug) VALUES ('Default', 'default');
+
+ALTER TABLE tasks ADD COLUMN workspace_id BIGINT UNSIGNED;
+UPDATE tasks SET workspace_id = (SELECT id FROM workspaces WHERE slug = 'default') WHERE workspace_id IS NULL;
+
+CREATE INDEX tasks_workspace_id_idx ON tasks (workspace_id);
+
+ALTER TABLE tasks
+	MODIFY workspace_id BIGINT UNSIGNED NOT NULL,
+	ADD CONSTRAINT tasks_workspace_id_fk FOREIGN KEY (workspace_id) REFERENCES workspaces(id) ON DELETE CASCADE;
@@ -0,0 +1,4 @@
+DROP TABLE IF EXISTS task_shares;
+DROP TABLE IF EXISTS task_assignees;
+
+ALTER TABLE tasks DROP FOREIGN KEY tasks_user_id_fk, DROP COLUMN user_id;
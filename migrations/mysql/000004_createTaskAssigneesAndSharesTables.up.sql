@@ -0,0 +1,22 @@
+ALTER TABLE tasks
+	ADD COLUMN user_id BIGINT UNSIGNED,
+	ADD CONSTRAINT tasks_user_id_fk FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE SET NULL;
+
+CREATE TABLE IF NOT EXISTS task_assignees(
+	task_id  BIGINT UNSIGNED NOT NULL,
+	user_id  BIGINT UNSIGNED NOT NULL,
+	PRIMARY KEY (task_id, user_id),
+	INDEX task_assignees_user_id_idx (user_id),
+	FOREIGN KEY (task_id) REFERENCES tasks(id) ON DELETE CASCADE,
+	FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+);
+
+CREATE TABLE IF NOT EXISTS task_shares(
+	task_id     BIGINT UNSIGNED NOT NULL,
+	user_id     BIGINT UNSIGNED NOT NULL,
+	permission  VARCHAR(16) NOT NULL CHECK (permission IN ('read', 'write')),
+	PRIMARY KEY (task_id, user_id),
+	INDEX task_shares_user_id_idx (user_id),
+	FOREIGN KEY (task_id) REFERENCES tasks(id) ON DELETE CASCADE,
+	FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+);
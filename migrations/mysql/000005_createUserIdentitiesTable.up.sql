@@ -0,0 +1,8 @@
+CREATE TABLE IF NOT EXISTS user_identities(
+	issuer   VARCHAR(255) NOT NULL,
+	subject  VARCHAR(255) NOT NULL,
+	user_id  BIGINT UNSIGNED NOT NULL,
+	PRIMARY KEY (issuer, subject),
+	INDEX user_identities_user_id_idx (user_id),
+	FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+);
@@ -0,0 +1,7 @@
+DROP TABLE IF EXISTS task_labels;
+DROP INDEX tasks_workspace_id_created_at_idx ON tasks;
+
+ALTER TABLE tasks
+	DROP COLUMN completed_at,
+	DROP COLUMN created_at,
+	DROP COLUMN status;
@@ -0,0 +1,19 @@
+ALTER TABLE tasks
+	ADD COLUMN status VARCHAR(16) NOT NULL DEFAULT 'todo' CHECK (status IN ('todo', 'doing', 'done')),
+	ADD COLUMN created_at TIMESTAMP NULL,
+	ADD COLUMN completed_at TIMESTAMP NULL;
+
+-- Tasks created before are taken to be as old as their last update.
+UPDATE tasks SET created_at = updated_at WHERE created_at IS NULL;
+
+ALTER TABLE tasks MODIFY created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP;
+
+CREATE INDEX tasks_workspace_id_created_at_idx ON tasks (workspace_id, created_at);
+
+CREATE TABLE IF NOT EXISTS task_labels(
+	task_id  BIGINT UNSIGNED NOT NULL,
+	label    VARCHAR(64) NOT NULL,
+	PRIMARY KEY (task_id, label),
+	INDEX task_labels_label_idx (label),
+	FOREIGN KEY (task_id) REFERENCES tasks(id) ON DELETE CASCADE
+);
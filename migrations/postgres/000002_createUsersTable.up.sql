@@ -0,0 +1,11 @@
+BEGIN;
+
+CREATE TABLE IF NOT EXISTS users(
+	id          BIGSERIAL PRIMARY KEY,
+	name        TEXT NOT NULL,
+	email       TEXT NOT NULL UNIQUE,
+	password    TEXT NOT NULL,
+	updated_at  TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+COMMIT;
\ No newline at end of file
@@ -0,0 +1,9 @@
+BEGIN;
+
+DROP INDEX IF EXISTS tasks_workspace_id_idx;
+ALTER TABLE tasks DROP COLUMN IF EXISTS workspace_id;
+
+DROP TABLE IF EXISTS workspace_members;
+DROP TABLE IF EXISTS workspaces;
+
+COMMIT;
\ No newline at end of file
@@ -0,0 +1,25 @@
+BEGIN;
+
+CREATE TABLE IF NOT EXISTS workspaces(
+	id          BIGSERIAL PRIMARY KEY,
+	name        TEXT NOT NULL,
+	slug        TEXT NOT NULL UNIQUE,
+	updated_at  TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE IF NOT EXISTS workspace_members(
+	workspace_id  BIGINT NOT NULL REFERENCES workspaces(id) ON DELETE CASCADE,
+	user_id       BIGINT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+	role          TEXT NOT NULL DEFAULT 'member',
+	PRIMARY KEY (workspace_id, user_id)
+);
+
+INSERT INTO workspaces (name, slug) VALUES ('Default', 'default') ON CONFLICT (slug) DO NOTHING;
+
+ALTER TABLE tasks ADD COLUMN IF NOT EXISTS workspace_id BIGINT REFERENCES workspaces(id) ON DELETE CASCADE;
+UPDATE tasks SET workspace_id = (SELECT id FROM workspaces WHERE slug = 'default') WHERE workspace_id IS NULL;
+ALTER TABLE tasks ALTER COLUMN workspace_id SET NOT NULL;
+
+CREATE INDEX IF NOT EXISTS tasks_workspace_id_idx ON tasks (workspace_id);
+
+COMMIT;
\ No newline at end of file
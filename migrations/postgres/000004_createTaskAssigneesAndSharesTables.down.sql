@@ -0,0 +1,8 @@
+BEGIN;
+
+DROP TABLE IF EXISTS task_shares;
+DROP TABLE IF EXISTS task_assignees;
+
+ALTER TABLE tasks DROP COLUMN IF EXISTS user_id;
+
+COMMIT;
\ No newline at end of file
@@ -0,0 +1,8 @@
+BEGIN;
+
+DROP TRIGGER IF EXISTS task_shares_notify_change ON task_shares;
+DROP TRIGGER IF EXISTS task_assignees_notify_change ON task_assignees;
+DROP TRIGGER IF EXISTS tasks_notify_change ON tasks;
+DROP FUNCTION IF EXISTS notify_task_change();
+
+COMMIT;
@@ -0,0 +1,45 @@
+BEGIN;
+
+-- Every change to a task, its assignees or its shares is announced on the
+-- task_changes channel, so replicas keeping their own caches can drop what
+-- they hold of it.
+CREATE OR REPLACE FUNCTION notify_task_change() RETURNS TRIGGER AS $$
+DECLARE
+	changed_task_id BIGINT;
+	changed_workspace_id BIGINT;
+BEGIN
+	IF TG_TABLE_NAME = 'tasks' THEN
+		changed_task_id := COALESCE(NEW.id, OLD.id);
+		changed_workspace_id := COALESCE(NEW.workspace_id, OLD.workspace_id);
+	ELSE
+		changed_task_id := COALESCE(NEW.task_id, OLD.task_id);
+		SELECT workspace_id INTO changed_workspace_id FROM tasks WHERE id = changed_task_id;
+
+		-- Rows deleted along with their task were announced by the task.
+		IF changed_workspace_id IS NULL THEN
+			RETURN NULL;
+		END IF;
+	END IF;
+
+	PERFORM pg_notify('task_changes', json_build_object(
+		'workspaceId', changed_workspace_id,
+		'taskId', changed_task_id
+	)::text);
+
+	RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER tasks_notify_change
+	AFTER INSERT OR UPDATE OR DELETE ON tasks
+	FOR EACH ROW EXECUTE FUNCTION notify_task_change();
+
+CREATE TRIGGER task_assignees_notify_change
+	AFTER INSERT OR UPDATE OR DELETE ON task_assignees
+	FOR EACH ROW EXECUTE FUNCTION notify_task_change();
+
+CREATE TRIGGER task_shares_notify_change
+	AFTER INSERT OR UPDATE OR DELETE ON task_shares
+	FOR EACH ROW EXECUTE FUNCTION notify_task_change();
+
+COMMIT;
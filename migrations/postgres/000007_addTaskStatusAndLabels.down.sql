@@ -0,0 +1,11 @@
+BEGIN;
+
+DROP TABLE IF EXISTS task_labels;
+DROP INDEX IF EXISTS tasks_workspace_id_created_at_idx;
+
+ALTER TABLE tasks
+	DROP COLUMN IF EXISTS completed_at,
+	DROP COLUMN IF EXISTS created_at,
+	DROP COLUMN IF EXISTS status;
+
+COMMIT;
\ No newline at end of file
@@ -0,0 +1,25 @@
+BEGIN;
+
+ALTER TABLE tasks
+	ADD COLUMN IF NOT EXISTS status TEXT NOT NULL DEFAULT 'todo' CHECK (status IN ('todo', 'doing', 'done')),
+	ADD COLUMN IF NOT EXISTS created_at TIMESTAMP WITH TIME ZONE,
+	ADD COLUMN IF NOT EXISTS completed_at TIMESTAMP WITH TIME ZONE;
+
+-- Tasks created before are taken to be as old as their last update.
+UPDATE tasks SET created_at = updated_at WHERE created_at IS NULL;
+
+ALTER TABLE tasks
+	ALTER COLUMN created_at SET DEFAULT CURRENT_TIMESTAMP,
+	ALTER COLUMN created_at SET NOT NULL;
+
+CREATE INDEX IF NOT EXISTS tasks_workspace_id_created_at_idx ON tasks (workspace_id, created_at);
+
+CREATE TABLE IF NOT EXISTS task_labels(
+	task_id  BIGINT NOT NULL REFERENCES tasks(id) ON DELETE CASCADE,
+	label    TEXT NOT NULL,
+	PRIMARY KEY (task_id, label)
+);
+
+CREATE INDEX IF NOT EXISTS task_labels_label_idx ON task_labels (label);
+
+COMMIT;
\ No newline at end of file
@@ -0,0 +1,7 @@
+CREATE TABLE IF NOT EXISTS users(
+	id          INTEGER PRIMARY KEY AUTOINCREMENT,
+	name        TEXT NOT NULL,
+	email       TEXT NOT NULL UNIQUE,
+	password    TEXT NOT NULL,
+	updated_at  TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
@@ -0,0 +1,19 @@
+DROP INDEX IF EXISTS tasks_workspace_id_idx;
+
+-- SQLite cannot drop a column with a foreign key, so tasks is rebuilt
+-- without it.
+CREATE TABLE tasks_old(
+	id          INTEGER PRIMARY KEY AUTOINCREMENT,
+	title       TEXT NOT NULL,
+	description TEXT,
+	updated_at  TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+INSERT INTO tasks_old (id, title, description, updated_at)
+SELECT id, title, description, updated_at FROM tasks;
+
+DROP TABLE tasks;
+ALTER TABLE tasks_old RENAME TO tasks;
+
+DROP TABLE IF EXISTS workspace_members;
+DROP TABLE IF EXISTS workspaces;
@@ -0,0 +1,33 @@
+CREATE TABLE IF NOT EXISTS workspaces(
+	id          INTEGER PRIMARY KEY AUTOINCREMENT,
+	name        TEXT NOT NULL,
+	slug        TEXT NOT NULL UNIQUE,
+	updated_at  TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE IF NOT EXISTS workspace_members(
+	workspace_id  INTEGER NOT NULL REFERENCES workspaces(id) ON DELETE CASCADE,
+	user_id       INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+	role          TEXT NOT NULL DEFAULT 'member',
+	PRIMARY KEY (workspace_id, user_id)
+);
+
+INSERT INTO workspaces (name, slug) VALUES ('Default', 'default') ON CONFLICT (slug) DO NOTHING;
+
+-- SQLite cannot add a NOT NULL column without a default, so tasks is rebuilt
+-- with it.
+CREATE TABLE tasks_new(
+	id            INTEGER PRIMARY KEY AUTOINCREMENT,
+	title         TEXT NOT NULL,
+	description   TEXT,
+	updated_at    TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+	workspace_id  INTEGER NOT NULL REFERENCES workspaces(id) ON DELETE CASCADE
+);
+
+INSERT INTO tasks_new (id, title, description, updated_at, workspace_id)
+SELECT id, title, description, updated_at, (SELECT id FROM workspaces WHERE slug = 'default') FROM tasks;
+
+DROP TABLE tasks;
+ALTER TABLE tasks_new RENAME TO tasks;
+
+CREATE INDEX IF NOT EXISTS tasks_workspace_id_idx ON tasks (workspace_id);
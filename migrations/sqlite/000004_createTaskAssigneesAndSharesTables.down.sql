@@ -0,0 +1,22 @@
+DROP TABLE IF EXISTS task_shares;
+DROP TABLE IF EXISTS task_assignees;
+
+-- SQLite cannot drop a column with a foreign key, so tasks is rebuilt
+-- without it.
+DROP INDEX IF EXISTS tasks_workspace_id_idx;
+
+CREATE TABLE tasks_old(
+	id            INTEGER PRIMARY KEY AUTOINCREMENT,
+	title         TEXT NOT NULL,
+	description   TEXT,
+	updated_at    TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+	workspace_id  INTEGER NOT NULL REFERENCES workspaces(id) ON DELETE CASCADE
+);
+
+INSERT INTO tasks_old (id, title, description, updated_at, workspace_id)
+SELECT id, title, description, updated_at, workspace_id FROM tasks;
+
+DROP TABLE tasks;
+ALTER TABLE tasks_old RENAME TO tasks;
+
+CREATE INDEX IF NOT EXISTS tasks_workspace_id_idx ON tasks (workspace_id);
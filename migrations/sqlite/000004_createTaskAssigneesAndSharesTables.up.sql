@@ -0,0 +1,17 @@
+ALTER TABLE tasks ADD COLUMN user_id INTEGER REFERENCES users(id) ON DELETE SET NULL;
+
+CREATE TABLE IF NOT EXISTS task_assignees(
+	task_id  INTEGER NOT NULL REFERENCES tasks(id) ON DELETE CASCADE,
+	user_id  INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+	PRIMARY KEY (task_id, user_id)
+);
+
+CREATE TABLE IF NOT EXISTS task_shares(
+	task_id     INTEGER NOT NULL REFERENCES tasks(id) ON DELETE CASCADE,
+	user_id     INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+	permission  TEXT NOT NULL CHECK (permission IN ('read', 'write')),
+	PRIMARY KEY (task_id, user_id)
+);
+
+CREATE INDEX IF NOT EXISTS task_assignees_user_id_idx ON task_assignees (user_id);
+CREATE INDEX IF NOT EXISTS task_shares_user_id_idx ON task_shares (user_id);
@@ -0,0 +1,8 @@
+CREATE TABLE IF NOT EXISTS user_identities(
+	issuer   TEXT NOT NULL,
+	subject  TEXT NOT NULL,
+	user_id  INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+	PRIMARY KEY (issuer, subject)
+);
+
+CREATE INDEX IF NOT EXISTS user_identities_user_id_idx ON user_identities (user_id);
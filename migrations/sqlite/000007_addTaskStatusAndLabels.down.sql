@@ -0,0 +1,7 @@
+DROP TABLE IF EXISTS task_labels;
+DROP INDEX IF EXISTS tasks_workspace_id_created_at_idx;
+DROP TRIGGER IF EXISTS tasks_created_at;
+
+ALTER TABLE tasks DROP COLUMN created_at;
+ALTER TABLE tasks DROP COLUMN completed_at;
+ALTER TABLE tasks DROP COLUMN status;
@@ -0,0 +1,24 @@
+ALTER TABLE tasks ADD COLUMN status TEXT NOT NULL DEFAULT 'todo' CHECK (status IN ('todo', 'doing', 'done'));
+ALTER TABLE tasks ADD COLUMN completed_at TIMESTAMP;
+
+-- SQLite cannot add a column defaulting to CURRENT_TIMESTAMP, so new tasks
+-- get it from a trigger. Tasks created before are taken to be as old as
+-- their last update.
+ALTER TABLE tasks ADD COLUMN created_at TIMESTAMP;
+UPDATE tasks SET created_at = updated_at WHERE created_at IS NULL;
+
+CREATE TRIGGER IF NOT EXISTS tasks_created_at AFTER INSERT ON tasks
+WHEN NEW.created_at IS NULL
+BEGIN
+	UPDATE tasks SET created_at = CURRENT_TIMESTAMP WHERE id = NEW.id;
+END;
+
+CREATE INDEX IF NOT EXISTS tasks_workspace_id_created_at_idx ON tasks (workspace_id, created_at);
+
+CREATE TABLE IF NOT EXISTS task_labels(
+	task_id  INTEGER NOT NULL REFERENCES tasks(id) ON DELETE CASCADE,
+	label    TEXT NOT NULL,
+	PRIMARY KEY (task_id, label)
+);
+
+CREATE INDEX IF NOT EXISTS task_labels_label_idx ON task_labels (label);
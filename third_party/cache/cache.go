@@ -1,15 +1,124 @@
 package cache
 
 import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
 	"github.com/henriqueassiss/advanced-golang-api/config"
 
 	"github.com/redis/go-redis/v9"
 )
 
-func New(cfg config.Cache) *redis.Client {
-	return redis.NewClient(&redis.Options{
-		Addr:     cfg.Address,
-		Password: cfg.Password,
-		DB:       cfg.DB,
-	})
+var ErrNotFound = errors.New("run-time: cache key not found")
+
+// KeepTTL, passed as a ttl, keeps the expiration a key already has. A ttl of
+// zero means the key never expires.
+const KeepTTL time.Duration = -1
+
+// Cache is a key-value store with expiration and tags. Tagging a key adds it
+// to a set that can be listed with Keys or dropped at once with Invalidate;
+// tags are additive and only forgotten when the key goes away. The keys of a
+// tag are expected to share a ttl, since the tag lives as long as the key it
+// was last added with.
+type Cache interface {
+	Get(ctx context.Context, key string) ([]byte, error)
+	Set(ctx context.Context, key string, value []byte, ttl time.Duration, tags ...string) error
+	// Add sets key only if it does not exist and reports whether it did.
+	Add(ctx context.Context, key string, value []byte, ttl time.Duration, tags ...string) (bool, error)
+	// Swap sets key only if it still holds old and reports whether it did.
+	Swap(ctx context.Context, key string, old, value []byte, ttl time.Duration, tags ...string) (bool, error)
+	// Pop returns and deletes key, so only one caller ever gets its value.
+	Pop(ctx context.Context, key string) ([]byte, error)
+	Delete(ctx context.Context, keys ...string) error
+	// TTL returns the time key has left, or zero if it never expires.
+	TTL(ctx context.Context, key string) (time.Duration, error)
+	Keys(ctx context.Context, tag string) ([]string, error)
+	Invalidate(ctx context.Context, tags ...string) error
+}
+
+func tlsConfig(cfg config.Cache) (*tls.Config, error) {
+	if !cfg.TLS {
+		return nil, nil
+	}
+
+	c := &tls.Config{
+		MinVersion: tls.VersionTLS12,
+		ServerName: cfg.TLSServerName,
+	}
+
+	if cfg.TLSCACert != "" {
+		pem, err := os.ReadFile(cfg.TLSCACert)
+		if err != nil {
+			return nil, err
+		}
+
+		c.RootCAs = x509.NewCertPool()
+		if !c.RootCAs.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("no certificate found in %s", cfg.TLSCACert)
+		}
+	}
+
+	if cfg.TLSCert != "" || cfg.TLSKey != "" {
+		cert, err := tls.LoadX509KeyPair(cfg.TLSCert, cfg.TLSKey)
+		if err != nil {
+			return nil, err
+		}
+
+		c.Certificates = []tls.Certificate{cert}
+	}
+
+	return c, nil
+}
+
+// New connects to Redis in the mode cfg asks for. It does not dial: use Ping
+// to find out whether Redis can be reached.
+func New(cfg config.Cache) (redis.UniversalClient, error) {
+	tlsConfig, err := tlsConfig(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	opts := &redis.UniversalOptions{
+		Addrs:            cfg.Address,
+		MasterName:       cfg.MasterName,
+		Username:         cfg.Username,
+		Password:         cfg.Password,
+		SentinelPassword: cfg.SentinelPassword,
+		DB:               cfg.DB,
+		TLSConfig:        tlsConfig,
+		DialTimeout:      cfg.DialTimeout,
+		ReadTimeout:      cfg.ReadTimeout,
+		WriteTimeout:     cfg.WriteTimeout,
+		PoolSize:         cfg.PoolSize,
+		MinIdleConns:     cfg.MinIdleConns,
+		PoolTimeout:      cfg.PoolTimeout,
+	}
+
+	switch cfg.Mode {
+	case "standalone":
+		if len(cfg.Address) != 1 {
+			return nil, errors.New("standalone redis takes a single address")
+		}
+
+		return redis.NewClient(opts.Simple()), nil
+	case "sentinel":
+		if cfg.MasterName == "" {
+			return nil, errors.New("sentinel redis needs a master name")
+		}
+
+		return redis.NewFailoverClient(opts.Failover()), nil
+	case "cluster":
+		if cfg.DB != 0 {
+			return nil, errors.New("cluster redis only has database 0")
+		}
+
+		return redis.NewClusterClient(opts.Cluster()), nil
+	default:
+		return nil, fmt.Errorf("unknown redis mode %q", cfg.Mode)
+	}
 }
@@ -7,10 +7,10 @@ import (
 	"github.com/redis/go-redis/v9"
 )
 
-func NewMock(t *testing.T) *redis.Client {
+func NewMock(t *testing.T) *Redis {
 	cacheMock := miniredis.RunT(t)
 
-	return redis.NewClient(&redis.Options{
+	return NewRedis(redis.NewClient(&redis.Options{
 		Addr: cacheMock.Addr(),
-	})
+	}))
 }
@@ -0,0 +1,241 @@
+package cache
+
+import (
+	"context"
+	"encoding/pem"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/henriqueassiss/advanced-golang-api/config"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+)
+
+// testCache runs the behaviour every Cache backend has to share.
+func testCache(t *testing.T, newCache func(t *testing.T) Cache) {
+	ctx := context.TODO()
+
+	t.Run("Get and Set", func(t *testing.T) {
+		c := newCache(t)
+
+		_, err := c.Get(ctx, "a")
+		assert.Equal(t, ErrNotFound, err)
+
+		err = c.Set(ctx, "a", []byte("1"), time.Minute)
+		assert.Nil(t, err)
+
+		value, err := c.Get(ctx, "a")
+		assert.Nil(t, err)
+		assert.Equal(t, []byte("1"), value)
+	})
+
+	t.Run("TTL", func(t *testing.T) {
+		c := newCache(t)
+
+		_ = c.Set(ctx, "a", []byte("1"), time.Minute)
+		_ = c.Set(ctx, "b", []byte("1"), 0)
+
+		ttl, err := c.TTL(ctx, "a")
+		assert.Nil(t, err)
+		assert.InDelta(t, time.Minute, ttl, float64(time.Second))
+
+		_ = c.Set(ctx, "a", []byte("2"), KeepTTL)
+
+		ttl, err = c.TTL(ctx, "a")
+		assert.Nil(t, err)
+		assert.InDelta(t, time.Minute, ttl, float64(time.Second))
+
+		ttl, err = c.TTL(ctx, "b")
+		assert.Nil(t, err)
+		assert.Equal(t, time.Duration(0), ttl)
+
+		_, err = c.TTL(ctx, "c")
+		assert.Equal(t, ErrNotFound, err)
+	})
+
+	t.Run("Add", func(t *testing.T) {
+		c := newCache(t)
+
+		ok, err := c.Add(ctx, "a", []byte("1"), time.Minute)
+		assert.Nil(t, err)
+		assert.True(t, ok)
+
+		ok, err = c.Add(ctx, "a", []byte("2"), time.Minute)
+		assert.Nil(t, err)
+		assert.False(t, ok)
+
+		value, _ := c.Get(ctx, "a")
+		assert.Equal(t, []byte("1"), value)
+	})
+
+	t.Run("Swap", func(t *testing.T) {
+		c := newCache(t)
+
+		ok, err := c.Swap(ctx, "a", []byte("1"), []byte("2"), time.Minute)
+		assert.Nil(t, err)
+		assert.False(t, ok, "missing keys are not swapped")
+
+		_ = c.Set(ctx, "a", []byte("1"), time.Minute)
+
+		ok, err = c.Swap(ctx, "a", []byte("1"), []byte("2"), time.Hour)
+		assert.Nil(t, err)
+		assert.True(t, ok)
+
+		ok, err = c.Swap(ctx, "a", []byte("1"), []byte("3"), time.Hour)
+		assert.Nil(t, err)
+		assert.False(t, ok)
+
+		value, _ := c.Get(ctx, "a")
+		assert.Equal(t, []byte("2"), value)
+
+		ttl, _ := c.TTL(ctx, "a")
+		assert.InDelta(t, time.Hour, ttl, float64(time.Second))
+	})
+
+	t.Run("Pop", func(t *testing.T) {
+		c := newCache(t)
+
+		_ = c.Set(ctx, "a", []byte("1"), time.Minute)
+
+		value, err := c.Pop(ctx, "a")
+		assert.Nil(t, err)
+		assert.Equal(t, []byte("1"), value)
+
+		_, err = c.Pop(ctx, "a")
+		assert.Equal(t, ErrNotFound, err)
+	})
+
+	t.Run("Delete", func(t *testing.T) {
+		c := newCache(t)
+
+		_ = c.Set(ctx, "a", []byte("1"), time.Minute)
+		_ = c.Set(ctx, "b", []byte("1"), time.Minute)
+
+		err := c.Delete(ctx, "a", "b", "c")
+		assert.Nil(t, err)
+
+		_, err = c.Get(ctx, "a")
+		assert.Equal(t, ErrNotFound, err)
+		_, err = c.Get(ctx, "b")
+		assert.Equal(t, ErrNotFound, err)
+	})
+
+	t.Run("Tags", func(t *testing.T) {
+		c := newCache(t)
+
+		_ = c.Set(ctx, "a", []byte("1"), time.Minute, "x")
+		_ = c.Set(ctx, "b", []byte("1"), time.Minute, "x", "y")
+		_ = c.Set(ctx, "c", []byte("1"), time.Minute, "y")
+		_ = c.Delete(ctx, "a")
+
+		keys, err := c.Keys(ctx, "x")
+		assert.Nil(t, err)
+		assert.Equal(t, []string{"b"}, keys)
+
+		err = c.Invalidate(ctx, "y")
+		assert.Nil(t, err)
+
+		for _, key := range []string{"b", "c"} {
+			_, err = c.Get(ctx, key)
+			assert.Equal(t, ErrNotFound, err)
+		}
+
+		keys, err = c.Keys(ctx, "y")
+		assert.Nil(t, err)
+		assert.Empty(t, keys)
+
+		_ = c.Set(ctx, "d", []byte("1"), time.Minute, "z")
+		_, _ = c.Swap(ctx, "d", []byte("1"), []byte("2"), time.Minute)
+		_ = c.Set(ctx, "e", []byte("1"), time.Minute, "z")
+
+		keys, err = c.Keys(ctx, "z")
+		assert.Nil(t, err)
+		sort.Strings(keys)
+		assert.Equal(t, []string{"d", "e"}, keys, "tags survive overwrites")
+	})
+}
+
+func TestRedis(t *testing.T) {
+	testCache(t, func(t *testing.T) Cache {
+		return NewMock(t)
+	})
+}
+
+func TestMemory(t *testing.T) {
+	testCache(t, func(t *testing.T) Cache {
+		return NewMemory(100, 1<<20)
+	})
+}
+
+func TestNew(t *testing.T) {
+	s := miniredis.RunT(t)
+
+	client, err := New(config.Cache{Mode: "standalone", Address: []string{s.Addr()}, DialTimeout: time.Second})
+	assert.Nil(t, err)
+	assert.Nil(t, client.Ping(context.TODO()).Err())
+
+	_, ok := client.(*redis.Client)
+	assert.True(t, ok)
+
+	client, err = New(config.Cache{Mode: "sentinel", MasterName: "main", Address: []string{s.Addr()}})
+	assert.Nil(t, err)
+	_, ok = client.(*redis.Client)
+	assert.True(t, ok, "sentinel clients fail over to the current master")
+
+	client, err = New(config.Cache{Mode: "cluster", Address: []string{s.Addr()}})
+	assert.Nil(t, err)
+	_, ok = client.(*redis.ClusterClient)
+	assert.True(t, ok, "a single seed node is enough for a cluster")
+
+	_, err = New(config.Cache{Mode: "standalone", Address: []string{"a:6379", "b:6379"}})
+	assert.NotNil(t, err)
+
+	_, err = New(config.Cache{Mode: "sentinel", Address: []string{s.Addr()}})
+	assert.NotNil(t, err, "sentinel needs a master name")
+
+	_, err = New(config.Cache{Mode: "cluster", Address: []string{s.Addr()}, DB: 1})
+	assert.NotNil(t, err)
+
+	_, err = New(config.Cache{Mode: "replicated", Address: []string{s.Addr()}})
+	assert.NotNil(t, err)
+
+	_, err = New(config.Cache{Mode: "standalone", Address: []string{s.Addr()}, TLS: true, TLSCACert: "missing.pem"})
+	assert.NotNil(t, err)
+}
+
+func TestNew_TLS(t *testing.T) {
+	s := miniredis.NewMiniRedis()
+	server := httptest.NewUnstartedServer(nil)
+	server.StartTLS()
+	defer server.Close()
+
+	cert := server.Certificate()
+	ca := filepath.Join(t.TempDir(), "ca.pem")
+	err := os.WriteFile(ca, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: cert.Raw}), 0o600)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = s.StartTLS(server.TLS)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+
+	client, err := New(config.Cache{
+		Mode:          "standalone",
+		Address:       []string{s.Addr()},
+		TLS:           true,
+		TLSCACert:     ca,
+		TLSServerName: "example.com",
+		DialTimeout:   time.Second,
+	})
+	assert.Nil(t, err)
+	assert.Nil(t, client.Ping(context.TODO()).Err())
+}
@@ -0,0 +1,247 @@
+package cache
+
+import (
+	"container/list"
+	"context"
+	"sync"
+	"time"
+)
+
+type entry struct {
+	key       string
+	value     []byte
+	expiresAt time.Time
+	tags      map[string]struct{}
+}
+
+func (e *entry) size() int64 {
+	return int64(len(e.key) + len(e.value))
+}
+
+func (e *entry) expired(now time.Time) bool {
+	return !e.expiresAt.IsZero() && !now.Before(e.expiresAt)
+}
+
+// Memory is an in-process Cache bounded by a number of entries and a number
+// of bytes of keys and values, evicting the least recently used entries
+// first. It is meant for a single replica or local development: nothing is
+// shared between processes.
+type Memory struct {
+	maxEntries int
+	maxBytes   int64
+	now        func() time.Time
+
+	mu      sync.Mutex
+	bytes   int64
+	lru     *list.List
+	entries map[string]*list.Element
+	tags    map[string]map[string]struct{}
+}
+
+// NewMemory returns a Memory cache. A limit of zero or less disables it.
+func NewMemory(maxEntries int, maxBytes int64) *Memory {
+	return &Memory{
+		maxEntries: maxEntries,
+		maxBytes:   maxBytes,
+		now:        time.Now,
+		lru:        list.New(),
+		entries:    map[string]*list.Element{},
+		tags:       map[string]map[string]struct{}{},
+	}
+}
+
+// lookup returns the live entry of key, dropping it if it expired.
+func (c *Memory) lookup(key string) *entry {
+	el, ok := c.entries[key]
+	if !ok {
+		return nil
+	}
+
+	e := el.Value.(*entry)
+	if e.expired(c.now()) {
+		c.remove(el)
+		return nil
+	}
+
+	return e
+}
+
+func (c *Memory) remove(el *list.Element) {
+	e := c.lru.Remove(el).(*entry)
+	delete(c.entries, e.key)
+	c.bytes -= e.size()
+
+	for tag := range e.tags {
+		delete(c.tags[tag], e.key)
+		if len(c.tags[tag]) == 0 {
+			delete(c.tags, tag)
+		}
+	}
+}
+
+func (c *Memory) set(key string, value []byte, ttl time.Duration, tags []string) {
+	e := &entry{
+		key:   key,
+		value: append([]byte(nil), value...),
+		tags:  map[string]struct{}{},
+	}
+
+	if ttl > 0 {
+		e.expiresAt = c.now().Add(ttl)
+	}
+
+	if previous := c.lookup(key); previous != nil {
+		if ttl == KeepTTL {
+			e.expiresAt = previous.expiresAt
+		}
+
+		for tag := range previous.tags {
+			e.tags[tag] = struct{}{}
+		}
+
+		c.remove(c.entries[key])
+	}
+
+	if c.maxBytes > 0 && e.size() > c.maxBytes {
+		return
+	}
+
+	for _, tag := range tags {
+		e.tags[tag] = struct{}{}
+	}
+
+	for tag := range e.tags {
+		if c.tags[tag] == nil {
+			c.tags[tag] = map[string]struct{}{}
+		}
+
+		c.tags[tag][key] = struct{}{}
+	}
+
+	c.entries[key] = c.lru.PushFront(e)
+	c.bytes += e.size()
+
+	for (c.maxEntries > 0 && c.lru.Len() > c.maxEntries) || (c.maxBytes > 0 && c.bytes > c.maxBytes) {
+		c.remove(c.lru.Back())
+	}
+}
+
+func (c *Memory) Get(ctx context.Context, key string) ([]byte, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	e := c.lookup(key)
+	if e == nil {
+		return nil, ErrNotFound
+	}
+
+	c.lru.MoveToFront(c.entries[key])
+
+	return append([]byte(nil), e.value...), nil
+}
+
+func (c *Memory) Set(ctx context.Context, key string, value []byte, ttl time.Duration, tags ...string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.set(key, value, ttl, tags)
+
+	return nil
+}
+
+func (c *Memory) Add(ctx context.Context, key string, value []byte, ttl time.Duration, tags ...string) (bool, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.lookup(key) != nil {
+		return false, nil
+	}
+
+	c.set(key, value, ttl, tags)
+
+	return true, nil
+}
+
+func (c *Memory) Swap(ctx context.Context, key string, old, value []byte, ttl time.Duration, tags ...string) (bool, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	e := c.lookup(key)
+	if e == nil || string(e.value) != string(old) {
+		return false, nil
+	}
+
+	c.set(key, value, ttl, tags)
+
+	return true, nil
+}
+
+func (c *Memory) Pop(ctx context.Context, key string) ([]byte, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	e := c.lookup(key)
+	if e == nil {
+		return nil, ErrNotFound
+	}
+
+	c.remove(c.entries[key])
+
+	return e.value, nil
+}
+
+func (c *Memory) Delete(ctx context.Context, keys ...string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for _, key := range keys {
+		if el, ok := c.entries[key]; ok {
+			c.remove(el)
+		}
+	}
+
+	return nil
+}
+
+func (c *Memory) TTL(ctx context.Context, key string) (time.Duration, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	e := c.lookup(key)
+	if e == nil {
+		return 0, ErrNotFound
+	}
+
+	if e.expiresAt.IsZero() {
+		return 0, nil
+	}
+
+	return e.expiresAt.Sub(c.now()), nil
+}
+
+func (c *Memory) Keys(ctx context.Context, tag string) ([]string, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	var keys []string
+	for key := range c.tags[tag] {
+		if c.lookup(key) != nil {
+			keys = append(keys, key)
+		}
+	}
+
+	return keys, nil
+}
+
+func (c *Memory) Invalidate(ctx context.Context, tags ...string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for _, tag := range tags {
+		for key := range c.tags[tag] {
+			c.remove(c.entries[key])
+		}
+	}
+
+	return nil
+}
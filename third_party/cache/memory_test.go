@@ -0,0 +1,65 @@
+package cache
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMemory_Expiration(t *testing.T) {
+	now := time.Date(2000, 1, 1, 0, 0, 0, 0, time.UTC)
+	c := NewMemory(0, 0)
+	c.now = func() time.Time { return now }
+
+	_ = c.Set(context.TODO(), "a", []byte("1"), time.Minute, "x")
+
+	now = now.Add(59 * time.Second)
+	_, err := c.Get(context.TODO(), "a")
+	assert.Nil(t, err)
+
+	now = now.Add(time.Second)
+	_, err = c.Get(context.TODO(), "a")
+	assert.Equal(t, ErrNotFound, err)
+
+	keys, _ := c.Keys(context.TODO(), "x")
+	assert.Empty(t, keys)
+	assert.Empty(t, c.tags, "expired keys leave their tags")
+}
+
+func TestMemory_EvictsLeastRecentlyUsed(t *testing.T) {
+	c := NewMemory(2, 0)
+
+	_ = c.Set(context.TODO(), "a", []byte("1"), 0)
+	_ = c.Set(context.TODO(), "b", []byte("1"), 0)
+	_, _ = c.Get(context.TODO(), "a")
+	_ = c.Set(context.TODO(), "c", []byte("1"), 0, "x")
+
+	_, err := c.Get(context.TODO(), "b")
+	assert.Equal(t, ErrNotFound, err)
+
+	for _, key := range []string{"a", "c"} {
+		_, err = c.Get(context.TODO(), key)
+		assert.Nil(t, err)
+	}
+}
+
+func TestMemory_BoundedBytes(t *testing.T) {
+	c := NewMemory(0, 10)
+
+	_ = c.Set(context.TODO(), "a", []byte("1234"), 0)
+	_ = c.Set(context.TODO(), "b", []byte("1234"), 0)
+	assert.Equal(t, int64(10), c.bytes)
+
+	_ = c.Set(context.TODO(), "c", []byte("1"), 0)
+	assert.Equal(t, int64(7), c.bytes)
+
+	_, err := c.Get(context.TODO(), "a")
+	assert.Equal(t, ErrNotFound, err)
+
+	_ = c.Set(context.TODO(), "d", []byte("too large to fit"), 0)
+	_, err = c.Get(context.TODO(), "d")
+	assert.Equal(t, ErrNotFound, err)
+	assert.Equal(t, 2, c.lru.Len())
+}
@@ -0,0 +1,233 @@
+package cache
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// Redis is the Cache shared by every API replica. Tags are kept as sets of
+// keys, pruned of expired keys as they are read.
+type Redis struct {
+	client redis.UniversalClient
+}
+
+func NewRedis(client redis.UniversalClient) *Redis {
+	return &Redis{
+		client: client,
+	}
+}
+
+func tagKey(tag string) string {
+	return fmt.Sprintf("tag:%s", tag)
+}
+
+func (c *Redis) tag(ctx context.Context, pipe redis.Pipeliner, key string, ttl time.Duration, tags []string) {
+	for _, tag := range tags {
+		pipe.SAdd(ctx, tagKey(tag), key)
+
+		switch {
+		case ttl > 0:
+			pipe.Expire(ctx, tagKey(tag), ttl)
+		case ttl == 0:
+			pipe.Persist(ctx, tagKey(tag))
+		}
+	}
+}
+
+func (c *Redis) Get(ctx context.Context, key string) ([]byte, error) {
+	value, err := c.client.Get(ctx, key).Bytes()
+	if err == redis.Nil {
+		return nil, ErrNotFound
+	}
+
+	return value, err
+}
+
+func (c *Redis) Set(ctx context.Context, key string, value []byte, ttl time.Duration, tags ...string) error {
+	_, err := c.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.Set(ctx, key, value, ttl)
+		c.tag(ctx, pipe, key, ttl, tags)
+		return nil
+	})
+
+	return err
+}
+
+func (c *Redis) Add(ctx context.Context, key string, value []byte, ttl time.Duration, tags ...string) (bool, error) {
+	ok, err := c.client.SetNX(ctx, key, value, ttl).Result()
+	if err != nil || !ok || len(tags) == 0 {
+		return ok, err
+	}
+
+	_, err = c.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		c.tag(ctx, pipe, key, ttl, tags)
+		return nil
+	})
+
+	return true, err
+}
+
+// Swap tags key after the swap rather than within it, since a transaction
+// watching key can only touch keys of its cluster slot.
+func (c *Redis) Swap(ctx context.Context, key string, old, value []byte, ttl time.Duration, tags ...string) (bool, error) {
+	swapped := false
+	err := c.client.Watch(ctx, func(tx *redis.Tx) error {
+		stored, err := tx.Get(ctx, key).Bytes()
+		if err != nil {
+			if err == redis.Nil {
+				return nil
+			}
+
+			return err
+		}
+
+		if string(stored) != string(old) {
+			return nil
+		}
+
+		_, err = tx.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+			pipe.Set(ctx, key, value, ttl)
+			return nil
+		})
+		if err == redis.TxFailedErr {
+			return nil
+		}
+
+		swapped = err == nil
+
+		return err
+	}, key)
+	if err != nil || !swapped || len(tags) == 0 {
+		return swapped, err
+	}
+
+	if ttl == KeepTTL {
+		ttl, err = c.TTL(ctx, key)
+		if err == ErrNotFound {
+			return true, nil
+		}
+
+		if err != nil {
+			return true, err
+		}
+	}
+
+	_, err = c.client.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+		c.tag(ctx, pipe, key, ttl, tags)
+		return nil
+	})
+
+	return true, err
+}
+
+func (c *Redis) Pop(ctx context.Context, key string) ([]byte, error) {
+	value, err := c.client.GetDel(ctx, key).Bytes()
+	if err == redis.Nil {
+		return nil, ErrNotFound
+	}
+
+	return value, err
+}
+
+// Delete deletes the keys one by one, since in a cluster they may live in
+// different slots.
+func (c *Redis) Delete(ctx context.Context, keys ...string) error {
+	if len(keys) == 0 {
+		return nil
+	}
+
+	_, err := c.client.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+		for _, key := range keys {
+			pipe.Del(ctx, key)
+		}
+		return nil
+	})
+
+	return err
+}
+
+func (c *Redis) TTL(ctx context.Context, key string) (time.Duration, error) {
+	ttl, err := c.client.PTTL(ctx, key).Result()
+	if err != nil {
+		return 0, err
+	}
+
+	switch ttl {
+	case -2:
+		return 0, ErrNotFound
+	case -1:
+		return 0, nil
+	}
+
+	return ttl, nil
+}
+
+func (c *Redis) Keys(ctx context.Context, tag string) ([]string, error) {
+	members, err := c.client.SMembers(ctx, tagKey(tag)).Result()
+	if err != nil || len(members) == 0 {
+		return nil, err
+	}
+
+	cmds, err := c.client.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+		for _, key := range members {
+			pipe.Exists(ctx, key)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var keys []string
+	var expired []any
+	for i, cmd := range cmds {
+		if cmd.(*redis.IntCmd).Val() == 0 {
+			expired = append(expired, members[i])
+			continue
+		}
+
+		keys = append(keys, members[i])
+	}
+
+	if len(expired) != 0 {
+		err = c.client.SRem(ctx, tagKey(tag), expired...).Err()
+	}
+
+	return keys, err
+}
+
+// Invalidate deletes the keys of each tag. Only the members it saw are
+// removed from the tag, so keys tagged concurrently are not lost.
+func (c *Redis) Invalidate(ctx context.Context, tags ...string) error {
+	for _, tag := range tags {
+		members, err := c.client.SMembers(ctx, tagKey(tag)).Result()
+		if err != nil {
+			return err
+		}
+
+		if len(members) == 0 {
+			continue
+		}
+
+		values := make([]any, len(members))
+		for i, key := range members {
+			values[i] = key
+		}
+
+		_, err = c.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+			for _, key := range members {
+				pipe.Del(ctx, key)
+			}
+			pipe.SRem(ctx, tagKey(tag), values...)
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,204 @@
+package database
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+)
+
+// Dialect covers where the supported databases disagree on SQL. Queries are
+// written with ? placeholders and unquoted identifiers, in the subset of SQL
+// all of them share, and Rebind adapts them right before they run.
+type Dialect interface {
+	// Name is the name of the migrations directory of the dialect.
+	Name() string
+	// Rebind turns the ? placeholders of query into the ones the database
+	// expects.
+	Rebind(query string) string
+	// Quote quotes ident as an identifier.
+	Quote(ident string) string
+	// Returning reports whether INSERT takes a RETURNING clause. When it
+	// does not, the ID of an inserted row comes from LastInsertId.
+	Returning() bool
+	// Upsert returns the clause that, appended to an INSERT, overwrites the
+	// update columns of the row conflicting on the conflict columns with the
+	// ones inserted and applies the extra assignments, such as touching a
+	// timestamp, as they are. It leaves the row alone when there is nothing
+	// to set.
+	Upsert(conflict, update []string, extra ...string) string
+	// Explain returns the statement showing the plan of query.
+	Explain(query string) string
+	// DateTrunc returns the expression truncating the timestamp expr to the
+	// day or, with the unit week, to the Monday starting its week, as text
+	// in the form 2006-01-02.
+	DateTrunc(unit, expr string) string
+	// In returns the condition matching expr against any value of list, a
+	// slice, and the arguments binding it. Postgres binds list as one array;
+	// the others, without arrays, get a placeholder per value.
+	In(expr string, list any) (string, []any)
+	// Bind adapts the values written to columns to the database: Postgres
+	// takes slices of strings as arrays. The others have no arrays, so their
+	// drivers reject such values rather than store them as text.
+	Bind(args []any) []any
+}
+
+type dialect struct {
+	name      string
+	bind      int
+	quote     string
+	returning bool
+	arrays    bool
+}
+
+var (
+	Postgres Dialect = dialect{name: "postgres", bind: sqlx.DOLLAR, quote: `"`, returning: true, arrays: true}
+	SQLite   Dialect = dialect{name: "sqlite", bind: sqlx.QUESTION, quote: `"`, returning: true}
+	MySQL    Dialect = dialect{name: "mysql", bind: sqlx.QUESTION, quote: "`", returning: false}
+)
+
+var errUnknownDriver = errors.New("must choose a database driver")
+
+// DialectFor returns the dialect spoken through driver.
+func DialectFor(driver string) (Dialect, error) {
+	switch driver {
+	case "postgres", "pgx":
+		return Postgres, nil
+	case "sqlite3":
+		return SQLite, nil
+	case "mysql":
+		return MySQL, nil
+	default:
+		return nil, errUnknownDriver
+	}
+}
+
+// DialectOf returns the dialect db speaks. Databases opened through any other
+// driver, such as the mock, are taken for Postgres.
+func DialectOf(db *sqlx.DB) Dialect {
+	d, err := DialectFor(db.DriverName())
+	if err != nil {
+		return Postgres
+	}
+
+	return d
+}
+
+func (d dialect) Name() string {
+	return d.name
+}
+
+func (d dialect) Rebind(query string) string {
+	return sqlx.Rebind(d.bind, query)
+}
+
+func (d dialect) Quote(ident string) string {
+	return d.quote + strings.ReplaceAll(ident, d.quote, d.quote+d.quote) + d.quote
+}
+
+func (d dialect) Returning() bool {
+	return d.returning
+}
+
+func (d dialect) Upsert(conflict, update []string, extra ...string) string {
+	quoted := func(idents []string) []string {
+		qs := make([]string, len(idents))
+		for i, ident := range idents {
+			qs[i] = d.Quote(ident)
+		}
+
+		return qs
+	}
+
+	set := make([]string, len(update))
+	for i, column := range quoted(update) {
+		if d.name == "mysql" {
+			set[i] = fmt.Sprintf("%s = VALUES(%s)", column, column)
+		} else {
+			set[i] = fmt.Sprintf("%s = EXCLUDED.%s", column, column)
+		}
+	}
+	set = append(set, extra...)
+
+	if d.name == "mysql" {
+		// MySQL has no DO NOTHING; assigning a key column to itself changes
+		// nothing.
+		if len(set) == 0 {
+			column := d.Quote(conflict[0])
+			set = []string{column + " = " + column}
+		}
+
+		return "ON DUPLICATE KEY UPDATE " + strings.Join(set, ", ")
+	}
+
+	target := strings.Join(quoted(conflict), ", ")
+	if len(set) == 0 {
+		return fmt.Sprintf("ON CONFLICT (%s) DO NOTHING", target)
+	}
+
+	return fmt.Sprintf("ON CONFLICT (%s) DO UPDATE SET %s", target, strings.Join(set, ", "))
+}
+
+func (d dialect) Explain(query string) string {
+	if d.name == "sqlite" {
+		return "EXPLAIN QUERY PLAN " + query
+	}
+
+	return "EXPLAIN " + query
+}
+
+func (d dialect) DateTrunc(unit, expr string) string {
+	week := unit == "week"
+
+	switch d.name {
+	case "sqlite":
+		if week {
+			return fmt.Sprintf("date(%s, 'weekday 0', '-6 days')", expr)
+		}
+
+		return fmt.Sprintf("date(%s)", expr)
+	case "mysql":
+		if week {
+			expr = fmt.Sprintf("DATE_SUB(%s, INTERVAL WEEKDAY(%s) DAY)", expr, expr)
+		}
+
+		return fmt.Sprintf("DATE_FORMAT(%s, '%%Y-%%m-%%d')", expr)
+	default:
+		return fmt.Sprintf("to_char(date_trunc('%s', %s), 'YYYY-MM-DD')", unit, expr)
+	}
+}
+
+func (d dialect) In(expr string, list any) (string, []any) {
+	if d.arrays {
+		return expr + " = ANY(?)", []any{pq.Array(list)}
+	}
+
+	v := reflect.ValueOf(list)
+	if v.Len() == 0 {
+		return "1 = 0", nil
+	}
+
+	args := make([]any, v.Len())
+	for i := range args {
+		args[i] = v.Index(i).Interface()
+	}
+
+	return fmt.Sprintf("%s IN (%s)", expr, strings.TrimSuffix(strings.Repeat("?, ", len(args)), ", ")), args
+}
+
+func (d dialect) Bind(args []any) []any {
+	if !d.arrays {
+		return args
+	}
+
+	for i, arg := range args {
+		if v, ok := arg.([]string); ok {
+			args[i] = pq.StringArray(v)
+		}
+	}
+
+	return args
+}
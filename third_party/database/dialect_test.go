@@ -0,0 +1,133 @@
+package database
+
+import (
+	"testing"
+
+	"github.com/henriqueassiss/advanced-golang-api/config"
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDialectFor(t *testing.T) {
+	for driver, want := range map[string]Dialect{"pgx": Postgres, "postgres": Postgres, "sqlite3": SQLite, "mysql": MySQL} {
+		got, err := DialectFor(driver)
+		assert.Nil(t, err)
+		assert.Equal(t, want, got, driver)
+	}
+
+	_, err := DialectFor("oracle")
+	assert.Equal(t, errUnknownDriver, err)
+}
+
+func TestDialect(t *testing.T) {
+	type want struct {
+		rebind   string
+		ident    string
+		quote    string
+		upsert   string
+		nothing  string
+		explain  string
+		week     string
+		returned bool
+		in       string
+		inArgs   []any
+		bound    any
+	}
+
+	tests := map[Dialect]want{
+		Postgres: {
+			rebind:   "SELECT 1 FROM t WHERE a = $1 AND b = $2",
+			ident:    `or"der`,
+			quote:    `"or""der"`,
+			upsert:   `ON CONFLICT ("a", "b") DO UPDATE SET "c" = EXCLUDED."c", updated_at = CURRENT_TIMESTAMP`,
+			nothing:  `ON CONFLICT ("a") DO NOTHING`,
+			explain:  "EXPLAIN SELECT 1",
+			week:     "to_char(date_trunc('week', t.created_at), 'YYYY-MM-DD')",
+			returned: true,
+			in:       "l.name = ANY(?)",
+			inArgs:   []any{pq.Array([]string{"a", "b"})},
+			bound:    pq.StringArray{"a", "b"},
+		},
+		SQLite: {
+			rebind:   "SELECT 1 FROM t WHERE a = ? AND b = ?",
+			ident:    `or"der`,
+			quote:    `"or""der"`,
+			upsert:   `ON CONFLICT ("a", "b") DO UPDATE SET "c" = EXCLUDED."c", updated_at = CURRENT_TIMESTAMP`,
+			nothing:  `ON CONFLICT ("a") DO NOTHING`,
+			explain:  "EXPLAIN QUERY PLAN SELECT 1",
+			week:     "date(t.created_at, 'weekday 0', '-6 days')",
+			returned: true,
+			in:       "l.name IN (?, ?)",
+			inArgs:   []any{"a", "b"},
+			bound:    []string{"a", "b"},
+		},
+		MySQL: {
+			rebind:   "SELECT 1 FROM t WHERE a = ? AND b = ?",
+			ident:    "or`der",
+			quote:    "`or``der`",
+			upsert:   "ON DUPLICATE KEY UPDATE `c` = VALUES(`c`), updated_at = CURRENT_TIMESTAMP",
+			nothing:  "ON DUPLICATE KEY UPDATE `a` = `a`",
+			explain:  "EXPLAIN SELECT 1",
+			week:     "DATE_FORMAT(DATE_SUB(t.created_at, INTERVAL WEEKDAY(t.created_at) DAY), '%Y-%m-%d')",
+			returned: false,
+			in:       "l.name IN (?, ?)",
+			inArgs:   []any{"a", "b"},
+			bound:    []string{"a", "b"},
+		},
+	}
+
+	for d, want := range tests {
+		t.Run(d.Name(), func(t *testing.T) {
+			assert.Equal(t, want.rebind, d.Rebind("SELECT 1 FROM t WHERE a = ? AND b = ?"))
+			assert.Equal(t, want.quote, d.Quote(want.ident))
+			assert.Equal(t, want.upsert, d.Upsert([]string{"a", "b"}, []string{"c"}, "updated_at = CURRENT_TIMESTAMP"))
+			assert.Equal(t, want.nothing, d.Upsert([]string{"a"}, nil))
+			assert.Equal(t, want.explain, d.Explain("SELECT 1"))
+			assert.Equal(t, want.week, d.DateTrunc("week", "t.created_at"))
+			assert.Equal(t, want.returned, d.Returning())
+
+			in, args := d.In("l.name", []string{"a", "b"})
+			assert.Equal(t, want.in, in)
+			assert.Equal(t, want.inArgs, args)
+			assert.Equal(t, []any{want.bound, 1}, d.Bind([]any{[]string{"a", "b"}, 1}))
+		})
+	}
+}
+
+func TestDialect_In(t *testing.T) {
+	db := NewSqlxSqlite(t)
+
+	count := func(list []string) int {
+		in, args := SQLite.In("slug", list)
+
+		var n int
+		err := db.Get(&n, "SELECT count(*) FROM workspaces WHERE "+in, args...)
+		assert.Nil(t, err)
+
+		return n
+	}
+
+	assert.Equal(t, 1, count([]string{"default", "missing"}), "lists match each of their values")
+	assert.Equal(t, 0, count(nil), "empty lists match nothing")
+}
+
+func TestDSN(t *testing.T) {
+	cfg := config.Database{Host: "db", Port: 3306, Name: "api", User: "api", Password: "secret", SslMode: "disable"}
+
+	cfg.Driver = "mysql"
+	dsn, err := DSN(cfg)
+	assert.Nil(t, err)
+	assert.Contains(t, dsn, "api:secret@tcp(db:3306)/api?")
+	assert.Contains(t, dsn, "clientFoundRows=true")
+	assert.Contains(t, dsn, "parseTime=true")
+	assert.Contains(t, dsn, "tls=false")
+
+	cfg.Driver, cfg.Name = "sqlite3", "api.db"
+	dsn, err = DSN(cfg)
+	assert.Nil(t, err)
+	assert.Equal(t, "file:api.db?_foreign_keys=on&_busy_timeout=5000", dsn)
+
+	cfg.Driver = ""
+	_, err = DSN(cfg)
+	assert.Equal(t, errUnknownDriver, err)
+}
@@ -0,0 +1,628 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"reflect"
+	"slices"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// Instrumentation watches the statements run on the databases it opens: it
+// logs the ones slower than SlowQuery, gives the ones whose context has no
+// deadline one of Timeout and counts failures by type. Arguments are never
+// logged, only their types.
+type Instrumentation struct {
+	Logger    *slog.Logger
+	SlowQuery time.Duration
+	Timeout   time.Duration
+	// Explain attaches the plan of slow reads to their log. It runs them
+	// again, so it is meant for development.
+	Explain bool
+
+	errors sync.Map
+}
+
+// errorClasses names the SQLSTATE classes worth telling apart.
+var errorClasses = map[string]string{
+	"08": "connection",
+	"22": "data",
+	"23": "constraint",
+	"40": "rollback",
+	"42": "syntax",
+	"53": "resources",
+	"57": "canceled",
+}
+
+// errorType names the kind of failure err is.
+func errorType(err error) string {
+	var state interface{ SQLState() string }
+
+	switch {
+	case errors.Is(err, context.DeadlineExceeded):
+		return "timeout"
+	case errors.Is(err, context.Canceled):
+		return "canceled"
+	case errors.Is(err, driver.ErrBadConn):
+		return "connection"
+	case errors.As(err, &state) && len(state.SQLState()) == 5:
+		if class, ok := errorClasses[state.SQLState()[:2]]; ok {
+			return class
+		}
+	}
+
+	return "other"
+}
+
+// Errors returns how many statements failed, by type of failure.
+func (i *Instrumentation) Errors() map[string]uint64 {
+	counts := map[string]uint64{}
+	i.errors.Range(func(k, v any) bool {
+		counts[k.(string)] = v.(*atomic.Uint64).Load()
+		return true
+	})
+
+	return counts
+}
+
+// Report logs the failure counts every interval until ctx is done, when
+// any statement failed.
+func (i *Instrumentation) Report(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			counts := i.Errors()
+			if len(counts) == 0 {
+				continue
+			}
+
+			kinds := make([]string, 0, len(counts))
+			for kind := range counts {
+				kinds = append(kinds, kind)
+			}
+			slices.Sort(kinds)
+
+			attrs := make([]any, 0, 2*len(kinds))
+			for _, kind := range kinds {
+				attrs = append(attrs, kind, counts[kind])
+			}
+
+			i.Logger.Info("Query errors", attrs...)
+		}
+	}
+}
+
+// fail counts err, the failure of a statement run with ctx. Drivers do not
+// all report running out of time the same way, so ctx tells instead.
+func (i *Instrumentation) fail(ctx context.Context, err error) {
+	if err == nil || err == driver.ErrSkip || err == io.EOF {
+		return
+	}
+
+	kind := errorType(err)
+	if ctx.Err() != nil {
+		kind = errorType(ctx.Err())
+	}
+
+	v, _ := i.errors.LoadOrStore(kind, &atomic.Uint64{})
+	count := v.(*atomic.Uint64).Add(1)
+
+	i.Logger.Debug("Query failed: "+err.Error(), "type", kind, "count", count)
+}
+
+// Open opens a database through driverName whose statements are watched.
+func (i *Instrumentation) Open(driverName, dsn string) (*sqlx.DB, error) {
+	d, err := DialectFor(driverName)
+	if err != nil {
+		return nil, err
+	}
+
+	// Opening does not connect; it only looks the driver up.
+	probe, err := sql.Open(driverName, dsn)
+	if err != nil {
+		return nil, err
+	}
+	drv := probe.Driver()
+	probe.Close()
+
+	var c driver.Connector = dsnConnector{dsn: dsn, driver: drv}
+	if dc, ok := drv.(driver.DriverContext); ok {
+		c, err = dc.OpenConnector(dsn)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return sqlx.NewDb(sql.OpenDB(&connector{Connector: c, i: i, dialect: d}), driverName), nil
+}
+
+// timeout gives ctx the default deadline when it has none.
+func (i *Instrumentation) timeout(ctx context.Context) (context.Context, context.CancelFunc) {
+	if _, ok := ctx.Deadline(); ok || i.Timeout <= 0 {
+		return ctx, func() {}
+	}
+
+	return context.WithTimeout(ctx, i.Timeout)
+}
+
+// redact returns the types of args in place of their values.
+func redact(args []driver.NamedValue) []string {
+	types := make([]string, len(args))
+	for n, arg := range args {
+		types[n] = fmt.Sprintf("%T", arg.Value)
+	}
+
+	return types
+}
+
+func isRead(query string) bool {
+	q := strings.ToUpper(strings.TrimSpace(query))
+	return strings.HasPrefix(q, "SELECT") || strings.HasPrefix(q, "WITH")
+}
+
+type dsnConnector struct {
+	dsn    string
+	driver driver.Driver
+}
+
+func (c dsnConnector) Connect(context.Context) (driver.Conn, error) {
+	return c.driver.Open(c.dsn)
+}
+
+func (c dsnConnector) Driver() driver.Driver {
+	return c.driver
+}
+
+type connector struct {
+	driver.Connector
+	i       *Instrumentation
+	dialect Dialect
+}
+
+func (c *connector) Connect(ctx context.Context) (driver.Conn, error) {
+	inner, err := c.Connector.Connect(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return &conn{Conn: inner, i: c.i, dialect: c.dialect}, nil
+}
+
+// conn instruments the statements run on a connection of the driver.
+type conn struct {
+	driver.Conn
+	i       *Instrumentation
+	dialect Dialect
+}
+
+// InstrumentationOf returns what watches driverConn, the connection
+// sql.Conn.Raw hands over, or nil when nothing does.
+func InstrumentationOf(driverConn any) *Instrumentation {
+	if c, ok := driverConn.(*conn); ok {
+		return c.i
+	}
+
+	return nil
+}
+
+// Track watches a statement run on the connection of the driver past
+// database/sql, such as a COPY, described by query. It gives ctx the
+// default timeout and returns the func to call with the outcome, which
+// counts a failure and logs the statement when slow. A nil i tracks
+// nothing.
+func (i *Instrumentation) Track(ctx context.Context, query string) (context.Context, func(err error)) {
+	if i == nil {
+		return ctx, func(error) {}
+	}
+
+	ctx, cancel := i.timeout(ctx)
+	start := time.Now()
+
+	return ctx, func(err error) {
+		defer cancel()
+		i.fail(ctx, err)
+
+		elapsed := time.Since(start)
+		if i.SlowQuery > 0 && elapsed >= i.SlowQuery {
+			i.Logger.Warn("Slow query", "query", query, "duration", elapsed)
+		}
+	}
+}
+
+// Raw returns the connection of the driver, for what only it can do.
+func (c *conn) Raw() driver.Conn {
+	return c.Conn
+}
+
+// done records a statement that took since start, and explains it if it is
+// a slow read.
+func (c *conn) done(ctx context.Context, query string, args []driver.NamedValue, start time.Time, err error) {
+	c.i.fail(ctx, err)
+
+	elapsed := time.Since(start)
+	if c.i.SlowQuery <= 0 || elapsed < c.i.SlowQuery {
+		return
+	}
+
+	attrs := []any{"query", query, "args", redact(args), "duration", elapsed}
+	if err == nil && c.i.Explain && isRead(query) {
+		attrs = append(attrs, "plan", c.explain(query, args))
+	}
+
+	c.i.Logger.Warn("Slow query", attrs...)
+}
+
+func (c *conn) explain(query string, args []driver.NamedValue) string {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	rows, err := c.query(ctx, c.dialect.Explain(query), args)
+	if err != nil {
+		return err.Error()
+	}
+	defer rows.Close()
+
+	var lines []string
+	values := make([]driver.Value, len(rows.Columns()))
+	for rows.Next(values) == nil {
+		line := make([]string, len(values))
+		for n, v := range values {
+			if b, ok := v.([]byte); ok {
+				v = string(b)
+			}
+			line[n] = fmt.Sprint(v)
+		}
+		lines = append(lines, strings.Join(line, " "))
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+// query runs query on the connection of the driver, preparing it when the
+// driver cannot run it directly.
+func (c *conn) query(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	if q, ok := c.Conn.(driver.QueryerContext); ok {
+		rows, err := q.QueryContext(ctx, query, args)
+		if err != driver.ErrSkip {
+			return rows, err
+		}
+	}
+
+	s, err := c.prepare(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := stmtQuery(ctx, s, args)
+	if err != nil {
+		s.Close()
+		return nil, err
+	}
+
+	return &closingRows{Rows: rows, close: s.Close}, nil
+}
+
+func (c *conn) prepare(ctx context.Context, query string) (driver.Stmt, error) {
+	if p, ok := c.Conn.(driver.ConnPrepareContext); ok {
+		return p.PrepareContext(ctx, query)
+	}
+
+	return c.Conn.Prepare(query)
+}
+
+func (c *conn) ExecContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	e, ok := c.Conn.(driver.ExecerContext)
+	if !ok {
+		return nil, driver.ErrSkip
+	}
+
+	ctx, cancel := c.i.timeout(ctx)
+	defer cancel()
+
+	start := time.Now()
+	result, err := e.ExecContext(ctx, query, args)
+	if err != driver.ErrSkip {
+		c.done(ctx, query, args, start, err)
+	}
+
+	return result, err
+}
+
+func (c *conn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	q, ok := c.Conn.(driver.QueryerContext)
+	if !ok {
+		return nil, driver.ErrSkip
+	}
+
+	ctx, cancel := c.i.timeout(ctx)
+
+	start := time.Now()
+	rows, err := q.QueryContext(ctx, query, args)
+	if err != nil {
+		if err != driver.ErrSkip {
+			c.done(ctx, query, args, start, err)
+		}
+		cancel()
+
+		return nil, err
+	}
+
+	return &instrumentedRows{columnRows: columnRows{rows}, conn: c, ctx: ctx, query: query, args: args, start: start, cancel: cancel}, nil
+}
+
+func (c *conn) PrepareContext(ctx context.Context, query string) (driver.Stmt, error) {
+	s, err := c.prepare(ctx, query)
+	if err != nil {
+		c.i.fail(ctx, err)
+		return nil, err
+	}
+
+	return &stmt{Stmt: s, conn: c, query: query}, nil
+}
+
+func (c *conn) Prepare(query string) (driver.Stmt, error) {
+	return c.PrepareContext(context.Background(), query)
+}
+
+func (c *conn) BeginTx(ctx context.Context, opts driver.TxOptions) (driver.Tx, error) {
+	if b, ok := c.Conn.(driver.ConnBeginTx); ok {
+		return b.BeginTx(ctx, opts)
+	}
+
+	return c.Conn.Begin()
+}
+
+func (c *conn) Ping(ctx context.Context) error {
+	if p, ok := c.Conn.(driver.Pinger); ok {
+		return p.Ping(ctx)
+	}
+
+	return nil
+}
+
+func (c *conn) ResetSession(ctx context.Context) error {
+	if r, ok := c.Conn.(driver.SessionResetter); ok {
+		return r.ResetSession(ctx)
+	}
+
+	return nil
+}
+
+func (c *conn) IsValid() bool {
+	if v, ok := c.Conn.(driver.Validator); ok {
+		return v.IsValid()
+	}
+
+	return true
+}
+
+func (c *conn) CheckNamedValue(nv *driver.NamedValue) error {
+	if n, ok := c.Conn.(driver.NamedValueChecker); ok {
+		return n.CheckNamedValue(nv)
+	}
+
+	return driver.ErrSkip
+}
+
+// stmt instruments a statement prepared on a conn.
+type stmt struct {
+	driver.Stmt
+	conn  *conn
+	query string
+}
+
+func (s *stmt) ExecContext(ctx context.Context, args []driver.NamedValue) (driver.Result, error) {
+	ctx, cancel := s.conn.i.timeout(ctx)
+	defer cancel()
+
+	start := time.Now()
+	result, err := stmtExec(ctx, s.Stmt, args)
+	s.conn.done(ctx, s.query, args, start, err)
+
+	return result, err
+}
+
+func (s *stmt) QueryContext(ctx context.Context, args []driver.NamedValue) (driver.Rows, error) {
+	ctx, cancel := s.conn.i.timeout(ctx)
+
+	start := time.Now()
+	rows, err := stmtQuery(ctx, s.Stmt, args)
+	if err != nil {
+		s.conn.done(ctx, s.query, args, start, err)
+		cancel()
+
+		return nil, err
+	}
+
+	return &instrumentedRows{columnRows: columnRows{rows}, conn: s.conn, ctx: ctx, query: s.query, args: args, start: start, cancel: cancel}, nil
+}
+
+// stmtExec runs s with the context when it takes one, and with the legacy
+// Exec otherwise, as database/sql does.
+func stmtExec(ctx context.Context, s driver.Stmt, args []driver.NamedValue) (driver.Result, error) {
+	if e, ok := s.(driver.StmtExecContext); ok {
+		return e.ExecContext(ctx, args)
+	}
+
+	values, err := legacyValues(args)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	return s.Exec(values)
+}
+
+// stmtQuery runs s with the context when it takes one, and with the legacy
+// Query otherwise, as database/sql does.
+func stmtQuery(ctx context.Context, s driver.Stmt, args []driver.NamedValue) (driver.Rows, error) {
+	if q, ok := s.(driver.StmtQueryContext); ok {
+		return q.QueryContext(ctx, args)
+	}
+
+	values, err := legacyValues(args)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	return s.Query(values)
+}
+
+// legacyValues turns args into the positional values of the legacy
+// statement methods, which cannot take named ones.
+func legacyValues(args []driver.NamedValue) ([]driver.Value, error) {
+	values := make([]driver.Value, len(args))
+	for n, arg := range args {
+		if arg.Name != "" {
+			return nil, errors.New("database: driver does not support the use of Named Parameters")
+		}
+
+		values[n] = arg.Value
+	}
+
+	return values, nil
+}
+
+// CheckNamedValue converts arguments the way the statement, or else its
+// connection, would.
+func (s *stmt) CheckNamedValue(nv *driver.NamedValue) error {
+	if n, ok := s.Stmt.(driver.NamedValueChecker); ok {
+		return n.CheckNamedValue(nv)
+	}
+
+	err := s.conn.CheckNamedValue(nv)
+	if err != driver.ErrSkip {
+		return err
+	}
+
+	if cc, ok := s.Stmt.(driver.ColumnConverter); ok {
+		nv.Value, err = cc.ColumnConverter(nv.Ordinal - 1).ConvertValue(nv.Value)
+		return err
+	}
+
+	return driver.ErrSkip
+}
+
+// columnRows passes the column types a driver knows on, and answers as
+// database/sql does for a driver that knows none.
+type columnRows struct {
+	driver.Rows
+}
+
+func (r columnRows) ColumnTypeScanType(index int) reflect.Type {
+	if t, ok := r.Rows.(driver.RowsColumnTypeScanType); ok {
+		return t.ColumnTypeScanType(index)
+	}
+
+	return reflect.TypeFor[any]()
+}
+
+func (r columnRows) ColumnTypeDatabaseTypeName(index int) string {
+	if t, ok := r.Rows.(driver.RowsColumnTypeDatabaseTypeName); ok {
+		return t.ColumnTypeDatabaseTypeName(index)
+	}
+
+	return ""
+}
+
+func (r columnRows) ColumnTypeLength(index int) (int64, bool) {
+	if t, ok := r.Rows.(driver.RowsColumnTypeLength); ok {
+		return t.ColumnTypeLength(index)
+	}
+
+	return 0, false
+}
+
+func (r columnRows) ColumnTypeNullable(index int) (bool, bool) {
+	if t, ok := r.Rows.(driver.RowsColumnTypeNullable); ok {
+		return t.ColumnTypeNullable(index)
+	}
+
+	return false, false
+}
+
+func (r columnRows) ColumnTypePrecisionScale(index int) (int64, int64, bool) {
+	if t, ok := r.Rows.(driver.RowsColumnTypePrecisionScale); ok {
+		return t.ColumnTypePrecisionScale(index)
+	}
+
+	return 0, 0, false
+}
+
+func (r columnRows) HasNextResultSet() bool {
+	if s, ok := r.Rows.(driver.RowsNextResultSet); ok {
+		return s.HasNextResultSet()
+	}
+
+	return false
+}
+
+func (r columnRows) NextResultSet() error {
+	if s, ok := r.Rows.(driver.RowsNextResultSet); ok {
+		return s.NextResultSet()
+	}
+
+	return io.EOF
+}
+
+// instrumentedRows times a query until its rows are closed, since reading
+// them is part of its cost, and only then lets go of its deadline.
+type instrumentedRows struct {
+	columnRows
+	conn   *conn
+	ctx    context.Context
+	query  string
+	args   []driver.NamedValue
+	start  time.Time
+	cancel context.CancelFunc
+	err    error
+}
+
+func (r *instrumentedRows) Next(dest []driver.Value) error {
+	err := r.Rows.Next(dest)
+	if err != nil && err != io.EOF {
+		r.err = err
+	}
+
+	return err
+}
+
+func (r *instrumentedRows) Close() error {
+	err := r.Rows.Close()
+	r.conn.done(r.ctx, r.query, r.args, r.start, r.err)
+	r.cancel()
+
+	return err
+}
+
+// closingRows closes the statement they were read through along with them.
+type closingRows struct {
+	driver.Rows
+	close func() error
+}
+
+func (r *closingRows) Close() error {
+	return errors.Join(r.Rows.Close(), r.close())
+}
@@ -0,0 +1,132 @@
+package database
+
+import (
+	"bytes"
+	"context"
+	"database/sql/driver"
+	"io"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/assert"
+)
+
+func newInstrumented(t *testing.T, i *Instrumentation) (*sqlx.DB, *bytes.Buffer) {
+	var logs bytes.Buffer
+	i.Logger = slog.New(slog.NewTextHandler(&logs, &slog.HandlerOptions{Level: slog.LevelDebug}))
+
+	db, err := i.Open("sqlite3", "file::memory:")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Every connection to :memory: opens a database of its own.
+	db.SetMaxOpenConns(1)
+	t.Cleanup(func() { db.Close() })
+
+	_, err = db.Exec("CREATE TABLE notes (id INTEGER PRIMARY KEY, body TEXT)")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return db, &logs
+}
+
+func TestInstrumentation_SlowQuery(t *testing.T) {
+	db, logs := newInstrumented(t, &Instrumentation{SlowQuery: time.Nanosecond, Explain: true})
+	logs.Reset()
+
+	var bodies []string
+	err := db.Select(&bodies, "SELECT body FROM notes WHERE body = ?", "top secret")
+	assert.Nil(t, err)
+
+	assert.Contains(t, logs.String(), `msg="Slow query" query="SELECT body FROM notes WHERE body = ?" args=[string]`)
+	assert.NotContains(t, logs.String(), "top secret", "arguments are redacted")
+	assert.Contains(t, logs.String(), "plan=", "slow reads are explained")
+	assert.Contains(t, logs.String(), "SCAN notes")
+
+	logs.Reset()
+	_, err = db.Exec("INSERT INTO notes (body) VALUES (?)", "top secret")
+	assert.Nil(t, err)
+	assert.Contains(t, logs.String(), `msg="Slow query"`)
+	assert.NotContains(t, logs.String(), "plan=", "only reads are explained")
+
+	fast, logs := newInstrumented(t, &Instrumentation{SlowQuery: time.Hour})
+	logs.Reset()
+	_, err = fast.Exec("DELETE FROM notes")
+	assert.Nil(t, err)
+	assert.Empty(t, logs.String(), "fast queries are not logged")
+}
+
+func TestInstrumentation_Errors(t *testing.T) {
+	i := &Instrumentation{Timeout: 50 * time.Millisecond}
+	db, _ := newInstrumented(t, i)
+
+	_, err := db.Exec("INSERT INTO nowhere VALUES (1)")
+	assert.NotNil(t, err)
+
+	// Counts forever, until the default timeout interrupts it.
+	var count int
+	err = db.Get(&count, "WITH RECURSIVE c(x) AS (SELECT 1 UNION ALL SELECT x + 1 FROM c) SELECT count(*) FROM c")
+	assert.NotNil(t, err)
+
+	ctx, cancel := context.WithTimeout(context.TODO(), time.Hour)
+	defer cancel()
+	err = db.GetContext(ctx, &count, "SELECT count(*) FROM notes")
+	assert.Nil(t, err, "deadlines of the caller are kept")
+
+	assert.Equal(t, map[string]uint64{"other": 1, "timeout": 1}, i.Errors())
+}
+
+// legacyStmt only has the methods drivers had before contexts.
+type legacyStmt struct {
+	args []driver.Value
+}
+
+func (s *legacyStmt) Close() error  { return nil }
+func (s *legacyStmt) NumInput() int { return -1 }
+
+func (s *legacyStmt) Exec(args []driver.Value) (driver.Result, error) {
+	s.args = args
+	return driver.RowsAffected(1), nil
+}
+
+func (s *legacyStmt) Query(args []driver.Value) (driver.Rows, error) {
+	s.args = args
+	return nil, io.EOF
+}
+
+func TestStmt_Legacy(t *testing.T) {
+	legacy := &legacyStmt{}
+	s := &stmt{Stmt: legacy, conn: &conn{i: &Instrumentation{Logger: slog.New(slog.NewTextHandler(io.Discard, nil))}}}
+	args := []driver.NamedValue{{Ordinal: 1, Value: int64(1)}}
+
+	result, err := s.ExecContext(context.TODO(), args)
+	assert.Nil(t, err)
+	affected, _ := result.RowsAffected()
+	assert.Equal(t, int64(1), affected)
+	assert.Equal(t, []driver.Value{int64(1)}, legacy.args)
+
+	_, err = s.QueryContext(context.TODO(), args)
+	assert.Equal(t, io.EOF, err)
+
+	_, err = s.ExecContext(context.TODO(), []driver.NamedValue{{Name: "id", Ordinal: 1, Value: int64(1)}})
+	assert.NotNil(t, err, "legacy statements take no named arguments")
+
+	ctx, cancel := context.WithCancel(context.TODO())
+	cancel()
+	_, err = s.ExecContext(ctx, args)
+	assert.Equal(t, context.Canceled, err)
+}
+
+func TestErrorType(t *testing.T) {
+	assert.Equal(t, "timeout", errorType(context.DeadlineExceeded))
+	assert.Equal(t, "canceled", errorType(context.Canceled))
+	assert.Equal(t, "constraint", errorType(&pgconn.PgError{Code: "23505"}))
+	assert.Equal(t, "rollback", errorType(&pgconn.PgError{Code: "40001"}))
+	assert.Equal(t, "other", errorType(&pgconn.PgError{Code: "XX000"}))
+	assert.Equal(t, "other", errorType(assert.AnError))
+}
@@ -0,0 +1,88 @@
+package database
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+)
+
+const (
+	minReconnectDelay = 100 * time.Millisecond
+	maxReconnectDelay = 30 * time.Second
+)
+
+// Listener receives the notifications of a Postgres channel on a connection
+// of its own, since LISTEN is bound to the session that issued it.
+type Listener struct {
+	dsn     string
+	channel string
+	logger  *slog.Logger
+}
+
+func NewListener(dsn, channel string, logger *slog.Logger) *Listener {
+	return &Listener{
+		dsn:     dsn,
+		channel: channel,
+		logger:  logger,
+	}
+}
+
+// Listen calls handle with the payload of each notification until ctx is
+// done. When the connection drops it reconnects with a growing delay and,
+// once listening again, calls onReconnect: notifications sent while it was
+// away are lost, so whatever they would have evicted has to be dropped some
+// other way.
+func (l *Listener) Listen(ctx context.Context, handle func(payload string), onReconnect func()) {
+	delay := minReconnectDelay
+	listened := false
+
+	for ctx.Err() == nil {
+		err := l.listen(ctx, handle, func() {
+			if listened {
+				onReconnect()
+			}
+
+			listened = true
+			delay = minReconnectDelay
+		})
+		if ctx.Err() != nil {
+			return
+		}
+
+		l.logger.Error(err.Error(), "channel", l.channel, "retry", delay)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(delay):
+		}
+
+		delay = min(delay*2, maxReconnectDelay)
+	}
+}
+
+func (l *Listener) listen(ctx context.Context, handle func(payload string), onListen func()) error {
+	conn, err := pgx.Connect(ctx, l.dsn)
+	if err != nil {
+		return err
+	}
+	defer conn.Close(context.WithoutCancel(ctx))
+
+	_, err = conn.Exec(ctx, "LISTEN "+pgx.Identifier{l.channel}.Sanitize())
+	if err != nil {
+		return err
+	}
+
+	onListen()
+
+	for {
+		n, err := conn.WaitForNotification(ctx)
+		if err != nil {
+			return err
+		}
+
+		handle(n.Payload)
+	}
+}
@@ -0,0 +1,44 @@
+package database
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// NewSqlxSqlite returns an in-memory SQLite database migrated up to the
+// latest of migrations/sqlite, for tests that need a real database.
+func NewSqlxSqlite(t *testing.T) *sqlx.DB {
+	db, err := sqlx.Open("sqlite3", "file::memory:?_foreign_keys=on")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Every connection to :memory: opens a database of its own.
+	db.SetMaxOpenConns(1)
+	t.Cleanup(func() { db.Close() })
+
+	_, file, _, _ := runtime.Caller(0)
+	ups, err := filepath.Glob(filepath.Join(filepath.Dir(file), "..", "..", "migrations", "sqlite", "*.up.sql"))
+	if err != nil || len(ups) == 0 {
+		t.Fatal("no sqlite migrations found", err)
+	}
+
+	// Glob sorts the files, so they run in the order of their versions.
+	for _, up := range ups {
+		migration, err := os.ReadFile(up)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		_, err = db.Exec(string(migration))
+		if err != nil {
+			t.Fatal(filepath.Base(up), err)
+		}
+	}
+
+	return db
+}
@@ -1,26 +1,68 @@
 package database
 
 import (
-	"errors"
+	"database/sql/driver"
 	"fmt"
+	"net"
+	"strconv"
+	"time"
 
+	"github.com/go-sql-driver/mysql"
 	"github.com/henriqueassiss/advanced-golang-api/config"
 
-	_ "github.com/jackc/pgx/v5/stdlib"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/stdlib"
 	"github.com/jmoiron/sqlx"
+	_ "github.com/mattn/go-sqlite3"
 )
 
-func NewSqlx(cfg config.Database) (db *sqlx.DB, err error) {
-	var dsn string
+// mysqlTLS maps the sslmode of Postgres to the tls parameter of MySQL.
+var mysqlTLS = map[string]string{
+	"disable":     "false",
+	"allow":       "preferred",
+	"prefer":      "preferred",
+	"require":     "skip-verify",
+	"verify-ca":   "true",
+	"verify-full": "true",
+}
+
+// DSN returns the data source name of cfg. SQLite databases are the file
+// named by cfg.Name, and need no other setting.
+func DSN(cfg config.Database) (string, error) {
 	switch cfg.Driver {
 	case "postgres", "pgx":
-		dsn = fmt.Sprintf("host=%s port=%d user=%s password=%s dbname=%s sslmode=%s TimeZone=America/Sao_Paulo",
-			cfg.Host, cfg.Port, cfg.User, cfg.Password, cfg.Name, cfg.SslMode)
+		return fmt.Sprintf("host=%s port=%d user=%s password=%s dbname=%s sslmode=%s TimeZone=America/Sao_Paulo",
+			cfg.Host, cfg.Port, cfg.User, cfg.Password, cfg.Name, cfg.SslMode), nil
+	case "sqlite3":
+		return fmt.Sprintf("file:%s?_foreign_keys=on&_busy_timeout=5000", cfg.Name), nil
+	case "mysql":
+		c := mysql.NewConfig()
+		c.User = cfg.User
+		c.Passwd = cfg.Password
+		c.Net = "tcp"
+		c.Addr = net.JoinHostPort(cfg.Host, strconv.Itoa(int(cfg.Port)))
+		c.DBName = cfg.Name
+		c.TLSConfig = mysqlTLS[cfg.SslMode]
+		c.ParseTime = true
+		c.Loc = time.Local
+		// Updates report the rows they matched, as on the other databases,
+		// rather than only the ones they changed.
+		c.ClientFoundRows = true
+
+		return c.FormatDSN(), nil
 	default:
-		return db, errors.New("must choose a database driver")
+		return "", errUnknownDriver
+	}
+}
+
+// NewSqlx opens the database of cfg, watched by i when it is not nil.
+func NewSqlx(cfg config.Database, i *Instrumentation) (db *sqlx.DB, err error) {
+	dsn, err := DSN(cfg)
+	if err != nil {
+		return db, err
 	}
 
-	db, err = sqlx.Open(cfg.Driver, dsn)
+	db, err = open(cfg.Driver, dsn, i)
 	if err != nil {
 		return db, err
 	}
@@ -29,3 +71,48 @@ func NewSqlx(cfg config.Database) (db *sqlx.DB, err error) {
 
 	return db, err
 }
+
+// NewSqlxReplicas opens the read replicas of cfg through its driver. They
+// are not pinged, since a replica being down must not keep the API from
+// starting; whoever reads from them checks their health.
+func NewSqlxReplicas(cfg config.Database, i *Instrumentation) ([]*sqlx.DB, error) {
+	replicas := make([]*sqlx.DB, 0, len(cfg.Replicas))
+	for _, dsn := range cfg.Replicas {
+		db, err := open(cfg.Driver, dsn, i)
+		if err != nil {
+			for _, opened := range replicas {
+				opened.Close()
+			}
+
+			return nil, err
+		}
+
+		replicas = append(replicas, db)
+	}
+
+	return replicas, nil
+}
+
+func open(driver, dsn string, i *Instrumentation) (*sqlx.DB, error) {
+	if i == nil {
+		return sqlx.Open(driver, dsn)
+	}
+
+	return i.Open(driver, dsn)
+}
+
+// PgxConn returns the pgx connection under driverConn, the connection
+// sql.Conn.Raw hands over, for what only pgx can do, such as COPY. It
+// reports false when driverConn is not opened through pgx.
+func PgxConn(driverConn any) (*pgx.Conn, bool) {
+	if c, ok := driverConn.(interface{ Raw() driver.Conn }); ok {
+		driverConn = c.Raw()
+	}
+
+	c, ok := driverConn.(*stdlib.Conn)
+	if !ok {
+		return nil, false
+	}
+
+	return c.Conn(), true
+}
@@ -0,0 +1,210 @@
+package oidc
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+type mockGrant struct {
+	claims      jwt.MapClaims
+	challenge   string
+	redirectURI string
+}
+
+// Mock is a local OpenID provider for tests. It serves discovery, JWKS and
+// token endpoints and signs ID tokens with an RSA key it can rotate.
+type Mock struct {
+	*httptest.Server
+	Config Config
+
+	// JWKSRequests counts fetches of the key set, to observe caching.
+	JWKSRequests atomic.Int64
+
+	mu     sync.Mutex
+	key    *rsa.PrivateKey
+	kid    string
+	grants map[string]mockGrant
+}
+
+func NewMock(t *testing.T) *Mock {
+	m := &Mock{grants: map[string]mockGrant{}}
+	m.RotateKey(t)
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/openid-configuration", m.discovery)
+	mux.HandleFunc("/jwks", m.jwks)
+	mux.HandleFunc("/token", m.token)
+
+	m.Server = httptest.NewServer(mux)
+	t.Cleanup(m.Server.Close)
+
+	m.Config = Config{
+		Issuer:       m.Server.URL,
+		ClientID:     "client",
+		ClientSecret: "secret",
+		RedirectURL:  "http://localhost/v1/sso/callback",
+		Scopes:       []string{"openid", "email", "profile"},
+		JWKSCacheTTL: time.Hour,
+	}
+
+	return m
+}
+
+// RotateKey replaces the signing key, as a provider does periodically.
+func (m *Mock) RotateKey(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	kid, err := NewVerifier()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	m.mu.Lock()
+	m.key, m.kid = key, kid
+	m.mu.Unlock()
+}
+
+// Authorize plays the user approving the request built by AuthCodeURL and
+// returns the URL the provider redirects back to. The given claims end up in
+// the ID token.
+func (m *Mock) Authorize(t *testing.T, authCodeURL string, claims jwt.MapClaims) *url.URL {
+	u, err := url.Parse(authCodeURL)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	q := u.Query()
+	if q.Get("client_id") != m.Config.ClientID || q.Get("code_challenge_method") != "S256" {
+		t.Fatalf("unexpected authorization request %s", authCodeURL)
+	}
+
+	c := jwt.MapClaims{"nonce": q.Get("nonce")}
+	for k, v := range claims {
+		c[k] = v
+	}
+
+	code, err := NewVerifier()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	m.mu.Lock()
+	m.grants[code] = mockGrant{
+		claims:      c,
+		challenge:   q.Get("code_challenge"),
+		redirectURI: q.Get("redirect_uri"),
+	}
+	m.mu.Unlock()
+
+	redirect, err := url.Parse(q.Get("redirect_uri"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rq := redirect.Query()
+	rq.Set("code", code)
+	rq.Set("state", q.Get("state"))
+	redirect.RawQuery = rq.Encode()
+
+	return redirect
+}
+
+// Sign issues an ID token for the configured client with the current key.
+// Registered claims may be overridden through claims.
+func (m *Mock) Sign(claims jwt.MapClaims) (string, error) {
+	now := time.Now()
+	c := jwt.MapClaims{
+		"iss": m.Config.Issuer,
+		"aud": m.Config.ClientID,
+		"iat": now.Unix(),
+		"exp": now.Add(time.Minute).Unix(),
+	}
+
+	for k, v := range claims {
+		c[k] = v
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	t := jwt.NewWithClaims(jwt.SigningMethodRS256, c)
+	t.Header["kid"] = m.kid
+
+	return t.SignedString(m.key)
+}
+
+func (m *Mock) discovery(w http.ResponseWriter, r *http.Request) {
+	writeJson(w, http.StatusOK, Discovery{
+		Issuer:                m.Server.URL,
+		AuthorizationEndpoint: m.Server.URL + "/authorize",
+		TokenEndpoint:         m.Server.URL + "/token",
+		JWKSURI:               m.Server.URL + "/jwks",
+	})
+}
+
+func (m *Mock) jwks(w http.ResponseWriter, r *http.Request) {
+	m.JWKSRequests.Add(1)
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	writeJson(w, http.StatusOK, jwks{Keys: []jwk{{
+		Kty: "RSA",
+		Kid: m.kid,
+		Use: "sig",
+		N:   base64.RawURLEncoding.EncodeToString(m.key.N.Bytes()),
+		E:   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(m.key.E)).Bytes()),
+	}}})
+}
+
+func (m *Mock) token(w http.ResponseWriter, r *http.Request) {
+	id, secret, ok := r.BasicAuth()
+	if !ok || id != m.Config.ClientID || secret != m.Config.ClientSecret {
+		writeJson(w, http.StatusUnauthorized, tokenResponse{Error: "invalid_client"})
+		return
+	}
+
+	code := r.PostFormValue("code")
+
+	m.mu.Lock()
+	g, ok := m.grants[code]
+	delete(m.grants, code)
+	m.mu.Unlock()
+
+	if !ok ||
+		r.PostFormValue("grant_type") != "authorization_code" ||
+		r.PostFormValue("redirect_uri") != g.redirectURI ||
+		Challenge(r.PostFormValue("code_verifier")) != g.challenge {
+		writeJson(w, http.StatusBadRequest, tokenResponse{Error: "invalid_grant"})
+		return
+	}
+
+	idToken, err := m.Sign(g.claims)
+	if err != nil {
+		writeJson(w, http.StatusInternalServerError, tokenResponse{Error: "server_error"})
+		return
+	}
+
+	writeJson(w, http.StatusOK, tokenResponse{IDToken: idToken})
+}
+
+func writeJson(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
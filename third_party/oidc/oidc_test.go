@@ -0,0 +1,186 @@
+package oidc
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+)
+
+func login(t *testing.T, m *Mock, p *Provider, claims jwt.MapClaims) (string, string) {
+	verifier, err := NewVerifier()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	authURL, err := p.AuthCodeURL(context.TODO(), "state", "nonce", verifier)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	redirect := m.Authorize(t, authURL, claims)
+	assert.Equal(t, "state", redirect.Query().Get("state"))
+
+	return redirect.Query().Get("code"), verifier
+}
+
+func TestProvider_Login(t *testing.T) {
+	m := NewMock(t)
+	p := New(m.Config, nil)
+
+	code, verifier := login(t, m, p, jwt.MapClaims{
+		"sub":            "abc",
+		"email":          "test@test.com",
+		"email_verified": true,
+		"name":           "Test",
+		"groups":         []string{"admins", "staff"},
+	})
+
+	idToken, err := p.Exchange(context.TODO(), code, verifier)
+	assert.Nil(t, err)
+
+	c, err := p.Verify(context.TODO(), idToken, "nonce")
+	assert.Nil(t, err)
+	assert.Equal(t, "abc", c.Subject)
+	assert.Equal(t, "test@test.com", c.Email)
+	assert.True(t, c.EmailVerified)
+	assert.Equal(t, "Test", c.Name)
+	assert.Equal(t, []string{"admins", "staff"}, c.Strings("groups"))
+
+	_, err = p.Exchange(context.TODO(), code, verifier)
+	assert.True(t, errors.Is(err, ErrExchange), "codes are single use")
+}
+
+func TestProvider_Exchange_PKCE(t *testing.T) {
+	m := NewMock(t)
+	p := New(m.Config, nil)
+
+	code, _ := login(t, m, p, jwt.MapClaims{"sub": "abc"})
+
+	_, err := p.Exchange(context.TODO(), code, "another-verifier")
+	assert.True(t, errors.Is(err, ErrExchange))
+}
+
+func TestProvider_Verify(t *testing.T) {
+	m := NewMock(t)
+	p := New(m.Config, nil)
+
+	type test struct {
+		name   string
+		claims jwt.MapClaims
+		nonce  string
+		ok     bool
+	}
+
+	tests := []test{
+		{
+			name:   "Success",
+			claims: jwt.MapClaims{"sub": "abc", "nonce": "n"},
+			nonce:  "n",
+			ok:     true,
+		},
+		{
+			name:   "Fail - Nonce mismatch",
+			claims: jwt.MapClaims{"sub": "abc", "nonce": "other"},
+			nonce:  "n",
+		},
+		{
+			name:   "Fail - Another audience",
+			claims: jwt.MapClaims{"sub": "abc", "nonce": "n", "aud": "another-client"},
+			nonce:  "n",
+		},
+		{
+			name:   "Fail - Another issuer",
+			claims: jwt.MapClaims{"sub": "abc", "nonce": "n", "iss": "https://evil.example.com"},
+			nonce:  "n",
+		},
+		{
+			name:   "Fail - Expired",
+			claims: jwt.MapClaims{"sub": "abc", "nonce": "n", "exp": time.Now().Add(-time.Minute).Unix()},
+			nonce:  "n",
+		},
+		{
+			name:   "Fail - Missing subject",
+			claims: jwt.MapClaims{"nonce": "n"},
+			nonce:  "n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			idToken, err := m.Sign(tt.claims)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			_, err = p.Verify(context.TODO(), idToken, tt.nonce)
+			if tt.ok {
+				assert.Nil(t, err)
+			} else {
+				assert.True(t, errors.Is(err, ErrInvalidIDToken), err)
+			}
+		})
+	}
+}
+
+func TestProvider_Verify_HS256WithClientSecret(t *testing.T) {
+	m := NewMock(t)
+	p := New(m.Config, nil)
+
+	idToken, err := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
+		"iss": m.Config.Issuer,
+		"aud": m.Config.ClientID,
+		"sub": "abc",
+		"exp": time.Now().Add(time.Minute).Unix(),
+	}).SignedString([]byte(m.Config.ClientSecret))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = p.Verify(context.TODO(), idToken, "")
+	assert.True(t, errors.Is(err, ErrInvalidIDToken))
+}
+
+func TestProvider_JWKSCache(t *testing.T) {
+	m := NewMock(t)
+	p := New(m.Config, nil)
+
+	for i := 0; i < 3; i++ {
+		idToken, _ := m.Sign(jwt.MapClaims{"sub": "abc"})
+		_, err := p.Verify(context.TODO(), idToken, "")
+		assert.Nil(t, err)
+	}
+
+	assert.Equal(t, int64(1), m.JWKSRequests.Load(), "keys are cached")
+
+	m.RotateKey(t)
+	idToken, _ := m.Sign(jwt.MapClaims{"sub": "abc"})
+
+	_, err := p.Verify(context.TODO(), idToken, "")
+	assert.True(t, errors.Is(err, ErrInvalidIDToken), "unknown keys are not refetched right after a refresh")
+	assert.Equal(t, int64(1), m.JWKSRequests.Load())
+
+	p.keys.minRefresh = 0
+
+	_, err = p.Verify(context.TODO(), idToken, "")
+	assert.Nil(t, err)
+	assert.Equal(t, int64(2), m.JWKSRequests.Load(), "a rotated key triggers a refresh")
+}
+
+func TestProvider_Discovery(t *testing.T) {
+	m := NewMock(t)
+
+	cfg := m.Config
+	cfg.Issuer = m.Server.URL + "/"
+
+	_, err := New(cfg, nil).AuthCodeURL(context.TODO(), "state", "nonce", "verifier")
+	assert.True(t, errors.Is(err, ErrDiscovery), "issuer must match exactly")
+
+	cfg.Issuer = "http://127.0.0.1:1"
+
+	_, err = New(cfg, nil).AuthCodeURL(context.TODO(), "state", "nonce", "verifier")
+	assert.True(t, errors.Is(err, ErrDiscovery))
+}
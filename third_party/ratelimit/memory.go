@@ -0,0 +1,86 @@
+package ratelimit
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// sweepEvery is how many requests Memory serves between sweeps of the keys
+// whose windows emptied.
+const sweepEvery = 1024
+
+type requestLog struct {
+	window time.Duration
+	times  []time.Time
+}
+
+// prune drops the requests that left the window.
+func (l *requestLog) prune(now time.Time) {
+	i := 0
+	for i < len(l.times) && !l.times[i].After(now.Add(-l.window)) {
+		i++
+	}
+
+	l.times = l.times[i:]
+}
+
+// Memory keeps the windows in the process. Each replica counts on its own,
+// so it is meant for a single replica or local development.
+type Memory struct {
+	now func() time.Time
+
+	mu       sync.Mutex
+	requests int
+	logs     map[string]*requestLog
+}
+
+func NewMemory() *Memory {
+	return &Memory{
+		now:  time.Now,
+		logs: map[string]*requestLog{},
+	}
+}
+
+func (l *Memory) sweep(now time.Time) {
+	for key, lg := range l.logs {
+		lg.prune(now)
+		if len(lg.times) == 0 {
+			delete(l.logs, key)
+		}
+	}
+}
+
+func (l *Memory) Allow(ctx context.Context, key string, limit int, window time.Duration) (Result, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+
+	l.requests++
+	if l.requests%sweepEvery == 0 {
+		l.sweep(now)
+	}
+
+	lg, ok := l.logs[key]
+	if !ok {
+		lg = &requestLog{}
+		l.logs[key] = lg
+	}
+
+	lg.window = window
+	lg.prune(now)
+
+	res := Result{Limit: limit}
+	if len(lg.times) < limit {
+		lg.times = append(lg.times, now)
+		res.Allowed = true
+	}
+
+	res.Remaining = limit - len(lg.times)
+	if len(lg.times) != 0 {
+		res.Reset = lg.times[0].Add(window).Sub(now)
+	}
+
+	return res, nil
+}
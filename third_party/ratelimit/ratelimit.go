@@ -0,0 +1,38 @@
+package ratelimit
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"time"
+)
+
+// Result is the outcome of a request against a sliding window of window
+// length allowing limit requests. Reset is the time until the oldest request
+// in the window leaves it, freeing a slot; when the request was refused it is
+// also how long the client has to wait.
+type Result struct {
+	Allowed   bool
+	Limit     int
+	Remaining int
+	Reset     time.Duration
+}
+
+// Limiter counts requests per key with a sliding window log: a request is
+// allowed when fewer than limit requests were allowed for the key in the last
+// window. Refused requests are not counted.
+type Limiter interface {
+	Allow(ctx context.Context, key string, limit int, window time.Duration) (Result, error)
+}
+
+// member names a request in the window log, which must be unique even for
+// requests in the same microsecond.
+func member() (string, error) {
+	b := make([]byte, 8)
+	_, err := rand.Read(b)
+	if err != nil {
+		return "", err
+	}
+
+	return hex.EncodeToString(b), nil
+}
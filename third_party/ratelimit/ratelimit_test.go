@@ -0,0 +1,62 @@
+package ratelimit
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+)
+
+func testLimiter(t *testing.T, l Limiter, advance func(time.Duration)) {
+	ctx := context.TODO()
+
+	for i := 0; i < 3; i++ {
+		res, err := l.Allow(ctx, "a", 3, time.Minute)
+		assert.Nil(t, err)
+		assert.True(t, res.Allowed)
+		assert.Equal(t, 2-i, res.Remaining)
+		advance(10 * time.Second)
+	}
+
+	res, err := l.Allow(ctx, "a", 3, time.Minute)
+	assert.Nil(t, err)
+	assert.False(t, res.Allowed)
+	assert.Equal(t, 0, res.Remaining)
+	assert.InDelta(t, 30*time.Second, res.Reset, float64(time.Second), "the first request leaves the window in 30s")
+
+	res, err = l.Allow(ctx, "b", 3, time.Minute)
+	assert.Nil(t, err)
+	assert.True(t, res.Allowed, "keys have their own windows")
+
+	advance(31 * time.Second)
+	res, err = l.Allow(ctx, "a", 3, time.Minute)
+	assert.Nil(t, err)
+	assert.True(t, res.Allowed, "the window slides")
+	assert.Equal(t, 0, res.Remaining)
+}
+
+func TestRedis(t *testing.T) {
+	s := miniredis.RunT(t)
+	now := time.Now()
+	s.SetTime(now)
+
+	l := NewRedis(redis.NewClient(&redis.Options{Addr: s.Addr()}))
+	testLimiter(t, l, func(d time.Duration) {
+		now = now.Add(d)
+		s.SetTime(now)
+		s.FastForward(d)
+	})
+}
+
+func TestMemory(t *testing.T) {
+	now := time.Now()
+	l := NewMemory()
+	l.now = func() time.Time { return now }
+
+	testLimiter(t, l, func(d time.Duration) {
+		now = now.Add(d)
+	})
+}
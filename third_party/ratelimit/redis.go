@@ -0,0 +1,69 @@
+package ratelimit
+
+import (
+	"context"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// slidingWindow keeps the allowed requests of KEYS[1] in a sorted set scored
+// by their time in microseconds. The time is read from Redis, so replicas
+// with skewed clocks still share one window.
+var slidingWindow = redis.NewScript(`
+redis.replicate_commands()
+
+local window = tonumber(ARGV[1])
+local limit = tonumber(ARGV[2])
+local t = redis.call('TIME')
+local now = tonumber(t[1]) * 1000000 + tonumber(t[2])
+
+redis.call('ZREMRANGEBYSCORE', KEYS[1], '-inf', now - window)
+
+local count = redis.call('ZCARD', KEYS[1])
+local allowed = 0
+if count < limit then
+	redis.call('ZADD', KEYS[1], now, ARGV[3])
+	redis.call('PEXPIRE', KEYS[1], math.ceil(window / 1000))
+	count = count + 1
+	allowed = 1
+end
+
+local oldest = redis.call('ZRANGE', KEYS[1], 0, 0, 'WITHSCORES')
+local reset = 0
+if oldest[2] then
+	reset = tonumber(oldest[2]) + window - now
+end
+
+return {allowed, limit - count, reset}
+`)
+
+// Redis shares the windows between every API replica.
+type Redis struct {
+	client redis.UniversalClient
+}
+
+func NewRedis(client redis.UniversalClient) *Redis {
+	return &Redis{
+		client: client,
+	}
+}
+
+func (l *Redis) Allow(ctx context.Context, key string, limit int, window time.Duration) (Result, error) {
+	m, err := member()
+	if err != nil {
+		return Result{}, err
+	}
+
+	res, err := slidingWindow.Run(ctx, l.client, []string{key}, window.Microseconds(), limit, m).Int64Slice()
+	if err != nil {
+		return Result{}, err
+	}
+
+	return Result{
+		Allowed:   res[0] == 1,
+		Limit:     limit,
+		Remaining: int(res[1]),
+		Reset:     time.Duration(res[2]) * time.Microsecond,
+	}, nil
+}
@@ -0,0 +1,75 @@
+package token
+
+import (
+	"errors"
+	"strconv"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+var ErrInvalidToken = errors.New("run-time: invalid access token")
+
+type Payload struct {
+	UserID      uint64
+	SessionID   string
+	WorkspaceID uint64
+}
+
+type claims struct {
+	SessionID   string `json:"sid"`
+	WorkspaceID uint64 `json:"wid,omitempty"`
+	jwt.RegisteredClaims
+}
+
+type Manager struct {
+	secret []byte
+	ttl    time.Duration
+}
+
+func New(secret string, ttl time.Duration) *Manager {
+	return &Manager{
+		secret: []byte(secret),
+		ttl:    ttl,
+	}
+}
+
+func (m *Manager) TTL() time.Duration {
+	return m.ttl
+}
+
+func (m *Manager) Sign(p Payload) (string, error) {
+	now := time.Now()
+	c := claims{
+		SessionID:   p.SessionID,
+		WorkspaceID: p.WorkspaceID,
+		RegisteredClaims: jwt.RegisteredClaims{
+			Subject:   strconv.FormatUint(p.UserID, 10),
+			IssuedAt:  jwt.NewNumericDate(now),
+			ExpiresAt: jwt.NewNumericDate(now.Add(m.ttl)),
+		},
+	}
+
+	return jwt.NewWithClaims(jwt.SigningMethodHS256, c).SignedString(m.secret)
+}
+
+func (m *Manager) Parse(tokenString string) (*Payload, error) {
+	var c claims
+	_, err := jwt.ParseWithClaims(tokenString, &c, func(t *jwt.Token) (any, error) {
+		return m.secret, nil
+	}, jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Alg()}), jwt.WithExpirationRequired())
+	if err != nil {
+		return nil, ErrInvalidToken
+	}
+
+	userID, err := strconv.ParseUint(c.Subject, 10, 64)
+	if err != nil || c.SessionID == "" {
+		return nil, ErrInvalidToken
+	}
+
+	return &Payload{
+		UserID:      userID,
+		SessionID:   c.SessionID,
+		WorkspaceID: c.WorkspaceID,
+	}, nil
+}